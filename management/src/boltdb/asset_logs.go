@@ -0,0 +1,79 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/contiv/errored"
+)
+
+const (
+	// assetLogsBucket contains a bucket of log entries per asset
+	assetLogsBucket = "asset-logs"
+	// maxAssetLogs is the maximum number of log entries retained per asset
+	maxAssetLogs = 1000
+)
+
+// AssetLog denotes a log entry of an asset as stored in boltdb
+type AssetLog struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// AddAssetLog creates a log entry for an asset. The oldest entries are pruned
+// once an asset has more than maxAssetLogs entries.
+func (c *Client) AddAssetLog(tag, mtype, message string) error {
+	val, err := json.Marshal(AssetLog{
+		Time:    time.Now().Format(time.RFC3339),
+		Type:    mtype,
+		Message: message,
+	})
+	if err != nil {
+		return errored.Errorf("failed to marshal. Error: %v", err)
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte(assetLogsBucket)).CreateBucketIfNotExists([]byte(tag))
+		if err != nil {
+			return err
+		}
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(seqKey(id), val); err != nil {
+			return err
+		}
+		if id <= maxAssetLogs {
+			return nil
+		}
+		// keys are ordered by their IDs, so the oldest entry is the first one
+		k, _ := b.Cursor().First()
+		return b.Delete(k)
+	})
+}
+
+// GetAssetLogs queries and returns the log entries of an asset, oldest first
+func (c *Client) GetAssetLogs(tag string) (interface{}, error) {
+	logs := []AssetLog{}
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(assetLogsBucket)).Bucket([]byte(tag))
+		if b == nil {
+			// no logs have been added for the asset yet
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var l AssetLog
+			if err := json.Unmarshal(v, &l); err != nil {
+				return err
+			}
+			logs = append(logs, l)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
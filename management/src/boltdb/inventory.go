@@ -30,6 +30,8 @@ type Asset struct {
 	Status    string `json:"status"`
 	State     string `json:"state"`
 	StateDesc string `json:"state_desc"`
+	// Attributes stores the key/value attributes associated with the asset
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 // Client denotes state for a boltdb client
@@ -46,8 +48,12 @@ func NewClientFromConfig(config Config) (*Client, error) {
 	}
 
 	if err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(assetsBucket))
-		return err
+		for _, bucket := range []string{assetsBucket, jobsBucket, assetLogsBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+		return nil
 	}); err != nil {
 		return nil, err
 	}
@@ -76,7 +82,7 @@ func (c *Client) CreateAsset(tag, status string) error {
 
 	if err := c.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(assetsBucket))
-		err := b.Put([]byte(tag), val)
+		err := b.Put([]byte(a.Name), val)
 		return err
 	}); err != nil {
 		return err
@@ -114,7 +120,6 @@ func (c *Client) GetAsset(tag string) (Asset, error) {
 func (c *Client) GetAllAssets() (interface{}, error) {
 	var (
 		vals   [][]byte
-		a      Asset
 		assets []Asset
 	)
 
@@ -128,6 +133,8 @@ func (c *Client) GetAllAssets() (interface{}, error) {
 	})
 
 	for _, val := range vals {
+		// use a new variable on each iteration, to avoid sharing the attributes map
+		var a Asset
 		if err := json.Unmarshal(val, &a); err != nil {
 			return nil, err
 		}
@@ -142,11 +149,6 @@ func (c *Client) CreateState(name, description, status string) error {
 	return nil
 }
 
-// AddAssetLog creates a log entry for an asset
-func (c *Client) AddAssetLog(tag, mtype, message string) error {
-	return errored.Errorf("not implemented")
-}
-
 // SetAssetStatus sets the status of an asset
 func (c *Client) SetAssetStatus(tag, status, state, reason string) error {
 	a, err := c.GetAsset(tag)
@@ -157,6 +159,24 @@ func (c *Client) SetAssetStatus(tag, status, state, reason string) error {
 	a.State = state
 	a.StateDesc = reason
 
+	return c.putAsset(a)
+}
+
+// SetAssetAttribute sets the value of a key/value attribute associated with an asset
+func (c *Client) SetAssetAttribute(tag, key, value string) error {
+	a, err := c.GetAsset(tag)
+	if err != nil {
+		return err
+	}
+	if a.Attributes == nil {
+		a.Attributes = make(map[string]string)
+	}
+	a.Attributes[key] = value
+
+	return c.putAsset(a)
+}
+
+func (c *Client) putAsset(a Asset) error {
 	val, err := json.Marshal(a)
 	if err != nil {
 		return errored.Errorf("failed to marshal. Error: %v", err)
@@ -164,7 +184,7 @@ func (c *Client) SetAssetStatus(tag, status, state, reason string) error {
 
 	if err := c.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(assetsBucket))
-		err := b.Put([]byte(tag), val)
+		err := b.Put([]byte(a.Name), val)
 		return err
 	}); err != nil {
 		return err
@@ -172,3 +192,21 @@ func (c *Client) SetAssetStatus(tag, status, state, reason string) error {
 
 	return nil
 }
+
+// DeleteAsset deletes an asset with specified tag along with it's log entries
+func (c *Client) DeleteAsset(tag string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(assetsBucket))
+		if b.Get([]byte(tag)) == nil {
+			return errored.Errorf("No asset found for name: %s", tag)
+		}
+		if err := b.Delete([]byte(tag)); err != nil {
+			return err
+		}
+		err := tx.Bucket([]byte(assetLogsBucket)).DeleteBucket([]byte(tag))
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package boltdb
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+	"github.com/contiv/errored"
+)
+
+const (
+	jobsBucket = "jobs"
+)
+
+// seqKey returns the key for a job or a log entry. The key is big-endian encoded
+// so that the entries are iterated in the order of their IDs.
+func seqKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// NextJobID returns a unique ID for a new job
+func (c *Client) NextJobID() (uint64, error) {
+	var id uint64
+	if err := c.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		id, err = tx.Bucket([]byte(jobsBucket)).NextSequence()
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// PutJob creates or updates the info of the job with specified ID
+func (c *Client) PutJob(id uint64, info []byte) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(jobsBucket)).Put(seqKey(id), info)
+	})
+}
+
+// GetJob queries and returns the info of the job with specified ID
+func (c *Client) GetJob(id uint64) ([]byte, error) {
+	var info []byte
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		val := tx.Bucket([]byte(jobsBucket)).Get(seqKey(id))
+		if val == nil {
+			return errored.Errorf("No job found for id: %d", id)
+		}
+		// the value is only valid for life of transaction, so make a copy
+		info = append([]byte{}, val...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetAllJobs queries and returns the info of all the jobs in the order of their IDs
+func (c *Client) GetAllJobs() ([][]byte, error) {
+	infos := [][]byte{}
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(jobsBucket)).ForEach(func(k, v []byte) error {
+			infos = append(infos, append([]byte{}, v...))
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// PruneJobs deletes the oldest jobs such that atmost maxJobs jobs are retained
+func (c *Client) PruneJobs(maxJobs int) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(jobsBucket))
+		keys := [][]byte{}
+		cur := b.Cursor()
+		for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		if len(keys) <= maxJobs {
+			return nil
+		}
+		// keys are ordered by job IDs, so the oldest jobs are at the beginning
+		for _, k := range keys[:len(keys)-maxJobs] {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,105 @@
+// +build unittest
+
+package boltdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type boltdbSuite struct {
+	dbFile string
+	client *Client
+}
+
+var _ = Suite(&boltdbSuite{})
+
+func (s *boltdbSuite) SetUpTest(c *C) {
+	f, err := ioutil.TempFile("", "boltdb-test")
+	c.Assert(err, IsNil)
+	f.Close()
+	s.dbFile = f.Name()
+	s.client, err = NewClientFromConfig(Config{DBFile: s.dbFile})
+	c.Assert(err, IsNil)
+}
+
+func (s *boltdbSuite) TearDownTest(c *C) {
+	s.client.db.Close()
+	os.Remove(s.dbFile)
+}
+
+func (s *boltdbSuite) TestJobs(c *C) {
+	ids := []uint64{}
+	for i := 0; i < 3; i++ {
+		id, err := s.client.NextJobID()
+		c.Assert(err, IsNil)
+		ids = append(ids, id)
+		c.Assert(s.client.PutJob(id, []byte{byte('a' + i)}), IsNil)
+	}
+	c.Assert(ids, DeepEquals, []uint64{1, 2, 3})
+
+	info, err := s.client.GetJob(2)
+	c.Assert(err, IsNil)
+	c.Assert(info, DeepEquals, []byte("b"))
+
+	infos, err := s.client.GetAllJobs()
+	c.Assert(err, IsNil)
+	c.Assert(infos, DeepEquals, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+
+	// prune and make sure the oldest job is removed
+	c.Assert(s.client.PruneJobs(2), IsNil)
+	infos, err = s.client.GetAllJobs()
+	c.Assert(err, IsNil)
+	c.Assert(infos, DeepEquals, [][]byte{[]byte("b"), []byte("c")})
+	_, err = s.client.GetJob(1)
+	c.Assert(err, ErrorMatches, "No job found for id: 1")
+}
+
+func (s *boltdbSuite) TestAssetLogs(c *C) {
+	logs, err := s.client.GetAssetLogs("foo")
+	c.Assert(err, IsNil)
+	c.Assert(logs, DeepEquals, []AssetLog{})
+
+	c.Assert(s.client.AddAssetLog("foo", "INFORMATIONAL", "msg1"), IsNil)
+	c.Assert(s.client.AddAssetLog("bar", "INFORMATIONAL", "msg2"), IsNil)
+	c.Assert(s.client.AddAssetLog("foo", "ERROR", "msg3"), IsNil)
+	logs, err = s.client.GetAssetLogs("foo")
+	c.Assert(err, IsNil)
+	fooLogs := logs.([]AssetLog)
+	c.Assert(len(fooLogs), Equals, 2)
+	c.Assert(fooLogs[0].Type, Equals, "INFORMATIONAL")
+	c.Assert(fooLogs[0].Message, Equals, "msg1")
+	c.Assert(fooLogs[1].Type, Equals, "ERROR")
+	c.Assert(fooLogs[1].Message, Equals, "msg3")
+}
+
+func (s *boltdbSuite) TestAssetLogsPrune(c *C) {
+	for i := 0; i < maxAssetLogs+2; i++ {
+		c.Assert(s.client.AddAssetLog("foo", "INFORMATIONAL", fmt.Sprintf("msg%d", i)), IsNil)
+	}
+	logs, err := s.client.GetAssetLogs("foo")
+	c.Assert(err, IsNil)
+	fooLogs := logs.([]AssetLog)
+	c.Assert(len(fooLogs), Equals, maxAssetLogs)
+	c.Assert(fooLogs[0].Message, Equals, "msg2")
+}
+
+func (s *boltdbSuite) TestDeleteAsset(c *C) {
+	c.Assert(s.client.DeleteAsset("foo"), ErrorMatches, "No asset found.*")
+
+	c.Assert(s.client.CreateAsset("foo", "Decommissioned"), IsNil)
+	c.Assert(s.client.AddAssetLog("foo", "INFORMATIONAL", "msg1"), IsNil)
+	c.Assert(s.client.DeleteAsset("foo"), IsNil)
+	_, err := s.client.GetAsset("foo")
+	c.Assert(err, ErrorMatches, "No asset found.*")
+	logs, err := s.client.GetAssetLogs("foo")
+	c.Assert(err, IsNil)
+	c.Assert(logs, DeepEquals, []AssetLog{})
+}
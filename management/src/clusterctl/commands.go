@@ -22,6 +22,17 @@ var (
 		Usage: "extra vars for ansible configuration. This should be a quoted json string.",
 	}
 
+	waitFlag = cli.BoolFlag{
+		Name:  "wait, w",
+		Usage: "wait for the job to finish. Exits with non-zero status if the job fails",
+	}
+
+	selectorFlag = cli.StringFlag{
+		Name:  "selector, l",
+		Value: "",
+		Usage: "act upon the nodes that match the label selector, like 'zone=a,role=service-worker'. The nodes are not specified as args, when set",
+	}
+
 	jsonFlag = cli.BoolFlag{
 		Name:  "json, j",
 		Usage: "print command output in JSON",
@@ -31,16 +42,118 @@ var (
 		jsonFlag,
 	}
 
+	getNodesFlags = []cli.Flag{
+		jsonFlag,
+		selectorFlag,
+		cli.StringFlag{
+			Name:  "output, o",
+			Value: "",
+			Usage: "output format. Possible values: wide, json, yaml or template=<go-template>. The nodes are printed as a table, when not set",
+		},
+		cli.StringFlag{
+			Name:  "status, s",
+			Value: "",
+			Usage: "list the nodes in the inventory status, like Allocated or Unallocated",
+		},
+		cli.StringFlag{
+			Name:  "state, t",
+			Value: "",
+			Usage: "list the nodes in the inventory state, like Discovered or Disappeared",
+		},
+		cli.StringFlag{
+			Name:  "host-group, g",
+			Value: "",
+			Usage: "list the nodes in the host-group",
+		},
+		cli.StringFlag{
+			Name:  "name, n",
+			Value: "",
+			Usage: "list the nodes whose name matches the shell pattern, like 'node-*'",
+		},
+		cli.StringFlag{
+			Name:  "sort",
+			Value: "name",
+			Usage: "sort the nodes by a field. Possible values: name, addr, host_group, status or state",
+		},
+	}
+
 	postFlags = []cli.Flag{
 		extraVarsFlag,
 	}
 
+	postJobFlags = []cli.Flag{
+		extraVarsFlag,
+		waitFlag,
+	}
+
+	postAdoptFlags = []cli.Flag{
+		extraVarsFlag,
+		waitFlag,
+		cli.StringFlag{
+			Name:  "host-group, g",
+			Value: "",
+			Usage: "host-group of the node(s), as declared by a role in clusterm configuration",
+		},
+		cli.BoolFlag{
+			Name:  "verify, v",
+			Usage: "verify the configuration of the node(s) using the verification playbook, before adopting them",
+		},
+	}
+
+	postDecommissionFlags = []cli.Flag{
+		extraVarsFlag,
+		waitFlag,
+		cli.BoolFlag{
+			Name:  "force, f",
+			Usage: "decommission the node(s) even if they are not reachable. The cleanup is skipped for the unreachable nodes",
+		},
+	}
+
+	postUpgradeFlags = []cli.Flag{
+		extraVarsFlag,
+		waitFlag,
+		cli.IntFlag{
+			Name:  "batch-size, b",
+			Value: 1,
+			Usage: "number of nodes to upgrade at a time",
+		},
+		cli.IntFlag{
+			Name:  "max-unavailable, m",
+			Value: 0,
+			Usage: "maximum number of nodes of a host-group that can be unavailable at a time, including the nodes that are already down. The batches are shrunk to stay within it, when set",
+		},
+		cli.StringFlag{
+			Name:  "batch-pause, p",
+			Value: "",
+			Usage: "time to wait between the batches, like 30s or 5m",
+		},
+	}
+
+	postForceStatusFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "status, s",
+			Value: "",
+			Usage: "inventory status to force, like Allocated or Unallocated",
+		},
+		cli.StringFlag{
+			Name:  "state, t",
+			Value: "",
+			Usage: "inventory state to force, like Discovered or Disappeared. The current state is retained, when not set",
+		},
+		cli.StringFlag{
+			Name:  "reason, r",
+			Value: "",
+			Usage: "reason for the override. It is recorded in the node's logs",
+		},
+	}
+
 	postHostGroupFlags = []cli.Flag{
 		extraVarsFlag,
+		waitFlag,
 		cli.StringFlag{
 			Name:  "host-group, g",
 			Value: "",
-			Usage: "host-group of the node(s). Possible values: service-master or service-worker",
+			Usage: "host-group of the node(s), as declared by a role in clusterm configuration",
 		},
 	}
 
@@ -62,7 +175,20 @@ var (
 					Aliases: []string{"d"},
 					Usage:   "decommission a node",
 					Action:  doAction(newPostActioner(validateOneArg, nodeDecommission)),
-					Flags:   postFlags,
+					Flags:   postDecommissionFlags,
+				},
+				{
+					Name:    "adopt",
+					Aliases: []string{"a"},
+					Usage:   "commission a node that is already configured, without running the configuration on it",
+					Action:  doAction(newPostActioner(validateOneArg, nodesAdopt)),
+					Flags:   postAdoptFlags,
+				},
+				{
+					Name:    "purge",
+					Aliases: []string{"p"},
+					Usage:   "delete a decommissioned node from the inventory",
+					Action:  doAction(newPostActioner(validateOneArg, nodesPurge)),
 				},
 				{
 					Name:    "update",
@@ -71,6 +197,19 @@ var (
 					Action:  doAction(newPostActioner(validateOneArg, nodeUpdate)),
 					Flags:   postHostGroupFlags,
 				},
+				{
+					Name:    "label",
+					Aliases: []string{"b"},
+					Usage:   "set the labels of a node. Expects the node name followed by one or more labels like 'zone=a', a label like 'zone-' is removed",
+					Action:  doAction(newPostActioner(validateOneNodeAndLabels, nodesLabel)),
+				},
+				{
+					Name:    "force-status",
+					Aliases: []string{"f"},
+					Usage:   "force node's inventory status and state, bypassing the lifecycle checks. Meant to fix a node whose status doesn't reflect the reality",
+					Action:  doAction(newPostActioner(validateOneArg, nodeForceStatus)),
+					Flags:   postForceStatusFlags,
+				},
 				{
 					Name:    "get",
 					Aliases: []string{"g"},
@@ -78,6 +217,13 @@ var (
 					Action:  doAction(newGetActioner(nodeGet)),
 					Flags:   getFlags,
 				},
+				{
+					Name:    "logs",
+					Aliases: []string{"l"},
+					Usage:   "get node's event logs, like status transitions, jobs and monitoring events",
+					Action:  doAction(newGetActioner(nodeLogs)),
+					Flags:   getFlags,
+				},
 			},
 		},
 		{
@@ -89,28 +235,63 @@ var (
 					Name:    "commission",
 					Aliases: []string{"c"},
 					Usage:   "commission a set of nodes",
-					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesCommission)),
-					Flags:   postHostGroupFlags,
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesCommission).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(postHostGroupFlags),
 				},
 				{
 					Name:    "decommission",
 					Aliases: []string{"d"},
 					Usage:   "decommission a set of nodes",
-					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesDecommission)),
-					Flags:   postFlags,
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesDecommission).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(postDecommissionFlags),
+				},
+				{
+					Name:    "adopt",
+					Aliases: []string{"o"},
+					Usage:   "commission a set of nodes that are already configured, without running the configuration on them",
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesAdopt).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(postAdoptFlags),
+				},
+				{
+					Name:    "purge",
+					Aliases: []string{"p"},
+					Usage:   "delete a set of decommissioned nodes from the inventory",
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesPurge).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(nil),
+				},
+				{
+					Name:    "label",
+					Aliases: []string{"b"},
+					Usage:   "set the labels of a set of nodes. Expects the node names followed by one or more labels like 'zone=a', a label like 'zone-' is removed",
+					Action:  doAction(newPostActioner(validateMultiNodesAndLabels, nodesLabel).selectable(validateOnlyLabels)),
+					Flags:   withSelectorFlag(nil),
 				},
 				{
 					Name:    "update",
 					Aliases: []string{"u"},
 					Usage:   "update a set of nodes",
-					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesUpdate)),
-					Flags:   postFlags,
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesUpdate).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(postJobFlags),
+				},
+				{
+					Name:    "upgrade",
+					Aliases: []string{"r"},
+					Usage:   "perform a rolling upgrade of a set of nodes using the upgrade playbook. The nodes are upgraded in batches in the order they are specified and the upgrade stops at the first batch that fails",
+					Action:  doAction(newPostActioner(validateMultiNodeNames, nodesUpgrade).selectable(validateZeroArgs)),
+					Flags:   withSelectorFlag(postUpgradeFlags),
 				},
 				{
 					Name:    "get",
 					Aliases: []string{"g"},
-					Usage:   "get status information for all nodes",
+					Usage:   "list all nodes, or the nodes that match the filters, one node per row",
 					Action:  doAction(newGetActioner(nodesGet)),
+					Flags:   getNodesFlags,
+				},
+				{
+					Name:    "quarantined",
+					Aliases: []string{"q"},
+					Usage:   "list the discovered nodes that are not added to the inventory, as their identity is missing or conflicting",
+					Action:  doAction(newGetActioner(nodesQuarantined)),
 					Flags:   getFlags,
 				},
 			},
@@ -144,10 +325,35 @@ var (
 				{
 					Name:    "get",
 					Aliases: []string{"g"},
-					Usage:   "get job info. Expects an arg with value 'active' or 'last'",
+					Usage:   "get job info. Expects an arg with value 'active', 'last' or a job id",
 					Action:  doAction(newGetActioner(jobGet)),
 					Flags:   getFlags,
 				},
+				{
+					Name:    "logs",
+					Aliases: []string{"o"},
+					Usage:   "get job logs. Expects an arg with value 'active', 'last' or a job id",
+					Action:  doAction(newGetActioner(jobLogs)),
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "follow, f",
+							Usage: "keep streaming the logs until the job is done",
+						},
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"l"},
+					Usage:   "list the jobs in job history",
+					Action:  doAction(newGetActioner(jobsList)),
+					Flags:   getFlags,
+				},
+				{
+					Name:    "cancel",
+					Aliases: []string{"c"},
+					Usage:   "cancel a running or queued job. Expects an optional arg with value 'active' or a job id. Defaults to 'active', which is rejected when more than one job is running",
+					Action:  doAction(newPostActioner(validateZeroOrOneArg, jobCancel)),
+				},
 			},
 		},
 		{
@@ -155,7 +361,7 @@ var (
 			Aliases: []string{"d"},
 			Usage:   "provision one or more nodes for discovery",
 			Action:  doAction(newPostActioner(validateMultiNodeAddrs, nodesDiscover)),
-			Flags:   postFlags,
+			Flags:   postJobFlags,
 		},
 		{
 			Name:    "config",
@@ -180,18 +386,36 @@ var (
 	}
 )
 
+// withSelectorFlag returns the flags along with the label selector flag
+func withSelectorFlag(flags []cli.Flag) []cli.Flag {
+	return append(append([]cli.Flag{}, flags...), selectorFlag)
+}
+
 func errUnexpectedArgCount(exptd string, rcvd int) error {
 	return errored.Errorf("command expects %s arg(s) but received %d", exptd, rcvd)
 }
 
+func errNoLabelArgs() error {
+	return errored.Errorf("command expects atleast one label arg like 'key=value' or 'key-'")
+}
+
 func errInvalidIPAddr(a string) error {
 	return errored.Errorf("failed to parse ip address %q", a)
 }
 
 type parsedFlags struct {
-	extraVars  string
-	hostGroup  string
-	jsonOutput bool
+	extraVars   string
+	hostGroup   string
+	jsonOutput  bool
+	wait        bool
+	follow      bool
+	upgrade     manager.UpgradeOptions
+	force       bool
+	verify      bool
+	forceStatus manager.ForceStatusOptions
+	selector    string
+	output      string
+	nodesQuery  manager.NodesQuery
 }
 
 type actioner interface {
@@ -206,7 +430,7 @@ func doAction(a actioner) func(*cli.Context) {
 		a.procArgs(c)
 		a.procFlags(c)
 		if err := a.action(cClient); err != nil {
-			logrus.Fatalf(err.Error())
+			logrus.Fatal(err)
 		}
 	}
 }
@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"reflect"
+	"text/tabwriter"
 	"text/template"
 
 	"github.com/codegangsta/cli"
@@ -12,15 +14,39 @@ import (
 )
 
 type nodeInfo struct {
-	Mon map[string]interface{} `json:"monitoring_state"`
-	Inv map[string]interface{} `json:"inventory_state"`
-	Cfg map[string]interface{} `json:"configuration_state"`
+	Mon     map[string]interface{} `json:"monitoring_state"`
+	Inv     map[string]interface{} `json:"inventory_state"`
+	Cfg     map[string]interface{} `json:"configuration_state"`
+	Labels  map[string]string      `json:"labels"`
+	LastJob map[string]interface{} `json:"last_job"`
+	History *nodeHistoryInfo       `json:"monitoring_history"`
 }
 
-type nodesInfo map[string]nodeInfo
+// nodeHistoryInfo is the summary of node's monitoring timeline over the flap window
+type nodeHistoryInfo struct {
+	Transitions []map[string]interface{} `json:"transitions"`
+	Flaps       int                      `json:"flaps"`
+	Uptime      *float64                 `json:"uptime_percent"`
+	Window      string                   `json:"window"`
+	Flapping    bool                     `json:"flapping"`
+}
+
+// UptimeString returns the uptime percentage for printing, '-' if it's not known
+func (h *nodeHistoryInfo) UptimeString() string {
+	if h.Uptime == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *h.Uptime)
+}
+
+type nodeLogsInfo []map[string]interface{}
 
 type jobInfo map[string]interface{}
 
+type jobsInfo []jobInfo
+
+type quarantinedInfo []map[string]interface{}
+
 type globalInfo map[string]interface{}
 
 type configInfo map[string]interface{}
@@ -105,6 +131,20 @@ var (
 	{{- template "typePrint" newPrintHelper $indent .Mon }}
 	{{- $invName }}: Configuration State{{ "\n" }}
 	{{- template "typePrint" newPrintHelper $indent .Cfg }}
+	{{- if .Labels }}
+	{{- $invName }}: Labels{{ "\n" }}
+	{{- template "typePrint" newPrintHelper $indent .Labels }}
+	{{- end }}
+	{{- with .History }}
+	{{- $invName }}: Monitoring History{{ "\n" }}
+	{{- $indent }}flapping: {{ .Flapping }}{{ "\n" }}
+	{{- $indent }}flaps: {{ .Flaps }} in last {{ .Window }}{{ "\n" }}
+	{{- $indent }}uptime: {{ .UptimeString }} in last {{ .Window }}{{ "\n" }}
+	{{- $indent }}transitions:{{ "\n" }}
+	{{- range .Transitions }}
+	{{- $indent }}    {{ .time }}: {{ .state }}{{ "\n" }}
+	{{- end }}
+	{{- end }}
 {{ end }}
 `
 	nodeTemplate = template.Must(template.Must(typeTemplate.Clone()).Parse(nodePrint))
@@ -112,17 +152,39 @@ var (
 	oneNodePrint    = `{{- template "nodePrint" . }}`
 	oneNodeTemplate = template.Must(template.Must(nodeTemplate.Clone()).Parse(oneNodePrint))
 
-	multiNodePrint    = `{{- range $key, $val := . }}{{ template "nodePrint" $val }}{{ end }}`
-	multiNodeTemplate = template.Must(template.Must(nodeTemplate.Clone()).Parse(multiNodePrint))
-
 	jobPrint = `
+ID: {{ .id }}
 Description: {{ .desc }}
+Nodes: {{ .nodes }}
 Status: {{ .status }}
 Error: {{ .error }}
+Start Time: {{ or .start_time "-" }}
+End Time: {{ or .end_time "-" }}
 Logs:
 {{ template "typePrint" newPrintHelper "    " .logs }}
 `
 	jobTemplate = template.Must(template.Must(typeTemplate.Clone()).Parse(jobPrint))
+
+	jobsPrint = `ID	STATUS	START TIME	END TIME	NODES	DESCRIPTION
+{{- range . }}
+{{ .id }}	{{ .status }}	{{ or .start_time "-" }}	{{ or .end_time "-" }}	{{ .nodes }}	{{ .desc }}
+{{- end }}
+`
+	jobsTemplate = template.Must(template.New("").Parse(jobsPrint))
+
+	nodeLogsPrint = `TIME	TYPE	MESSAGE
+{{- range . }}
+{{ .time }}	{{ .type }}	{{ .message }}
+{{- end }}
+`
+	nodeLogsTemplate = template.Must(template.New("").Parse(nodeLogsPrint))
+
+	quarantinedPrint = `LABEL	SERIAL	ADDR	SINCE	REASON
+{{- range . }}
+{{ or .label "-" }}	{{ or .serial "-" }}	{{ .addr }}	{{ .since }}	{{ .reason }}
+{{- end }}
+`
+	quarantinedTemplate = template.Must(template.New("").Parse(quarantinedPrint))
 )
 
 type getCallback func(c *manager.Client, arg string, flags parsedFlags) error
@@ -139,6 +201,19 @@ func newGetActioner(getCb getCallback) *getActioner {
 
 func (nga *getActioner) procFlags(c *cli.Context) {
 	nga.flags.jsonOutput = c.Bool("json")
+	nga.flags.follow = c.Bool("follow")
+	nga.flags.selector = c.String("selector")
+	nga.flags.output = c.String("output")
+	if nga.flags.jsonOutput && nga.flags.output == "" {
+		nga.flags.output = outputJSON
+	}
+	nga.flags.nodesQuery = manager.NodesQuery{
+		Status:    c.String("status"),
+		State:     c.String("state"),
+		HostGroup: c.String("host-group"),
+		Name:      c.String("name"),
+		Sort:      nodesSortKey(c.String("sort"), nga.flags.output),
+	}
 	return
 }
 
@@ -181,14 +256,65 @@ func nodeGet(c *manager.Client, nodeName string, flags parsedFlags) error {
 	return nil
 }
 
+func nodeLogs(c *manager.Client, nodeName string, flags parsedFlags) error {
+	if nodeName == "" {
+		return errUnexpectedArgCount("1", 0)
+	}
+
+	out, err := c.GetNodeLogs(nodeName)
+	if err != nil {
+		return err
+	}
+
+	if !flags.jsonOutput {
+		// print the logs as a table
+		logs := &nodeLogsInfo{}
+		if err := json.Unmarshal(out, logs); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		if err := nodeLogsTemplate.Execute(w, logs); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	ppJSON(out)
+	return nil
+}
+
 func nodesGet(c *manager.Client, noop string, flags parsedFlags) error {
-	out, err := c.GetAllNodes()
+	if err := validateOutput(flags.output); err != nil {
+		return err
+	}
+	if flags.selector != "" {
+		c = c.WithSelector(flags.selector)
+	}
+	out, err := c.GetNodes(flags.nodesQuery)
+	if err != nil {
+		return err
+	}
+
+	return printNodes(os.Stdout, out, flags.output)
+}
+
+func nodesQuarantined(c *manager.Client, noop string, flags parsedFlags) error {
+	out, err := c.GetQuarantinedNodes()
 	if err != nil {
 		return err
 	}
 
 	if !flags.jsonOutput {
-		return printTemplate(out, multiNodeTemplate, &nodesInfo{})
+		// print the quarantined nodes as a table
+		nodes := &quarantinedInfo{}
+		if err := json.Unmarshal(out, nodes); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		if err := quarantinedTemplate.Execute(w, nodes); err != nil {
+			return err
+		}
+		return w.Flush()
 	}
 
 	ppJSON(out)
@@ -227,6 +353,37 @@ func jobGet(c *manager.Client, job string, flags parsedFlags) error {
 	return nil
 }
 
+func jobLogs(c *manager.Client, job string, flags parsedFlags) error {
+	if job == "" {
+		return errUnexpectedArgCount("1", 0)
+	}
+
+	return c.StreamJobLogs(job, 0, flags.follow, os.Stdout)
+}
+
+func jobsList(c *manager.Client, noop string, flags parsedFlags) error {
+	out, err := c.GetAllJobs()
+	if err != nil {
+		return err
+	}
+
+	if !flags.jsonOutput {
+		// print the jobs as a table
+		jobs := &jobsInfo{}
+		if err := json.Unmarshal(out, jobs); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		if err := jobsTemplate.Execute(w, jobs); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	ppJSON(out)
+	return nil
+}
+
 func configGet(c *manager.Client, noop string, flags parsedFlags) error {
 	out, err := c.GetConfig()
 	if err != nil {
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	. "gopkg.in/check.v1"
@@ -37,11 +39,31 @@ func (s *mainSuite) TestCommandArgValidationError(c *C) {
 			args:     []string{},
 			exptdErr: errUnexpectedArgCount(">=1", len([]string{})),
 		},
+		"zero-or-one-arg": {
+			f:        validateZeroOrOneArg,
+			args:     []string{"", ""},
+			exptdErr: errUnexpectedArgCount("0 or 1", len([]string{"", ""})),
+		},
 		"invalid-addr": {
 			f:        validateMultiNodeAddrs,
 			args:     []string{"1.2.3.4.5", ""},
 			exptdErr: errInvalidIPAddr("1.2.3.4.5"),
 		},
+		"one-node-and-labels": {
+			f:        validateOneNodeAndLabels,
+			args:     []string{"n1", "n2", "zone=a"},
+			exptdErr: errUnexpectedArgCount("1 node name", 2),
+		},
+		"multi-nodes-no-labels": {
+			f:        validateMultiNodesAndLabels,
+			args:     []string{"n1", "n2"},
+			exptdErr: errNoLabelArgs(),
+		},
+		"selector-no-labels": {
+			f:        validateOnlyLabels,
+			args:     []string{},
+			exptdErr: errNoLabelArgs(),
+		},
 	}
 
 	for key, test := range tests {
@@ -49,3 +71,76 @@ func (s *mainSuite) TestCommandArgValidationError(c *C) {
 		c.Assert(err.Error(), Equals, test.exptdErr.Error(), Commentf("test key: %s", key))
 	}
 }
+
+func (s *mainSuite) TestParseLabelArgs(c *C) {
+	nodes, labels := parseLabelArgs([]string{"n1", "zone=a", "n2", "rack-", "owner="})
+	c.Assert(nodes, DeepEquals, []string{"n1", "n2"})
+	c.Assert(labels, DeepEquals, map[string]string{"zone": "a", "rack": "", "owner": ""})
+}
+
+var testNodesOut = []byte(`[
+	{
+		"monitoring_state": {"label": "node1", "serial_number": "s1", "management_address": "10.0.0.1"},
+		"inventory_state": {"name": "node1-s1", "status": "Allocated", "state": "Discovered"},
+		"configuration_state": {"host_group": "service-master", "ssh_address": "10.0.0.1"},
+		"labels": {"zone": "a", "rack": "r1"},
+		"last_job": {"id": 3, "status": "Complete"}
+	},
+	{
+		"inventory_state": {"name": "node2-s2", "status": "Unallocated", "state": "Disappeared"},
+		"configuration_state": {"host_group": "service-worker", "ssh_address": ""}
+	}
+]`)
+
+func (s *mainSuite) TestPrintNodesTable(c *C) {
+	var out bytes.Buffer
+	c.Assert(printNodes(&out, testNodesOut, ""), IsNil)
+	c.Assert(out.String(), Equals, strings.Join([]string{
+		"NAME      ADDR      HOST GROUP      STATUS       STATE        LAST JOB",
+		"node1-s1  10.0.0.1  service-master  Allocated    Discovered   3 (Complete)",
+		"node2-s2  -         service-worker  Unallocated  Disappeared  -",
+		"",
+	}, "\n"))
+
+	out.Reset()
+	c.Assert(printNodes(&out, testNodesOut, outputWide), IsNil)
+	c.Assert(strings.Split(out.String(), "\n")[1], Equals,
+		"node1-s1  10.0.0.1  service-master  Allocated    Discovered   3 (Complete)  s1      rack=r1,zone=a")
+}
+
+func (s *mainSuite) TestPrintNodesYAMLAndTemplate(c *C) {
+	var out bytes.Buffer
+	c.Assert(printNodes(&out, []byte(`[{"name": "n1", "vars": {"on": "yes", "addr": "1.2.3.4"}, "jobs": [], "ids": [1, 2]}]`),
+		outputYAML), IsNil)
+	c.Assert(out.String(), Equals, strings.Join([]string{
+		"- ids:",
+		"    - 1",
+		"    - 2",
+		"  jobs: []",
+		"  name: n1",
+		"  vars:",
+		"    addr: \"1.2.3.4\"",
+		"    \"on\": \"yes\"",
+		"",
+	}, "\n"))
+
+	out.Reset()
+	c.Assert(printNodes(&out, testNodesOut, `template={{ range . }}{{ .inventory_state.name }} {{ end }}`), IsNil)
+	c.Assert(out.String(), Equals, "node1-s1 node2-s2 ")
+}
+
+func (s *mainSuite) TestNodesSortKey(c *C) {
+	c.Assert(nodesSortKey("", ""), Equals, "name")
+	c.Assert(nodesSortKey("", outputWide), Equals, "name")
+	c.Assert(nodesSortKey("status", ""), Equals, "status")
+	c.Assert(nodesSortKey("", outputJSON), Equals, "")
+	c.Assert(nodesSortKey("", "template={{ . }}"), Equals, "")
+}
+
+func (s *mainSuite) TestValidateOutput(c *C) {
+	for _, output := range []string{"", outputWide, outputJSON, outputYAML, "template={{ . }}"} {
+		c.Assert(validateOutput(output), IsNil, Commentf("output: %s", output))
+	}
+	c.Assert(validateOutput("xml"), ErrorMatches, "invalid output format \"xml\".*")
+	c.Assert(validateOutput("template={{ ."), ErrorMatches, "failed to parse the output template.*")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/contiv/errored"
+)
+
+// the output formats of the nodes listing. The listing is printed as a table, by default.
+const (
+	outputWide           = "wide"
+	outputJSON           = "json"
+	outputYAML           = "yaml"
+	outputTemplatePrefix = "template="
+)
+
+var (
+	nodesTableFuncs = template.FuncMap{
+		"labels": func(labels map[string]string) string {
+			kvs := []string{}
+			for k, v := range labels {
+				kvs = append(kvs, k+"="+v)
+			}
+			sort.Strings(kvs)
+			return strings.Join(kvs, ",")
+		},
+	}
+
+	nodesTablePrint = `NAME	ADDR	HOST GROUP	STATUS	STATE	LAST JOB{{ if .Wide }}	SERIAL	LABELS{{ end }}
+{{- $wide := .Wide }}
+{{- range .Nodes }}
+{{ .Inv.name }}	{{ or .Mon.management_address .Cfg.ssh_address "-" }}	{{ or .Cfg.host_group "-" }}	{{ .Inv.status }}	{{ .Inv.state }}	{{ with .LastJob }}{{ .id }} ({{ .status }}){{ else }}-{{ end }}
+{{- if $wide }}	{{ or .Mon.serial_number "-" }}	{{ or (labels .Labels) "-" }}{{ end }}
+{{- end }}
+`
+	nodesTableTemplate = template.Must(template.New("").Funcs(nodesTableFuncs).Parse(nodesTablePrint))
+
+	// yamlPlainRegexp matches the strings that can be written in yaml without quotes
+	yamlPlainRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+)
+
+// validateOutput checks the output format of the nodes listing
+func validateOutput(output string) error {
+	switch {
+	case output == "", output == outputWide, output == outputJSON, output == outputYAML:
+		return nil
+	case strings.HasPrefix(output, outputTemplatePrefix):
+		if _, err := template.New("").Parse(strings.TrimPrefix(output, outputTemplatePrefix)); err != nil {
+			return errored.Errorf("failed to parse the output template. Error: %v", err)
+		}
+		return nil
+	}
+	return errored.Errorf("invalid output format %q. Possible values: wide, json, yaml or template=<go-template>", output)
+}
+
+// nodesSortKey returns the sort order of the nodes listing. The nodes printed as
+// a table are always sorted, by name if no order is specified, as the nodes are
+// listed as a map by their names when no order is requested.
+func nodesSortKey(sortKey, output string) string {
+	if sortKey == "" && (output == "" || output == outputWide) {
+		return "name"
+	}
+	return sortKey
+}
+
+// printNodes prints the nodes listing in the specified output format
+func printNodes(w io.Writer, out []byte, output string) error {
+	switch {
+	case output == outputJSON:
+		var outBuf bytes.Buffer
+		if err := json.Indent(&outBuf, out, "", "    "); err != nil {
+			return err
+		}
+		_, err := outBuf.WriteTo(w)
+		return err
+	case output == outputYAML:
+		var v interface{}
+		if err := json.Unmarshal(out, &v); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, strings.Join(yamlLines(v), "\n")+"\n")
+		return err
+	case strings.HasPrefix(output, outputTemplatePrefix):
+		var v interface{}
+		if err := json.Unmarshal(out, &v); err != nil {
+			return err
+		}
+		t, err := template.New("").Parse(strings.TrimPrefix(output, outputTemplatePrefix))
+		if err != nil {
+			return errored.Errorf("failed to parse the output template. Error: %v", err)
+		}
+		return t.Execute(w, v)
+	}
+
+	// print the nodes as a table
+	nodes := []nodeInfo{}
+	if err := json.Unmarshal(out, &nodes); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if err := nodesTableTemplate.Execute(tw, struct {
+		Wide  bool
+		Nodes []nodeInfo
+	}{
+		Wide:  output == outputWide,
+		Nodes: nodes,
+	}); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// yamlLines returns the lines of the yaml representation of a value decoded
+// from json. The keys of the maps are sorted and the strings are quoted when
+// they may not be read back as is.
+func yamlLines(v interface{}) []string {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return []string{"{}"}
+		}
+		keys := []string{}
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		lines := []string{}
+		for _, k := range keys {
+			sub := yamlLines(val[k])
+			if !isYAMLCollection(val[k]) {
+				lines = append(lines, yamlString(k)+": "+sub[0])
+				continue
+			}
+			lines = append(lines, yamlString(k)+":")
+			for _, l := range sub {
+				lines = append(lines, "  "+l)
+			}
+		}
+		return lines
+	case []interface{}:
+		if len(val) == 0 {
+			return []string{"[]"}
+		}
+		lines := []string{}
+		for _, e := range val {
+			sub := yamlLines(e)
+			lines = append(lines, "- "+sub[0])
+			for _, l := range sub[1:] {
+				lines = append(lines, "  "+l)
+			}
+		}
+		return lines
+	case string:
+		return []string{yamlString(val)}
+	case float64:
+		return []string{strconv.FormatFloat(val, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(val)}
+	}
+	return []string{"null"}
+}
+
+// isYAMLCollection returns true if the value is a non empty map or list
+func isYAMLCollection(v interface{}) bool {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return len(val) > 0
+	case []interface{}:
+		return len(val) > 0
+	}
+	return false
+}
+
+// yamlString returns the string as is, if it is read back as a string in
+// yaml. Otherwise the string is double quoted.
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "on", "off", "true", "false", "null":
+		return strconv.Quote(s)
+	}
+	if yamlPlainRegexp.MatchString(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
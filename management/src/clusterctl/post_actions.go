@@ -2,9 +2,12 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"net"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/contiv/cluster/management/src/clusterm/manager"
@@ -15,10 +18,11 @@ type postCallback func(c *manager.Client, args []string, flags parsedFlags) erro
 type validateCallback func(args []string) error
 
 type postActioner struct {
-	args       []string
-	flags      parsedFlags
-	validateCb validateCallback
-	postCb     postCallback
+	args          []string
+	flags         parsedFlags
+	validateCb    validateCallback
+	selValidateCb validateCallback
+	postCb        postCallback
 }
 
 func newPostActioner(validateCb validateCallback, postCb postCallback) *postActioner {
@@ -28,9 +32,30 @@ func newPostActioner(validateCb validateCallback, postCb postCallback) *postActi
 	}
 }
 
+// selectable lets the command act upon the nodes that match a label selector.
+// The args are validated using validateCb, instead, when a selector is specified.
+func (npa *postActioner) selectable(validateCb validateCallback) *postActioner {
+	npa.selValidateCb = validateCb
+	return npa
+}
+
 func (npa *postActioner) procFlags(c *cli.Context) {
 	npa.flags.extraVars = c.String("extra-vars")
 	npa.flags.hostGroup = c.String("host-group")
+	npa.flags.wait = c.Bool("wait")
+	npa.flags.upgrade = manager.UpgradeOptions{
+		BatchSize:      c.Int("batch-size"),
+		MaxUnavailable: c.Int("max-unavailable"),
+		BatchPause:     c.String("batch-pause"),
+	}
+	npa.flags.force = c.Bool("force")
+	npa.flags.verify = c.Bool("verify")
+	npa.flags.forceStatus = manager.ForceStatusOptions{
+		Status: c.String("status"),
+		State:  c.String("state"),
+		Reason: c.String("reason"),
+	}
+	npa.flags.selector = c.String("selector")
 }
 
 func (npa *postActioner) procArgs(c *cli.Context) {
@@ -38,7 +63,15 @@ func (npa *postActioner) procArgs(c *cli.Context) {
 }
 
 func (npa *postActioner) action(c *manager.Client) error {
-	if err := npa.validateCb(npa.args); err != nil {
+	validateCb := npa.validateCb
+	if npa.flags.selector != "" {
+		if npa.selValidateCb == nil {
+			return errored.Errorf("the command doesn't accept a selector")
+		}
+		validateCb = npa.selValidateCb
+		c = c.WithSelector(npa.flags.selector)
+	}
+	if err := validateCb(npa.args); err != nil {
 		return err
 	}
 	return npa.postCb(c, npa.args, npa.flags)
@@ -51,19 +84,54 @@ func validateOneArg(args []string) error {
 	return nil
 }
 
+// jobPollInterval is the interval at which a job's status is polled when waiting for it
+const jobPollInterval = 2 * time.Second
+
+// followJob prints the id of a submitted job. It waits for the job to finish,
+// if the wait flag is set.
+func followJob(c *manager.Client, flags parsedFlags, id uint64, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Job ID: %d\n", id)
+	if !flags.wait {
+		return nil
+	}
+	if err := c.WaitForJob(id, jobPollInterval); err != nil {
+		return err
+	}
+	fmt.Printf("Job %d completed successfully\n", id)
+	return nil
+}
+
 func nodeCommission(c *manager.Client, args []string, flags parsedFlags) error {
 	nodeName := args[0]
-	return c.PostNodeCommission(nodeName, flags.extraVars, flags.hostGroup)
+	id, err := c.PostNodeCommission(nodeName, flags.extraVars, flags.hostGroup)
+	return followJob(c, flags, id, err)
 }
 
 func nodeDecommission(c *manager.Client, args []string, flags parsedFlags) error {
 	nodeName := args[0]
-	return c.PostNodeDecommission(nodeName, flags.extraVars)
+	if flags.force {
+		id, err := c.PostNodeForceDecommission(nodeName, flags.extraVars)
+		return followJob(c, flags, id, err)
+	}
+	id, err := c.PostNodeDecommission(nodeName, flags.extraVars)
+	return followJob(c, flags, id, err)
 }
 
 func nodeUpdate(c *manager.Client, args []string, flags parsedFlags) error {
 	nodeName := args[0]
-	return c.PostNodeUpdate(nodeName, flags.extraVars, flags.hostGroup)
+	id, err := c.PostNodeUpdate(nodeName, flags.extraVars, flags.hostGroup)
+	return followJob(c, flags, id, err)
+}
+
+func nodeForceStatus(c *manager.Client, args []string, flags parsedFlags) error {
+	nodeName := args[0]
+	if flags.forceStatus.Status == "" || flags.forceStatus.Reason == "" {
+		return errored.Errorf("both the status and the reason for the override must be specified")
+	}
+	return c.PostNodeForceStatus(nodeName, flags.forceStatus)
 }
 
 func validateMultiNodeNames(args []string) error {
@@ -74,15 +142,92 @@ func validateMultiNodeNames(args []string) error {
 }
 
 func nodesCommission(c *manager.Client, args []string, flags parsedFlags) error {
-	return c.PostNodesCommission(args, flags.extraVars, flags.hostGroup)
+	id, err := c.PostNodesCommission(args, flags.extraVars, flags.hostGroup)
+	return followJob(c, flags, id, err)
 }
 
 func nodesDecommission(c *manager.Client, args []string, flags parsedFlags) error {
-	return c.PostNodesDecommission(args, flags.extraVars)
+	if flags.force {
+		id, err := c.PostNodesForceDecommission(args, flags.extraVars)
+		return followJob(c, flags, id, err)
+	}
+	id, err := c.PostNodesDecommission(args, flags.extraVars)
+	return followJob(c, flags, id, err)
+}
+
+func nodesAdopt(c *manager.Client, args []string, flags parsedFlags) error {
+	id, err := c.PostNodesAdopt(args, flags.extraVars, flags.hostGroup, flags.verify)
+	return followJob(c, flags, id, err)
+}
+
+func nodesPurge(c *manager.Client, args []string, noop parsedFlags) error {
+	return c.PostNodesPurge(args)
 }
 
 func nodesUpdate(c *manager.Client, args []string, flags parsedFlags) error {
-	return c.PostNodesUpdate(args, flags.extraVars, flags.hostGroup)
+	id, err := c.PostNodesUpdate(args, flags.extraVars, flags.hostGroup)
+	return followJob(c, flags, id, err)
+}
+
+func nodesUpgrade(c *manager.Client, args []string, flags parsedFlags) error {
+	id, err := c.PostNodesUpgrade(args, flags.extraVars, flags.upgrade)
+	return followJob(c, flags, id, err)
+}
+
+// parseLabelArgs splits the args into the node names and the labels. A label is
+// specified as 'key=value' to set it or as 'key-' to remove it.
+func parseLabelArgs(args []string) ([]string, map[string]string) {
+	nodes := []string{}
+	labels := map[string]string{}
+	for _, arg := range args {
+		if kv := strings.SplitN(arg, "=", 2); len(kv) == 2 {
+			labels[kv[0]] = kv[1]
+		} else if strings.HasSuffix(arg, "-") {
+			// an empty value removes the label
+			labels[strings.TrimSuffix(arg, "-")] = ""
+		} else {
+			nodes = append(nodes, arg)
+		}
+	}
+	return nodes, labels
+}
+
+func validateOnlyLabels(args []string) error {
+	nodes, labels := parseLabelArgs(args)
+	if len(nodes) != 0 {
+		return errored.Errorf("no node names are expected with a selector, but received %v", nodes)
+	}
+	if len(labels) < 1 {
+		return errNoLabelArgs()
+	}
+	return nil
+}
+
+func validateOneNodeAndLabels(args []string) error {
+	nodes, labels := parseLabelArgs(args)
+	if len(nodes) != 1 {
+		return errUnexpectedArgCount("1 node name", len(nodes))
+	}
+	if len(labels) < 1 {
+		return errNoLabelArgs()
+	}
+	return nil
+}
+
+func validateMultiNodesAndLabels(args []string) error {
+	nodes, labels := parseLabelArgs(args)
+	if len(nodes) < 1 {
+		return errUnexpectedArgCount(">=1 node name", len(nodes))
+	}
+	if len(labels) < 1 {
+		return errNoLabelArgs()
+	}
+	return nil
+}
+
+func nodesLabel(c *manager.Client, args []string, noop parsedFlags) error {
+	nodes, labels := parseLabelArgs(args)
+	return c.PostNodesLabels(nodes, labels)
 }
 
 func validateMultiNodeAddrs(args []string) error {
@@ -98,7 +243,8 @@ func validateMultiNodeAddrs(args []string) error {
 }
 
 func nodesDiscover(c *manager.Client, args []string, flags parsedFlags) error {
-	return c.PostNodesDiscover(args, flags.extraVars)
+	id, err := c.PostNodesDiscover(args, flags.extraVars)
+	return followJob(c, flags, id, err)
 }
 
 func validateZeroArgs(args []string) error {
@@ -108,6 +254,21 @@ func validateZeroArgs(args []string) error {
 	return nil
 }
 
+func validateZeroOrOneArg(args []string) error {
+	if len(args) > 1 {
+		return errUnexpectedArgCount("0 or 1", len(args))
+	}
+	return nil
+}
+
+func jobCancel(c *manager.Client, args []string, noop parsedFlags) error {
+	jobLabel := "active"
+	if len(args) == 1 {
+		jobLabel = args[0]
+	}
+	return c.PostJobCancel(jobLabel)
+}
+
 func globalsSet(c *manager.Client, noop []string, flags parsedFlags) error {
 	return c.PostGlobals(flags.extraVars)
 }
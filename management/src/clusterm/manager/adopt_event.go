@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// adoptEvent triggers the adoption of the nodes that were configured outside of
+// cluster manager. The nodes are assigned the host-group and extra vars and are
+// commissioned without running the configuration on them. The configuration is
+// optionally verified before the nodes are accepted.
+type adoptEvent struct {
+	mgr       *Manager
+	nodeNames []string
+	extraVars string
+	hostGroup string
+	verify    bool
+
+	_hosts  []*configuration.AnsibleHost
+	_enodes map[string]*node
+	_job    *Job
+}
+
+// newAdoptEvent creates and returns adoptEvent
+func newAdoptEvent(mgr *Manager, nodeNames []string, extraVars, hostGroup string, verify bool) *adoptEvent {
+	return &adoptEvent{
+		mgr:       mgr,
+		nodeNames: nodeNames,
+		extraVars: extraVars,
+		hostGroup: hostGroup,
+		verify:    verify,
+	}
+}
+
+func (e *adoptEvent) String() string {
+	return fmt.Sprintf("adoptEvent: nodes: %v extra-vars: %v host-group: %q verify: %v",
+		e.nodeNames, e.extraVars, e.hostGroup, e.verify)
+}
+
+func (e *adoptEvent) job() *Job {
+	return e._job
+}
+
+func (e *adoptEvent) process() error {
+	var err error
+	e._job, err = e.mgr.submitJob(
+		e.String(),
+		e.nodeNames,
+		e.prepareJob,
+		e.verifyRunner,
+		func(status JobStatus, errRet error) {
+			// persist the host-group and vars of the nodes in either case, as the
+			// configuration state has been updated as part of the event
+			defer e.mgr.saveNodesConfigBestEffort(e.nodeNames)
+			if status == Errored {
+				logrus.Errorf("adoption job failed. Error: %v", errRet)
+				// the nodes were not touched, set assets as unallocated
+				e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetUnallocated)
+				return
+			}
+			// record the extra vars the nodes were adopted with
+			for _, host := range e._hosts {
+				host.SetExtraVars(e.extraVars)
+			}
+			// set assets as commissioned
+			e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetCommissioned)
+		})
+	return err
+}
+
+// prepareJob is run before the node verification is triggered
+func (e *adoptEvent) prepareJob() error {
+	// validate event data
+	if err := e.eventValidate(); err != nil {
+		return err
+	}
+
+	// prepare inventory
+	e.prepareInventory()
+
+	// set assets as provisioned, while their configuration is verified
+	return e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetProvisioned,
+		e.mgr.inventory.SetAssetUnallocated)
+}
+
+// eventValidate perfoms the validations
+func (e *adoptEvent) eventValidate() error {
+	var err error
+	e._enodes, err = e.mgr.commonEventValidate(e.nodeNames)
+	if err != nil {
+		return err
+	}
+
+	if !e.mgr.isValidHostGroup(e.hostGroup) {
+		return errored.Errorf("invalid or empty host-group specified: %q", e.hostGroup)
+	}
+
+	if e.verify && e.mgr.config.Ansible.VerifyPlaybook == "" {
+		return errored.Errorf("verification was requested but no verification playbook is configured")
+	}
+
+	// only the nodes that are not commissioned can be adopted
+	for _, name := range e.nodeNames {
+		if status, _ := e._enodes[name].Inv.GetStatus(); status != inventory.Unallocated {
+			return errored.Errorf("node %q is in %q status, only the unallocated nodes can be adopted", name, status)
+		}
+	}
+
+	return e.mgr.validateRoleChanges("adopting", nodeGroupChanges(e.nodeNames, e.hostGroup))
+}
+
+// prepareInventory adds the specified nodes to the specified host-group
+func (e *adoptEvent) prepareInventory() {
+	e._hosts = []*configuration.AnsibleHost{}
+	for _, name := range e.nodeNames {
+		host := e._enodes[name].Cfg.(*configuration.AnsibleHost)
+		host.SetGroup(e.hostGroup)
+		e._hosts = append(e._hosts, host)
+	}
+}
+
+// verifyRunner is the job runner that runs the verification playbook on the
+// nodes, if requested. The nodes are not configured or cleaned up.
+func (e *adoptEvent) verifyRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
+	if !e.verify {
+		fmt.Fprintf(jobLogs, "verification not requested, adopting nodes: %v\n", e.nodeNames)
+		return nil
+	}
+	outReader, cancelFunc, errCh := e.mgr.configuration.Verify(e._hosts, e.extraVars)
+	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
+		return errored.Errorf("configuration verification failed. Error: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// +build unittest
+
+package manager
+
+import (
+	"bytes"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	. "gopkg.in/check.v1"
+)
+
+type adoptEventSuite struct {
+}
+
+var _ = Suite(&adoptEventSuite{})
+
+func (s *adoptEventSuite) TestAdoptValidate(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Unallocated, inventory.Discovered},
+		"n2": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.config = DefaultConfig()
+
+	tests := map[string]struct {
+		nodes     []string
+		hostGroup string
+		verify    bool
+		exptdErr  string
+	}{
+		"invalid-host-group": {
+			nodes:     []string{"n1"},
+			hostGroup: ansibleDiscoverGroupName,
+			exptdErr:  "invalid or empty host-group specified.*",
+		},
+		"commissioned-node": {
+			nodes:     []string{"n1", "n2"},
+			hostGroup: ansibleWorkerGroupName,
+			exptdErr:  "node \"n2\" is in \"Allocated\" status, only the unallocated nodes can be adopted",
+		},
+		"no-verify-playbook": {
+			nodes:     []string{"n1"},
+			hostGroup: ansibleWorkerGroupName,
+			verify:    true,
+			exptdErr:  "verification was requested but no verification playbook is configured",
+		},
+	}
+	for key, test := range tests {
+		e := newAdoptEvent(mgr, test.nodes, "", test.hostGroup, test.verify)
+		c.Assert(e.eventValidate(), ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+
+	e := newAdoptEvent(mgr, []string{"n1"}, "", ansibleWorkerGroupName, false)
+	c.Assert(e.eventValidate(), IsNil)
+	e.prepareInventory()
+	c.Assert(mgr.nodes["n1"].Cfg.GetGroup(), Equals, ansibleWorkerGroupName)
+}
+
+func (s *adoptEventSuite) TestAdoptRunner(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Unallocated, inventory.Discovered},
+	})
+	rec := &verifyRecorder{}
+	mgr.configuration = rec
+
+	// the nodes are not touched, when verification is not requested
+	e := newAdoptEvent(mgr, []string{"n1"}, `{"foo": "bar"}`, ansibleMasterGroupName, false)
+	e._enodes = mgr.nodes
+	e.prepareInventory()
+	var logs bytes.Buffer
+	c.Assert(e.verifyRunner(make(CancelChannel), &logs), IsNil)
+	c.Assert(rec.extraVars, IsNil)
+	c.Assert(logs.String(), Equals, "verification not requested, adopting nodes: [n1]\n")
+
+	e.verify = true
+	c.Assert(e.verifyRunner(make(CancelChannel), &bytes.Buffer{}), IsNil)
+	c.Assert(rec.extraVars, DeepEquals, []string{`{"foo": "bar"}`})
+}
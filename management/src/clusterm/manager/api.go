@@ -2,10 +2,14 @@ package manager
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/contiv/cluster/management/src/configuration"
@@ -29,13 +33,30 @@ type MonitorEvent struct {
 
 // APIRequest is the general request body expected by clusterm from it's client
 type APIRequest struct {
-	Nodes     []string     `json:"nodes,omitempty"`
-	Addrs     []string     `json:"addrs,omitempty"`
-	HostGroup string       `json:"host_group,omitempty"`
-	ExtraVars string       `json:"extra_vars,omitempty"`
-	Job       string       `json:"job,omitempty"`
-	Event     MonitorEvent `json:"monitor_event,omitempty"`
-	Config    *Config      `json:"config,omitempty"`
+	Nodes       []string            `json:"nodes,omitempty"`
+	Selector    string              `json:"selector,omitempty"`
+	Addrs       []string            `json:"addrs,omitempty"`
+	HostGroup   string              `json:"host_group,omitempty"`
+	ExtraVars   string              `json:"extra_vars,omitempty"`
+	Job         string              `json:"job,omitempty"`
+	Event       MonitorEvent        `json:"monitor_event,omitempty"`
+	Force       bool                `json:"force,omitempty"`
+	Verify      bool                `json:"verify,omitempty"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+	Config      *Config             `json:"config,omitempty"`
+	Upgrade     *UpgradeOptions     `json:"upgrade,omitempty"`
+	ForceStatus *ForceStatusOptions `json:"force_status,omitempty"`
+	Query       url.Values          `json:"-"`
+}
+
+// jobLogsPollInterval is the interval at which a job's logs are checked for
+// new output, when streaming the logs of a running job
+var jobLogsPollInterval = 500 * time.Millisecond
+
+// APIJobResponse is the response body returned by clusterm for a request that
+// triggers a job. The job's status can be fetched from the URL in 'Location' header.
+type APIJobResponse struct {
+	JobID uint64 `json:"job_id"`
 }
 
 // errInvalidJSON is the error returned when an invalid json value is specified for
@@ -55,12 +76,24 @@ func errInvalidJobLabel(job string) error {
 	return errored.Errorf("Invalid or empty job label specified: %q", job)
 }
 
+// errInvalidLogOffset is the error returned when an invalid offset is
+// specified as part of job logs request
+func errInvalidLogOffset(offset string) error {
+	return errored.Errorf("Invalid offset specified for job logs: %q", offset)
+}
+
 // errInvalidEventName is the error returned when an invalid or empty event name
 // is specified as part of monitor event request
 func errInvalidEventName(event string) error {
 	return errored.Errorf("Invalid or empty event name specified: %q", event)
 }
 
+// errNilForceStatus is the error returned when no status is specified as part
+// of the request to force a node's status
+func errNilForceStatus() error {
+	return errored.Errorf("nil value specified for the status to force")
+}
+
 // errNilConfig is the error returned when a nil configuration value is
 // specified as part of clusterm configuration update request
 func errNilConfig() error {
@@ -79,19 +112,29 @@ func (m *Manager) apiLoop(errCh chan error, servingCh chan struct{}) {
 	}{
 		"GET": {
 			{"/" + getNodeInfo, emptyHdrs, get(m.oneNode)},
+			{"/" + getNodeLogs, emptyHdrs, get(m.nodeLogs)},
 			{"/" + GetNodesInfo, emptyHdrs, get(m.allNodes)},
+			{"/" + GetQuarantinedNodes, emptyHdrs, get(m.quarantinedNodes)},
 			{"/" + GetGlobals, emptyHdrs, get(m.globalsGet)},
 			{"/" + getJob, emptyHdrs, get(m.jobGet)},
+			{"/" + getJobLogs, emptyHdrs, m.jobLogsGet},
+			{"/" + GetJobsInfo, emptyHdrs, get(m.allJobs)},
 			{"/" + GetPostConfig, emptyHdrs, get(m.configGet)},
 		},
 		"POST": {
-			{"/" + PostNodesCommission, jsonContentHdrs, post(m.nodesCommission)},
-			{"/" + PostNodesDecommission, jsonContentHdrs, post(m.nodesDecommission)},
-			{"/" + PostNodesUpdate, jsonContentHdrs, post(m.nodesUpdate)},
-			{"/" + PostNodesDiscover, jsonContentHdrs, post(m.nodesDiscover)},
+			{"/" + PostNodesCommission, jsonContentHdrs, postJob(m.nodesCommission)},
+			{"/" + PostNodesDecommission, jsonContentHdrs, postJob(m.nodesDecommission)},
+			{"/" + PostNodesUpdate, jsonContentHdrs, postJob(m.nodesUpdate)},
+			{"/" + PostNodesAdopt, jsonContentHdrs, postJob(m.nodesAdopt)},
+			{"/" + PostNodesUpgrade, jsonContentHdrs, postJob(m.nodesUpgrade)},
+			{"/" + PostNodesDiscover, jsonContentHdrs, postJob(m.nodesDiscover)},
+			{"/" + PostNodesPurge, jsonContentHdrs, post(m.nodesPurge)},
+			{"/" + PostNodesLabels, jsonContentHdrs, post(m.nodesLabels)},
 			{"/" + PostGlobals, jsonContentHdrs, post(m.globalsSet)},
+			{"/" + postJobCancel, jsonContentHdrs, post(m.jobCancel)},
 			{"/" + PostMonitorEvent, jsonContentHdrs, post(m.monitorEvent)},
 			{"/" + GetPostConfig, jsonContentHdrs, post(m.configSet)},
+			{"/" + postNodeForceStatus, jsonContentHdrs, post(m.nodeForceStatus)},
 		},
 	}
 
@@ -119,51 +162,92 @@ func (m *Manager) apiLoop(errCh chan error, servingCh chan struct{}) {
 	}
 }
 
+// readAPIRequest parses the request body and url of a post request
+func readAPIRequest(r *http.Request) (*APIRequest, error) {
+	// process data from request body, if any
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &APIRequest{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+	}
+
+	// process data from url, if any
+	vars := mux.Vars(r)
+	if vars["tag"] != "" {
+		req.Nodes = append(req.Nodes, vars["tag"])
+	}
+	if vars["addr"] != "" {
+		req.Addrs = append(req.Addrs, vars["addr"])
+	}
+	if vars["job"] != "" {
+		req.Job = vars["job"]
+	}
+
+	// process query variables
+	req.ExtraVars, err = validateAndSanitizeEmptyExtraVars("extra_vars", req.ExtraVars)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
 type postCallback func(req *APIRequest) error
 
 func post(postCb postCallback) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// process data from request body, if any
-		body, err := ioutil.ReadAll(r.Body)
+		req, err := readAPIRequest(r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		req := APIRequest{}
-		if len(body) > 0 {
-			if err := json.Unmarshal(body, &req); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+		// call the handler
+		if err := postCb(req); err != nil {
+			http.Error(w,
+				err.Error(),
+				http.StatusInternalServerError)
+			return
 		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+}
 
-		// process data from url, if any
-		vars := mux.Vars(r)
-		if vars["tag"] != "" {
-			req.Nodes = append(req.Nodes, vars["tag"])
-		}
-		if vars["addr"] != "" {
-			req.Addrs = append(req.Addrs, vars["addr"])
-		}
+type postJobCallback func(req *APIRequest) (*Job, error)
 
-		// process query variables
-		req.ExtraVars, err = validateAndSanitizeEmptyExtraVars("extra_vars", req.ExtraVars)
+// postJob is like post, except that the handler triggers a job. The request is
+// responded as accepted with the job's id in the body and it's URL in 'Location' header.
+func postJob(postCb postJobCallback) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := readAPIRequest(r)
 		if err != nil {
-			http.Error(w,
-				err.Error(),
-				http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 		// call the handler
-		if err := postCb(&req); err != nil {
+		j, err := postCb(req)
+		if err != nil {
 			http.Error(w,
 				err.Error(),
 				http.StatusInternalServerError)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+		out, err := json.Marshal(APIJobResponse{JobID: j.ID()})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", fmt.Sprintf("/%s/%d", GetJobPrefix, j.ID()))
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(out)
 		return
 	}
 }
@@ -182,32 +266,101 @@ func validateAndSanitizeEmptyExtraVars(errorPrefix, extraVars string) (string, e
 	return extraVars, nil
 }
 
-func (m *Manager) nodesCommission(req *APIRequest) error {
-	me := newWaitableEvent(newCommissionEvent(m, req.Nodes, req.ExtraVars, req.HostGroup))
+// postJobEvent processes the event as a waitable event and returns the job submitted by it
+func (m *Manager) postJobEvent(e jobEvent) (*Job, error) {
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
+		return nil, err
+	}
+	return e.job(), nil
+}
+
+func (m *Manager) nodesCommission(req *APIRequest) (*Job, error) {
+	if err := m.resolveSelector(req); err != nil {
+		return nil, err
+	}
+	return m.postJobEvent(newCommissionEvent(m, req.Nodes, req.ExtraVars, req.HostGroup))
+}
+
+func (m *Manager) nodesDecommission(req *APIRequest) (*Job, error) {
+	if err := m.resolveSelector(req); err != nil {
+		return nil, err
+	}
+	return m.postJobEvent(newDecommissionEvent(m, req.Nodes, req.ExtraVars, req.Force))
+}
+
+func (m *Manager) nodesPurge(req *APIRequest) error {
+	if err := m.resolveSelector(req); err != nil {
+		return err
+	}
+	me := newWaitableEvent(newPurgeEvent(m, req.Nodes))
 	m.reqQ <- me
 	return me.waitForCompletion()
 }
 
-func (m *Manager) nodesDecommission(req *APIRequest) error {
-	me := newWaitableEvent(newDecommissionEvent(m, req.Nodes, req.ExtraVars))
+func (m *Manager) nodesAdopt(req *APIRequest) (*Job, error) {
+	if err := m.resolveSelector(req); err != nil {
+		return nil, err
+	}
+	return m.postJobEvent(newAdoptEvent(m, req.Nodes, req.ExtraVars, req.HostGroup, req.Verify))
+}
+
+func (m *Manager) nodesUpdate(req *APIRequest) (*Job, error) {
+	if err := m.resolveSelector(req); err != nil {
+		return nil, err
+	}
+	return m.postJobEvent(newUpdateEvent(m, req.Nodes, req.ExtraVars, req.HostGroup))
+}
+
+func (m *Manager) nodesUpgrade(req *APIRequest) (*Job, error) {
+	if err := m.resolveSelector(req); err != nil {
+		return nil, err
+	}
+	opts := UpgradeOptions{}
+	if req.Upgrade != nil {
+		opts = *req.Upgrade
+	}
+	return m.postJobEvent(newUpgradeEvent(m, req.Nodes, req.ExtraVars, opts))
+}
+
+func (m *Manager) nodesLabels(req *APIRequest) error {
+	if err := m.resolveSelector(req); err != nil {
+		return err
+	}
+	me := newWaitableEvent(newSetLabelsEvent(m, req.Nodes, req.Labels))
 	m.reqQ <- me
 	return me.waitForCompletion()
 }
 
-func (m *Manager) nodesUpdate(req *APIRequest) error {
-	me := newWaitableEvent(newUpdateEvent(m, req.Nodes, req.ExtraVars, req.HostGroup))
+func (m *Manager) nodesDiscover(req *APIRequest) (*Job, error) {
+	return m.postJobEvent(newDiscoverEvent(m, req.Addrs, req.ExtraVars))
+}
+
+func (m *Manager) globalsSet(req *APIRequest) error {
+	me := newWaitableEvent(newSetGlobalsEvent(m, req.ExtraVars))
 	m.reqQ <- me
 	return me.waitForCompletion()
 }
 
-func (m *Manager) nodesDiscover(req *APIRequest) error {
-	me := newWaitableEvent(newDiscoverEvent(m, req.Addrs, req.ExtraVars))
+func (m *Manager) jobCancel(req *APIRequest) error {
+	if req.Job != jobLabelActive {
+		if _, err := strconv.ParseUint(req.Job, 10, 64); err != nil {
+			return errInvalidJobLabel(req.Job)
+		}
+	}
+
+	me := newWaitableEvent(newCancelJobEvent(m, req.Job))
 	m.reqQ <- me
 	return me.waitForCompletion()
 }
 
-func (m *Manager) globalsSet(req *APIRequest) error {
-	me := newWaitableEvent(newSetGlobalsEvent(m, req.ExtraVars))
+func (m *Manager) nodeForceStatus(req *APIRequest) error {
+	if req.ForceStatus == nil {
+		return errNilForceStatus()
+	}
+
+	me := newWaitableEvent(newForceStatusEvent(m, req.Nodes[0], *req.ForceStatus))
 	m.reqQ <- me
 	return me.waitForCompletion()
 }
@@ -222,12 +375,12 @@ func (m *Manager) monitorEvent(req *APIRequest) error {
 		nodes = append(nodes, monitor.NewNode(node.Label, node.Serial, node.MgmtAddr))
 	}
 
-	switch strings.ToLower(req.Event.Name) {
-	case strings.ToLower(monitor.Discovered.String()):
-		e = newDiscoveredEvent(m, nodes)
-	case strings.ToLower(monitor.Disappeared.String()):
-		e = newDisappearedEvent(m, nodes)
-	default:
+	for _, t := range monitorEventTypes {
+		if strings.EqualFold(req.Event.Name, t.String()) {
+			e, _ = newMonitorEvent(m, t, nodes)
+		}
+	}
+	if e == nil {
 		return errInvalidEventName(req.Event.Name)
 	}
 
@@ -254,6 +407,7 @@ func get(getCb getCallback) http.HandlerFunc {
 		req := &APIRequest{
 			Nodes: []string{strings.TrimSpace(vars["tag"])},
 			Job:   strings.TrimSpace(vars["job"]),
+			Query: r.URL.Query(),
 		}
 		out, err := getCb(req)
 		if err != nil {
@@ -267,24 +421,43 @@ func get(getCb getCallback) http.HandlerFunc {
 }
 
 func (m *Manager) oneNode(req *APIRequest) ([]byte, error) {
-	node, err := m.findNode(req.Nodes[0])
-	if err != nil {
+	e := newGetNodeEvent(m, req.Nodes[0])
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
 		return nil, err
 	}
+	return e._out, nil
+}
 
-	out, err := json.Marshal(node)
-	if err != nil {
+func (m *Manager) nodeLogs(req *APIRequest) ([]byte, error) {
+	e := newGetNodeLogsEvent(m, req.Nodes[0])
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return e._out, nil
 }
 
-func (m *Manager) allNodes(noop *APIRequest) ([]byte, error) {
-	out, err := json.Marshal(m.nodes)
-	if err != nil {
+func (m *Manager) allNodes(req *APIRequest) ([]byte, error) {
+	e := newListNodesEvent(m, nodesQueryFromValues(req.Query))
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return e._out, nil
+}
+
+func (m *Manager) quarantinedNodes(noop *APIRequest) ([]byte, error) {
+	e := newListQuarantinedEvent(m)
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
+		return nil, err
+	}
+	return e._out, nil
 }
 
 func (m *Manager) globalsGet(noop *APIRequest) ([]byte, error) {
@@ -304,26 +477,141 @@ func (m *Manager) globalsGet(noop *APIRequest) ([]byte, error) {
 	return out, nil
 }
 
-func (m *Manager) jobGet(req *APIRequest) ([]byte, error) {
+// lookupJob returns the job specified by the job label. If the job is not
+// active or queued, then the job's info is returned from the job history.
+func (m *Manager) lookupJob(jobLabel string) (*Job, []byte, error) {
 	var j *Job
-	switch req.Job {
+	switch jobLabel {
 	case jobLabelActive:
-		j = m.activeJob
+		j = m.jobs.activeJob()
 	case jobLabelLast:
-		j = m.lastJob
+		j = m.jobs.getLastJob()
 	default:
-		return nil, errInvalidJobLabel(req.Job)
+		id, err := strconv.ParseUint(jobLabel, 10, 64)
+		if err != nil {
+			return nil, nil, errInvalidJobLabel(jobLabel)
+		}
+		if j = m.jobs.findJob(id); j != nil {
+			break
+		}
+		// lookup the finished jobs in the job history
+		out, err := m.jobStore.GetJob(id)
+		if err != nil {
+			logrus.Debugf("failed to get job %d from job history. Error: %v", id, err)
+			return nil, nil, errJobNotExist(jobLabel)
+		}
+		return nil, out, nil
+	}
+
+	if j == nil {
+		return nil, nil, errJobNotExist(jobLabel)
+	}
+	return j, nil, nil
+}
+
+func (m *Manager) jobGet(req *APIRequest) ([]byte, error) {
+	j, out, err := m.lookupJob(req.Job)
+	if err != nil {
+		return nil, err
 	}
+	if j == nil {
+		return out, nil
+	}
+
+	out, err = json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// jobLogsGet streams the logs of a job, starting at the offset specified in the
+// query. If 'follow' is set in the query, then the logs are streamed in chunks
+// until the job is done or the client goes away.
+func (m *Manager) jobLogsGet(w http.ResponseWriter, r *http.Request) {
+	jobLabel := strings.TrimSpace(mux.Vars(r)["job"])
+	offset := 0
+	if val := r.URL.Query().Get("offset"); val != "" {
+		var err error
+		if offset, err = strconv.Atoi(val); err != nil || offset < 0 {
+			http.Error(w, errInvalidLogOffset(val).Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	follow := r.URL.Query().Get("follow") == "true"
+
+	j, out, err := m.lookupJob(jobLabel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
 
 	if j == nil {
-		return nil, errJobNotExist(req.Job)
+		// the job has finished, serve the logs from the job history
+		info := jobInfo{}
+		if err := json.Unmarshal(out, &info); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logs := strings.Join(info.Logs, "\n")
+		if offset < len(logs) {
+			w.Write([]byte(logs[offset:]))
+		}
+		return
+	}
+
+	var closeCh <-chan bool
+	if cn, ok := w.(http.CloseNotifier); ok {
+		closeCh = cn.CloseNotify()
+	}
+	for {
+		// check the status before reading the logs, so that the logs written
+		// just before the job finished are not missed
+		done := j.isDone()
+		logs := j.LogsFrom(offset)
+		if len(logs) > 0 {
+			if _, err := w.Write(logs); err != nil {
+				return
+			}
+			offset += len(logs)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		if !follow || done {
+			return
+		}
+		select {
+		case <-time.After(jobLogsPollInterval):
+		case <-closeCh:
+			return
+		}
 	}
+}
 
-	out, err := json.Marshal(j)
+func (m *Manager) allJobs(noop *APIRequest) ([]byte, error) {
+	infos, err := m.jobStore.GetAllJobs()
 	if err != nil {
 		return nil, err
 	}
 
+	// skip the job logs as they can be fetched for individual jobs
+	jobs := []jobInfo{}
+	for _, info := range infos {
+		j := jobInfo{}
+		if err := json.Unmarshal(info, &j); err != nil {
+			return nil, err
+		}
+		j.Logs = nil
+		jobs = append(jobs, j)
+	}
+
+	out, err := json.Marshal(jobs)
+	if err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
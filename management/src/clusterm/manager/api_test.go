@@ -2,7 +2,20 @@
 
 package manager
 
-import . "gopkg.in/check.v1"
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/contiv/errored"
+	"github.com/gorilla/mux"
+
+	. "gopkg.in/check.v1"
+)
 
 type apiSuite struct {
 }
@@ -35,6 +48,13 @@ func (s *apiSuite) TestPostHandlerErrorCase(c *C) {
 			},
 			exptdErr: errInvalidEventName(""),
 		},
+		"job-cancel-invalid-label": {
+			cb: m.jobCancel,
+			arg: &APIRequest{
+				Job: "last",
+			},
+			exptdErr: errInvalidJobLabel("last"),
+		},
 		"config-event-nil-config": {
 			cb: m.configSet,
 			arg: &APIRequest{
@@ -53,7 +73,9 @@ func (s *apiSuite) TestPostHandlerErrorCase(c *C) {
 
 // some Get handlers have static error checks, this test validates those
 func (s *apiSuite) TestGetHandlerErrorCase(c *C) {
-	m := Manager{}
+	m := Manager{
+		jobs: newJobQueue(1, 1),
+	}
 	tests := map[string]struct {
 		arg      *APIRequest
 		cb       getCallback
@@ -71,6 +93,13 @@ func (s *apiSuite) TestGetHandlerErrorCase(c *C) {
 			arg:      &APIRequest{},
 			exptdErr: errInvalidJobLabel(""),
 		},
+		"job-invalid-id": {
+			cb: m.jobGet,
+			arg: &APIRequest{
+				Job: "-1",
+			},
+			exptdErr: errInvalidJobLabel("-1"),
+		},
 		"job-non-existent": {
 			cb: m.jobGet,
 			arg: &APIRequest{
@@ -86,3 +115,90 @@ func (s *apiSuite) TestGetHandlerErrorCase(c *C) {
 		c.Assert(err.Error(), Equals, test.exptdErr.Error(), Commentf("key: %s", key))
 	}
 }
+
+func (s *apiSuite) TestPostJobHandler(c *C) {
+	j := NewJob("", nil, nil)
+	j.id = 10
+	hdlr := postJob(func(req *APIRequest) (*Job, error) {
+		c.Assert(req.Nodes, DeepEquals, []string{"foo"})
+		return j, nil
+	})
+
+	r, err := http.NewRequest("POST", "/"+PostNodesCommission, strings.NewReader(`{"nodes": ["foo"]}`))
+	c.Assert(err, IsNil)
+	w := httptest.NewRecorder()
+	hdlr(w, r)
+	c.Assert(w.Code, Equals, http.StatusAccepted)
+	c.Assert(w.Header().Get("Location"), Equals, "/"+GetJobPrefix+"/10")
+	resp := APIJobResponse{}
+	c.Assert(json.Unmarshal(w.Body.Bytes(), &resp), IsNil)
+	c.Assert(resp.JobID, Equals, uint64(10))
+}
+
+func (s *apiSuite) TestPostJobHandlerError(c *C) {
+	hdlr := postJob(func(req *APIRequest) (*Job, error) {
+		return nil, errored.Errorf("test failure")
+	})
+
+	r, err := http.NewRequest("POST", "/"+PostNodesCommission, strings.NewReader(""))
+	c.Assert(err, IsNil)
+	w := httptest.NewRecorder()
+	hdlr(w, r)
+	c.Assert(w.Code, Equals, http.StatusInternalServerError)
+	c.Assert(w.Header().Get("Location"), Equals, "")
+}
+
+func (s *apiSuite) TestJobLogsGet(c *C) {
+	defer func(interval time.Duration) { jobLogsPollInterval = interval }(jobLogsPollInterval)
+	jobLogsPollInterval = 10 * time.Millisecond
+	m := Manager{
+		jobs: newJobQueue(1, 1),
+	}
+	proceedCh := make(chan struct{})
+	j := NewJob("", func(cancelCh CancelChannel, logs io.Writer) error {
+		logs.Write([]byte("foo\n"))
+		<-proceedCh
+		logs.Write([]byte("bar"))
+		return nil
+	}, func(status JobStatus, errRet error) {})
+	j.id = 1
+	c.Assert(m.jobs.tryActivate(j), Equals, true)
+	go j.Run()
+
+	r := mux.NewRouter()
+	r.Path("/" + getJobLogs).Methods("GET").HandlerFunc(m.jobLogsGet)
+	httpS := httptest.NewServer(r)
+	defer httpS.Close()
+	clstrC := Client{
+		url:   httpS.Listener.Addr().String(),
+		httpC: http.DefaultClient,
+	}
+
+	// wait for the job to log the first line
+	for len(j.LogsFrom(0)) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	var buf bytes.Buffer
+	c.Assert(clstrC.StreamJobLogs("1", 0, false, &buf), IsNil)
+	c.Assert(buf.String(), Equals, "foo\n")
+
+	// follow the logs from an offset until the job is done
+	errCh := make(chan error)
+	buf.Reset()
+	go func() {
+		errCh <- clstrC.StreamJobLogs(jobLabelActive, 2, true, &buf)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(proceedCh)
+	select {
+	case err := <-errCh:
+		c.Assert(err, IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatalf("timeout waiting for the logs to be streamed")
+	}
+	c.Assert(buf.String(), Equals, "o\nbar")
+
+	// invalid offset
+	err := clstrC.StreamJobLogs("1", -1, false, &buf)
+	c.Assert(err, ErrorMatches, ".*Invalid offset specified for job logs.*\n")
+}
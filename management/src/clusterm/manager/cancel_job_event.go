@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/contiv/errored"
+)
+
+// cancelJobEvent triggers the cancellation of a provisioning job
+type cancelJobEvent struct {
+	mgr      *Manager
+	jobLabel string
+}
+
+// newCancelJobEvent creates and returns cancelJobEvent
+func newCancelJobEvent(mgr *Manager, jobLabel string) *cancelJobEvent {
+	return &cancelJobEvent{
+		mgr:      mgr,
+		jobLabel: jobLabel,
+	}
+}
+
+func (e *cancelJobEvent) String() string {
+	return fmt.Sprintf("cancelJobEvent: %s", e.jobLabel)
+}
+
+func (e *cancelJobEvent) process() error {
+	var j *Job
+	if e.jobLabel == jobLabelActive {
+		// the job to cancel is ambiguous when more than one job is running
+		active := e.mgr.jobs.activeJobs()
+		if len(active) > 1 {
+			ids := []uint64{}
+			for _, aj := range active {
+				ids = append(ids, aj.ID())
+			}
+			return errored.Errorf("%d jobs are active (ids: %v), please specify the id of the job to cancel",
+				len(active), ids)
+		}
+		j = e.mgr.jobs.activeJob()
+	} else {
+		id, err := strconv.ParseUint(e.jobLabel, 10, 64)
+		if err != nil {
+			return errInvalidJobLabel(e.jobLabel)
+		}
+		// a queued job is just removed from the queue as it hasn't
+		// touched the assets yet
+		if j = e.mgr.jobs.remove(id); j != nil {
+			j.abort(errored.Errorf("job was canceled before it was run"))
+			e.mgr.saveJob(j)
+			e.mgr.addJobDoneLogs(j)
+			return nil
+		}
+		j = e.mgr.jobs.findJob(id)
+	}
+	if j == nil {
+		return errJobNotExist(e.jobLabel)
+	}
+
+	// the job's runner cancels the running configuration action and the job's
+	// done callback moves the assets to respective status
+	return j.Cancel()
+}
@@ -0,0 +1,34 @@
+// +build unittest
+
+package manager
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type cancelJobSuite struct {
+}
+
+var _ = Suite(&cancelJobSuite{})
+
+func (s *cancelJobSuite) TestCancelActiveJob(c *C) {
+	mgr := &Manager{jobs: newJobQueue(5, 5)}
+	j1 := testJob(1, "foo")
+	j1.setStatus(Running, nil)
+	c.Assert(mgr.jobs.tryActivate(j1), Equals, true)
+
+	// the only active job is canceled
+	c.Assert(newCancelJobEvent(mgr, jobLabelActive).process(), IsNil)
+	c.Assert(len(j1.cancelCh), Equals, 1)
+	<-j1.cancelCh
+
+	// the active job is ambiguous when more than one job is active
+	j2 := testJob(2, "bar")
+	j2.setStatus(Running, nil)
+	c.Assert(mgr.jobs.tryActivate(j2), Equals, true)
+	c.Assert(newCancelJobEvent(mgr, jobLabelActive).process(), ErrorMatches,
+		"2 jobs are active \\(ids: \\[1 2\\]\\), please specify the id of the job to cancel")
+	c.Assert(len(j1.cancelCh), Equals, 0)
+	c.Assert(newCancelJobEvent(mgr, "2").process(), IsNil)
+	c.Assert(len(j2.cancelCh), Equals, 1)
+}
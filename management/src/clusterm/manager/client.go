@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/contiv/errored"
 )
@@ -16,8 +19,9 @@ var httpErrorResp = func(rsrc string, req *APIRequest, status string, body []byt
 
 // Client provides the methods for issuing post and get requests to cluster manager
 type Client struct {
-	url   string
-	httpC *http.Client
+	url      string
+	httpC    *http.Client
+	selector string
 }
 
 // NewClient instantiates a REST based rpc client for cluster manager
@@ -25,17 +29,32 @@ func NewClient(url string) *Client {
 	return &Client{url: url, httpC: http.DefaultClient}
 }
 
+// WithSelector returns a copy of the client whose node requests act upon the
+// nodes that match the label selector, instead of the specified nodes.
+// The selector is of the form 'key1=value1,key2=value2'.
+func (c *Client) WithSelector(selector string) *Client {
+	sc := *c
+	sc.selector = selector
+	return &sc
+}
+
 func (c *Client) formURL(rsrc string) string {
 	return fmt.Sprintf("http://%s/%s", c.url, rsrc)
 }
 
-func (c *Client) doPost(rsrc string, req *APIRequest) error {
+func (c *Client) postRequest(rsrc string, req *APIRequest) (*http.Response, error) {
+	if c.selector != "" && req != nil {
+		sreq := *req
+		sreq.Nodes = nil
+		sreq.Selector = c.selector
+		req = &sreq
+	}
 
 	var reqJSON *bytes.Buffer
 	if req != nil {
 		reqJSON = new(bytes.Buffer)
 		if err := json.NewEncoder(reqJSON).Encode(req); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -51,6 +70,11 @@ func (c *Client) doPost(rsrc string, req *APIRequest) error {
 	} else {
 		resp, err = c.httpC.Post(c.formURL(rsrc), "application/json", reqJSON)
 	}
+	return resp, err
+}
+
+func (c *Client) doPost(rsrc string, req *APIRequest) error {
+	resp, err := c.postRequest(rsrc, req)
 	if err != nil {
 		return err
 	}
@@ -66,6 +90,29 @@ func (c *Client) doPost(rsrc string, req *APIRequest) error {
 	return nil
 }
 
+// doPostJob posts a request that triggers a job and returns the job's id
+func (c *Client) doPostJob(rsrc string, req *APIRequest) (uint64, error) {
+	resp, err := c.postRequest(rsrc, req)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		return 0, httpErrorResp(rsrc, req, resp.Status, body)
+	}
+
+	jobResp := APIJobResponse{}
+	if err := json.Unmarshal(body, &jobResp); err != nil {
+		return 0, errored.Errorf("failed to parse the job response %q. Error: %v", body, err)
+	}
+	return jobResp.JobID, nil
+}
+
 func (c *Client) doGet(rsrc string) ([]byte, error) {
 	resp, err := c.httpC.Get(c.formURL(rsrc))
 	if err != nil {
@@ -84,73 +131,133 @@ func (c *Client) doGet(rsrc string) ([]byte, error) {
 	return body, nil
 }
 
-// PostNodeCommission posts the request to commission a node
-func (c *Client) PostNodeCommission(nodeName, extraVars, hostGroup string) error {
+// PostNodeCommission posts the request to commission a node and returns the job id
+func (c *Client) PostNodeCommission(nodeName, extraVars, hostGroup string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     []string{nodeName},
 		HostGroup: hostGroup,
 		ExtraVars: extraVars,
 	}
-	return c.doPost(PostNodesCommission, req)
+	return c.doPostJob(PostNodesCommission, req)
 }
 
-// PostNodesCommission posts the request to commission a set of nodes
-func (c *Client) PostNodesCommission(nodeNames []string, extraVars, hostGroup string) error {
+// PostNodesCommission posts the request to commission a set of nodes and returns the job id
+func (c *Client) PostNodesCommission(nodeNames []string, extraVars, hostGroup string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     nodeNames,
 		HostGroup: hostGroup,
 		ExtraVars: extraVars,
 	}
-	return c.doPost(PostNodesCommission, req)
+	return c.doPostJob(PostNodesCommission, req)
 }
 
-// PostNodeDecommission posts the request to decommission a node
-func (c *Client) PostNodeDecommission(nodeName, extraVars string) error {
+// PostNodeDecommission posts the request to decommission a node and returns the job id
+func (c *Client) PostNodeDecommission(nodeName, extraVars string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     []string{nodeName},
 		ExtraVars: extraVars,
 	}
-	return c.doPost(PostNodesDecommission, req)
+	return c.doPostJob(PostNodesDecommission, req)
+}
+
+// PostNodesDecommission posts the request to decommission a set of nodes and returns the job id
+func (c *Client) PostNodesDecommission(nodeNames []string, extraVars string) (uint64, error) {
+	req := &APIRequest{
+		Nodes:     nodeNames,
+		ExtraVars: extraVars,
+	}
+	return c.doPostJob(PostNodesDecommission, req)
+}
+
+// PostNodesAdopt posts the request to commission a set of already configured nodes
+// into a host-group without configuring them and returns the job id. The nodes'
+// configuration is verified before they are accepted, when verify is set.
+func (c *Client) PostNodesAdopt(nodeNames []string, extraVars, hostGroup string, verify bool) (uint64, error) {
+	req := &APIRequest{
+		Nodes:     nodeNames,
+		HostGroup: hostGroup,
+		ExtraVars: extraVars,
+		Verify:    verify,
+	}
+	return c.doPostJob(PostNodesAdopt, req)
 }
 
-// PostNodesDecommission posts the request to decommission a set of nodes
-func (c *Client) PostNodesDecommission(nodeNames []string, extraVars string) error {
+// PostNodeForceDecommission posts the request to decommission a node that may not be
+// reachable and returns the job id. The cleanup is skipped for an unreachable node.
+func (c *Client) PostNodeForceDecommission(nodeName, extraVars string) (uint64, error) {
+	return c.PostNodesForceDecommission([]string{nodeName}, extraVars)
+}
+
+// PostNodesForceDecommission posts the request to decommission a set of nodes that may
+// not be reachable and returns the job id. The cleanup is skipped for the unreachable nodes.
+func (c *Client) PostNodesForceDecommission(nodeNames []string, extraVars string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     nodeNames,
 		ExtraVars: extraVars,
+		Force:     true,
+	}
+	return c.doPostJob(PostNodesDecommission, req)
+}
+
+// PostNodesPurge posts the request to delete a set of decommissioned nodes from the inventory
+func (c *Client) PostNodesPurge(nodeNames []string) error {
+	req := &APIRequest{
+		Nodes: nodeNames,
 	}
-	return c.doPost(PostNodesDecommission, req)
+	return c.doPost(PostNodesPurge, req)
+}
+
+// PostNodesLabels posts the request to set the labels of a set of nodes.
+// The labels are merged with the existing ones and a label with an empty
+// value is removed.
+func (c *Client) PostNodesLabels(nodeNames []string, labels map[string]string) error {
+	req := &APIRequest{
+		Nodes:  nodeNames,
+		Labels: labels,
+	}
+	return c.doPost(PostNodesLabels, req)
 }
 
 // PostNodeUpdate posts the request to update a node and optionally change
-// it's host-group when it is specified.
-func (c *Client) PostNodeUpdate(nodeName, extraVars, hostGroup string) error {
+// it's host-group when it is specified. It returns the job id.
+func (c *Client) PostNodeUpdate(nodeName, extraVars, hostGroup string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     []string{nodeName},
 		ExtraVars: extraVars,
 		HostGroup: hostGroup,
 	}
-	return c.doPost(PostNodesUpdate, req)
+	return c.doPostJob(PostNodesUpdate, req)
 }
 
 // PostNodesUpdate posts the request to update a set of node and optionally change
-// their host-group when it is specified.
-func (c *Client) PostNodesUpdate(nodeNames []string, extraVars, hostGroup string) error {
+// their host-group when it is specified. It returns the job id.
+func (c *Client) PostNodesUpdate(nodeNames []string, extraVars, hostGroup string) (uint64, error) {
 	req := &APIRequest{
 		Nodes:     nodeNames,
 		ExtraVars: extraVars,
 		HostGroup: hostGroup,
 	}
-	return c.doPost(PostNodesUpdate, req)
+	return c.doPostJob(PostNodesUpdate, req)
 }
 
-// PostNodesDiscover posts the request to provision a set of nodes for discovery
-func (c *Client) PostNodesDiscover(nodeAddrs []string, extraVars string) error {
+// PostNodesUpgrade posts the request to perform a rolling upgrade of a set of nodes,
+// as per the specified upgrade options. It returns the job id.
+func (c *Client) PostNodesUpgrade(nodeNames []string, extraVars string, opts UpgradeOptions) (uint64, error) {
+	req := &APIRequest{
+		Nodes:     nodeNames,
+		ExtraVars: extraVars,
+		Upgrade:   &opts,
+	}
+	return c.doPostJob(PostNodesUpgrade, req)
+}
+
+// PostNodesDiscover posts the request to provision a set of nodes for discovery and returns the job id
+func (c *Client) PostNodesDiscover(nodeAddrs []string, extraVars string) (uint64, error) {
 	req := &APIRequest{
 		Addrs:     nodeAddrs,
 		ExtraVars: extraVars,
 	}
-	return c.doPost(PostNodesDiscover, req)
+	return c.doPostJob(PostNodesDiscover, req)
 }
 
 // PostGlobals posts the request to set global extra vars
@@ -161,6 +268,12 @@ func (c *Client) PostGlobals(extraVars string) error {
 	return c.doPost(PostGlobals, req)
 }
 
+// PostJobCancel posts the request to cancel a provisioning job specified by jobLabel.
+// Accepted values of jobLabel are "active" or a job id
+func (c *Client) PostJobCancel(jobLabel string) error {
+	return c.doPost(fmt.Sprintf("%s/%s", PostJobCancelPrefix, jobLabel), nil)
+}
+
 // PostMonitorEvent posts a monitor event for one or more nodes.
 func (c *Client) PostMonitorEvent(event string, nodes []MonitorNode) error {
 	req := &APIRequest{
@@ -172,6 +285,15 @@ func (c *Client) PostMonitorEvent(event string, nodes []MonitorNode) error {
 	return c.doPost(PostMonitorEvent, req)
 }
 
+// PostNodeForceStatus posts the request to force the status and state of a node's
+// asset, bypassing the lifecycle validations
+func (c *Client) PostNodeForceStatus(nodeName string, opts ForceStatusOptions) error {
+	req := &APIRequest{
+		ForceStatus: &opts,
+	}
+	return c.doPost(fmt.Sprintf("%s/%s/%s", PostNodeForceStatusPrefix, nodeName, PostNodeForceStatusSuffix), req)
+}
+
 // PostConfig posts the request to set clusterm configuration
 func (c *Client) PostConfig(config *Config) error {
 	req := &APIRequest{
@@ -185,11 +307,35 @@ func (c *Client) GetNode(nodeName string) ([]byte, error) {
 	return c.doGet(fmt.Sprintf("%s/%s", GetNodeInfoPrefix, nodeName))
 }
 
-// GetAllNodes requests info of all known nodes
+// GetNodeLogs requests the event logs of a specified node
+func (c *Client) GetNodeLogs(nodeName string) ([]byte, error) {
+	return c.doGet(fmt.Sprintf("%s/%s/%s", GetNodeInfoPrefix, nodeName, GetNodeLogsSuffix))
+}
+
+// GetAllNodes requests info of all known nodes, or of the nodes that match
+// the client's selector
 func (c *Client) GetAllNodes() ([]byte, error) {
+	return c.GetNodes(NodesQuery{})
+}
+
+// GetNodes requests info of the nodes that match the query. The client's
+// selector, if any, is used when the query doesn't specify one.
+func (c *Client) GetNodes(query NodesQuery) ([]byte, error) {
+	if query.Selector == "" {
+		query.Selector = c.selector
+	}
+	if v := query.values(); len(v) > 0 {
+		return c.doGet(fmt.Sprintf("%s?%s", GetNodesInfo, v.Encode()))
+	}
 	return c.doGet(GetNodesInfo)
 }
 
+// GetQuarantinedNodes requests info of the nodes that are not added to the
+// inventory, as their identity is missing or conflicting
+func (c *Client) GetQuarantinedNodes() ([]byte, error) {
+	return c.doGet(GetQuarantinedNodes)
+}
+
 // GetGlobals requests the value global extra vars
 func (c *Client) GetGlobals() ([]byte, error) {
 	return c.doGet(GetGlobals)
@@ -201,7 +347,57 @@ func (c *Client) GetConfig() ([]byte, error) {
 }
 
 // GetJob requests the info of a provisioning job specified by jobLabel.
-// Accepted values of jobLabel are "active" and "last"
+// Accepted values of jobLabel are "active", "last" or a job id
 func (c *Client) GetJob(jobLabel string) ([]byte, error) {
 	return c.doGet(fmt.Sprintf("%s/%s", GetJobPrefix, jobLabel))
 }
+
+// GetAllJobs requests the info of all the jobs in job history
+func (c *Client) GetAllJobs() ([]byte, error) {
+	return c.doGet(GetJobsInfo)
+}
+
+// WaitForJob polls the status of the job with specified id, every pollInterval, until
+// the job is done. It returns an error if the job ends with Errored status.
+func (c *Client) WaitForJob(id uint64, pollInterval time.Duration) error {
+	for {
+		out, err := c.GetJob(strconv.FormatUint(id, 10))
+		if err != nil {
+			return err
+		}
+		info := jobInfo{}
+		if err := json.Unmarshal(out, &info); err != nil {
+			return errored.Errorf("failed to parse the info of job %d. Error: %v", id, err)
+		}
+		switch info.Status {
+		case Complete.String():
+			return nil
+		case Errored.String():
+			return errored.Errorf("job %d failed. Error: %s", id, info.ErrVal)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// StreamJobLogs copies the logs of a provisioning job specified by jobLabel to w,
+// starting at the specified byte offset. If follow is set, the logs are streamed
+// until the job is done. Accepted values of jobLabel are "active", "last" or a job id
+func (c *Client) StreamJobLogs(jobLabel string, offset int, follow bool, w io.Writer) error {
+	rsrc := fmt.Sprintf("%s/%s/%s?offset=%d&follow=%t", GetJobPrefix, jobLabel, GetJobLogsSuffix, offset, follow)
+	resp, err := c.httpC.Get(c.formURL(rsrc))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			body = []byte{}
+		}
+		return httpErrorResp(rsrc, nil, resp.Status, body)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
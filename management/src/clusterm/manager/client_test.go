@@ -31,6 +31,7 @@ var (
 	testGetData   = []byte("testdata123")
 	testExtraVars = "extraVars"
 	testJobLabel  = "testjob"
+	testJobID     = uint64(5)
 
 	testReqNodesBody = APIRequest{
 		Nodes: []string{testNodeName},
@@ -67,6 +68,11 @@ var (
 		ExtraVars: testExtraVars,
 	}
 
+	testReqNodesForceBody = APIRequest{
+		Nodes: []string{testNodeName},
+		Force: true,
+	}
+
 	testReqDiscoverBody = APIRequest{
 		Addrs: []string{testNodeName},
 	}
@@ -108,6 +114,21 @@ var (
 			})
 	}
 
+	acceptedReturner = func(c *C, expURL *url.URL, expBody []byte) http.HandlerFunc {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				c.Assert(r.URL.Scheme, Equals, expURL.Scheme)
+				c.Assert(r.URL.Host, Equals, expURL.Host)
+				c.Assert(r.URL.Query(), DeepEquals, expURL.Query())
+				body, err := ioutil.ReadAll(r.Body)
+				c.Assert(err, IsNil)
+				c.Assert(string(body), Equals, string(expBody))
+				w.Header().Set("Location", fmt.Sprintf("/%s/%d", GetJobPrefix, testJobID))
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(fmt.Sprintf(`{"job_id": %d}`, testJobID)))
+			})
+	}
+
 	okGetReturner = func(c *C, expURL *url.URL) http.HandlerFunc {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
@@ -149,6 +170,9 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 	var reqNodesHostGroupExtraVarsBody bytes.Buffer
 	c.Assert(json.NewEncoder(&reqNodesHostGroupExtraVarsBody).Encode(testReqNodesHostGroupExtraVarsBody), IsNil)
 
+	var reqNodesForceBody bytes.Buffer
+	c.Assert(json.NewEncoder(&reqNodesForceBody).Encode(testReqNodesForceBody), IsNil)
+
 	var reqDiscoverBody bytes.Buffer
 	c.Assert(json.NewEncoder(&reqDiscoverBody).Encode(testReqDiscoverBody), IsNil)
 
@@ -161,7 +185,7 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 		extraVars string
 		hostGroup string
 		exptdBody []byte
-		cb        func(names []string, extraVars string, hostGroup string) error
+		cb        func(names []string, extraVars string, hostGroup string) (uint64, error)
 	}{
 		"commission": {
 			expURLStr: fmt.Sprintf("http://%s/%s", baseURL, PostNodesCommission),
@@ -232,10 +256,12 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 		expURL, err := url.Parse(test.expURLStr)
 		c.Assert(err, IsNil, Commentf("test: %s", testname))
 
-		httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, test.exptdBody))
+		httpS, httpC := getHTTPTestClientAndServer(c, acceptedReturner(c, expURL, test.exptdBody))
 		defer httpS.Close()
 		clstrC.httpC = httpC
-		c.Assert(test.cb(test.nodeNames, test.extraVars, test.hostGroup), IsNil, Commentf("test: %s", testname))
+		id, err := test.cb(test.nodeNames, test.extraVars, test.hostGroup)
+		c.Assert(err, IsNil, Commentf("test: %s", testname))
+		c.Assert(id, Equals, testJobID, Commentf("test: %s", testname))
 	}
 
 	tests := map[string]struct {
@@ -243,7 +269,7 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 		nodeNames []string
 		extraVars string
 		exptdBody []byte
-		cb        func(names []string, extraVars string) error
+		cb        func(names []string, extraVars string) (uint64, error)
 	}{
 		"decommission": {
 			expURLStr: fmt.Sprintf("http://%s/%s", baseURL, PostNodesDecommission),
@@ -259,6 +285,13 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 			exptdBody: reqNodesExtraVarsBody.Bytes(),
 			cb:        clstrC.PostNodesDecommission,
 		},
+		"force-decommission": {
+			expURLStr: fmt.Sprintf("http://%s/%s", baseURL, PostNodesDecommission),
+			nodeNames: []string{testNodeName},
+			extraVars: "",
+			exptdBody: reqNodesForceBody.Bytes(),
+			cb:        clstrC.PostNodesForceDecommission,
+		},
 		"discover": {
 			expURLStr: fmt.Sprintf("http://%s/%s", baseURL, PostNodesDiscover),
 			nodeNames: []string{testNodeName},
@@ -278,13 +311,38 @@ func (s *managerSuite) TestPostMultiNodesSuccess(c *C) {
 		expURL, err := url.Parse(test.expURLStr)
 		c.Assert(err, IsNil, Commentf("test: %s", testname))
 
-		httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, test.exptdBody))
+		httpS, httpC := getHTTPTestClientAndServer(c, acceptedReturner(c, expURL, test.exptdBody))
 		defer httpS.Close()
 		clstrC.httpC = httpC
-		c.Assert(test.cb(test.nodeNames, test.extraVars), IsNil, Commentf("test: %s", testname))
+		id, err := test.cb(test.nodeNames, test.extraVars)
+		c.Assert(err, IsNil, Commentf("test: %s", testname))
+		c.Assert(id, Equals, testJobID, Commentf("test: %s", testname))
 	}
 }
 
+func (s *managerSuite) TestPostNodesUpgradeSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesUpgrade)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	opts := UpgradeOptions{BatchSize: 2, MaxUnavailable: 1, BatchPause: "10s"}
+	var reqBody bytes.Buffer
+	c.Assert(json.NewEncoder(&reqBody).Encode(APIRequest{
+		Nodes:     []string{testNodeName},
+		ExtraVars: testExtraVars,
+		Upgrade:   &opts,
+	}), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, acceptedReturner(c, expURL, reqBody.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	id, err := clstrC.PostNodesUpgrade([]string{testNodeName}, testExtraVars, opts)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, testJobID)
+}
+
 func (s *managerSuite) TestPostGlobalsWithVarsSuccess(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostGlobals)
 	expURL, err := url.Parse(expURLStr)
@@ -348,6 +406,130 @@ func (s *managerSuite) TestPostMonitorEvent(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *managerSuite) TestPostJobCancelSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s/%s", baseURL, PostJobCancelPrefix, jobLabelActive)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, []byte{}))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	err = clstrC.PostJobCancel(jobLabelActive)
+	c.Assert(err, IsNil)
+}
+
+func (s *managerSuite) TestPostNodeForceStatusSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s/%s/%s", baseURL, PostNodeForceStatusPrefix, testNodeName,
+		PostNodeForceStatusSuffix)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	opts := ForceStatusOptions{
+		Status: "Unallocated",
+		State:  "Discovered",
+		Reason: "node was reinstalled",
+	}
+	var reqJSON bytes.Buffer
+	c.Assert(json.NewEncoder(&reqJSON).Encode(&APIRequest{ForceStatus: &opts}), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, reqJSON.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	err = clstrC.PostNodeForceStatus(testNodeName, opts)
+	c.Assert(err, IsNil)
+}
+
+func (s *managerSuite) TestPostNodesAdoptSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesAdopt)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	reqBody := &APIRequest{
+		Nodes:     []string{testNodeName},
+		HostGroup: ansibleMasterGroupName,
+		ExtraVars: testExtraVars,
+		Verify:    true,
+	}
+	var reqJSON bytes.Buffer
+	c.Assert(json.NewEncoder(&reqJSON).Encode(reqBody), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, acceptedReturner(c, expURL, reqJSON.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	id, err := clstrC.PostNodesAdopt([]string{testNodeName}, testExtraVars, ansibleMasterGroupName, true)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, testJobID)
+}
+
+func (s *managerSuite) TestPostNodesPurgeSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesPurge)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	var reqBody bytes.Buffer
+	c.Assert(json.NewEncoder(&reqBody).Encode(testReqNodesBody), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, reqBody.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	err = clstrC.PostNodesPurge([]string{testNodeName})
+	c.Assert(err, IsNil)
+}
+
+func (s *managerSuite) TestPostNodesLabelsSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesLabels)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	reqBody := &APIRequest{
+		Nodes:  []string{testNodeName},
+		Labels: map[string]string{"zone": "a", "rack": ""},
+	}
+	var reqJSON bytes.Buffer
+	c.Assert(json.NewEncoder(&reqJSON).Encode(reqBody), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, reqJSON.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	err = clstrC.PostNodesLabels([]string{testNodeName}, map[string]string{"zone": "a", "rack": ""})
+	c.Assert(err, IsNil)
+}
+
+func (s *managerSuite) TestPostNodesWithSelectorSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesUpdate)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	reqBody := &APIRequest{
+		Selector:  "zone=a",
+		ExtraVars: testExtraVars,
+	}
+	var reqJSON bytes.Buffer
+	c.Assert(json.NewEncoder(&reqJSON).Encode(reqBody), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, acceptedReturner(c, expURL, reqJSON.Bytes()))
+	defer httpS.Close()
+	clstrC := &Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	// the nodes, if any, are replaced by the selector
+	id, err := clstrC.WithSelector("zone=a").PostNodesUpdate(nil, testExtraVars, "")
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, testJobID)
+	c.Assert(clstrC.selector, Equals, "")
+}
+
 func (s *managerSuite) TestPostConfigSuccess(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, GetPostConfig)
 	expURL, err := url.Parse(expURLStr)
@@ -377,10 +559,81 @@ func (s *managerSuite) TestPostError(c *C) {
 		url:   baseURL,
 		httpC: httpC,
 	}
-	err = clstrC.PostNodesUpdate([]string{testNodeName}, "", "")
+	_, err = clstrC.PostNodesUpdate([]string{testNodeName}, "", "")
 	c.Assert(err, ErrorMatches, ".*test failure\n")
 }
 
+func (s *managerSuite) TestPostJobUnexpectedStatus(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, PostNodesUpdate)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	var reqBody bytes.Buffer
+	c.Assert(json.NewEncoder(&reqBody).Encode(testReqNodesBody), IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okReturner(c, expURL, reqBody.Bytes()))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+	_, err = clstrC.PostNodesUpdate([]string{testNodeName}, "", "")
+	c.Assert(err, ErrorMatches, ".*Response status: \"200 OK\".*")
+}
+
+func jobStatusReturner(c *C, expURL *url.URL, statuses []JobStatus, errVal string) http.HandlerFunc {
+	i := 0
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			c.Assert(r.URL.Path, Equals, expURL.Path)
+			info := jobInfo{
+				ID:     testJobID,
+				Status: statuses[i].String(),
+				ErrVal: errVal,
+			}
+			if i < len(statuses)-1 {
+				i++
+			}
+			out, err := json.Marshal(info)
+			c.Assert(err, IsNil)
+			w.Write(out)
+		})
+}
+
+func (s *managerSuite) TestWaitForJob(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s/%d", baseURL, GetJobPrefix, testJobID)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+
+	tests := map[string]struct {
+		statuses []JobStatus
+		errVal   string
+		exptdErr string
+	}{
+		"complete": {
+			statuses: []JobStatus{Queued, Running, Complete},
+		},
+		"errored": {
+			statuses: []JobStatus{Running, Errored},
+			errVal:   "test failure",
+			exptdErr: fmt.Sprintf("job %d failed. Error: test failure", testJobID),
+		},
+	}
+
+	for key, test := range tests {
+		httpS, httpC := getHTTPTestClientAndServer(c, jobStatusReturner(c, expURL, test.statuses, test.errVal))
+		clstrC := Client{
+			url:   baseURL,
+			httpC: httpC,
+		}
+		err := clstrC.WaitForJob(testJobID, time.Millisecond)
+		if test.exptdErr == "" {
+			c.Assert(err, IsNil, Commentf("test: %s", key))
+		} else {
+			c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+		}
+		httpS.Close()
+	}
+}
+
 func (s *managerSuite) TestGetNodeSuccess(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s/%s", baseURL, GetNodeInfoPrefix, testNodeName)
 	expURL, err := url.Parse(expURLStr)
@@ -397,6 +650,22 @@ func (s *managerSuite) TestGetNodeSuccess(c *C) {
 	c.Assert(resp, DeepEquals, testGetData)
 }
 
+func (s *managerSuite) TestGetNodeLogsSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s/%s/%s", baseURL, GetNodeInfoPrefix, testNodeName, GetNodeLogsSuffix)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okGetReturner(c, expURL))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	resp, err := clstrC.GetNodeLogs(testNodeName)
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, testGetData)
+}
+
 func (s *managerSuite) TestGetNodesSuccess(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, GetNodesInfo)
 	expURL, err := url.Parse(expURLStr)
@@ -413,6 +682,43 @@ func (s *managerSuite) TestGetNodesSuccess(c *C) {
 	c.Assert(resp, DeepEquals, testGetData)
 }
 
+func (s *managerSuite) TestGetNodesWithSelectorSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s?selector=zone%%3Da%%2Crack%%3Dr1", baseURL, GetNodesInfo)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okGetReturner(c, expURL))
+	defer httpS.Close()
+	clstrC := &Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	resp, err := clstrC.WithSelector("zone=a,rack=r1").GetAllNodes()
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, testGetData)
+}
+
+func (s *managerSuite) TestGetNodesWithQuerySuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s?host_group=%s&selector=zone%%3Da&sort=status&status=Allocated",
+		baseURL, GetNodesInfo, ansibleWorkerGroupName)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okGetReturner(c, expURL))
+	defer httpS.Close()
+	clstrC := &Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	resp, err := clstrC.WithSelector("zone=a").GetNodes(NodesQuery{
+		Status:    "Allocated",
+		HostGroup: ansibleWorkerGroupName,
+		Sort:      "status",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, testGetData)
+}
+
 func (s *managerSuite) TestGetGlobalsSuccess(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, GetGlobals)
 	expURL, err := url.Parse(expURLStr)
@@ -461,6 +767,38 @@ func (s *managerSuite) TestGetJobSuccess(c *C) {
 	c.Assert(resp, DeepEquals, testGetData)
 }
 
+func (s *managerSuite) TestGetQuarantinedNodesSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, GetQuarantinedNodes)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okGetReturner(c, expURL))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	resp, err := clstrC.GetQuarantinedNodes()
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, testGetData)
+}
+
+func (s *managerSuite) TestGetAllJobsSuccess(c *C) {
+	expURLStr := fmt.Sprintf("http://%s/%s", baseURL, GetJobsInfo)
+	expURL, err := url.Parse(expURLStr)
+	c.Assert(err, IsNil)
+	httpS, httpC := getHTTPTestClientAndServer(c, okGetReturner(c, expURL))
+	defer httpS.Close()
+	clstrC := Client{
+		url:   baseURL,
+		httpC: httpC,
+	}
+
+	resp, err := clstrC.GetAllJobs()
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, testGetData)
+}
+
 func (s *managerSuite) TestGetError(c *C) {
 	expURLStr := fmt.Sprintf("http://%s/%s/%s", baseURL, GetNodeInfoPrefix, testNodeName)
 	expURL, err := url.Parse(expURLStr)
@@ -9,10 +9,6 @@ import (
 	"github.com/contiv/errored"
 )
 
-func errActiveJob(desc string) error {
-	return errored.Errorf("there is already an active job, please try in sometime. Job: %s", desc)
-}
-
 // commissionEvent triggers the commission workflow
 type commissionEvent struct {
 	mgr       *Manager
@@ -22,6 +18,7 @@ type commissionEvent struct {
 
 	_hosts  configuration.SubsysHosts
 	_enodes map[string]*node
+	_job    *Job
 }
 
 // newCommissionEvent creates and returns commissionEvent
@@ -39,52 +36,52 @@ func (e *commissionEvent) String() string {
 		e.nodeNames, e.extraVars, e.hostGroup)
 }
 
+func (e *commissionEvent) job() *Job {
+	return e._job
+}
+
 func (e *commissionEvent) process() error {
-	// err shouldn't be redefined below
 	var err error
-
-	err = e.mgr.checkAndSetActiveJob(
+	e._job, err = e.mgr.submitJob(
 		e.String(),
+		e.nodeNames,
+		e.prepareJob,
 		e.configureOrCleanupOnErrorRunner,
 		func(status JobStatus, errRet error) {
+			// persist the host-group and vars of the nodes in either case, as the
+			// configuration state has been updated as part of the event
+			defer e.mgr.saveNodesConfigBestEffort(e.nodeNames)
 			if status == Errored {
 				logrus.Errorf("configuration job failed. Error: %v", errRet)
 				// set assets as unallocated
 				e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetUnallocated)
 				return
 			}
+			// record the extra vars that were applied
+			for _, host := range e._hosts.([]*configuration.AnsibleHost) {
+				host.SetExtraVars(e.extraVars)
+			}
 			// set assets as commissioned
 			e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetCommissioned)
 		})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			e.mgr.resetActiveJob()
-		}
-	}()
+	return err
+}
 
+// prepareJob is run before the node configuration is triggered
+func (e *commissionEvent) prepareJob() error {
 	// validate event data
-	if err = e.eventValidate(); err != nil {
+	if err := e.eventValidate(); err != nil {
 		return err
 	}
 
 	// prepare inventory
-	if err = e.prepareInventory(); err != nil {
+	if err := e.prepareInventory(); err != nil {
 		return err
 	}
 
 	// set assets as provisioning
-	if err = e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetProvisioning,
-		e.mgr.inventory.SetAssetUnallocated); err != nil {
-		return err
-	}
-
-	// trigger node configuration
-	go e.mgr.runActiveJob()
-
-	return nil
+	return e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetProvisioning,
+		e.mgr.inventory.SetAssetUnallocated)
 }
 
 func (e *commissionEvent) eventValidate() error {
@@ -94,46 +91,18 @@ func (e *commissionEvent) eventValidate() error {
 		return err
 	}
 
-	if !IsValidHostGroup(e.hostGroup) {
-		return errored.Errorf("invalid or empty host-group specified: %q", e.hostGroup)
+	// a flapping node is likely to disappear while it's being configured
+	if err := e.mgr.areNotFlappingNodes(e.nodeNames); err != nil {
+		return err
 	}
 
-	// when workers are being configured, make sure that there is atleast one service-master
-	if e.hostGroup == ansibleWorkerGroupName {
-		masterCommissioned := false
-		for name := range e.mgr.nodes {
-			if _, ok := e._enodes[name]; ok {
-				// skip nodes in the event
-				continue
-			}
-
-			isDiscoveredAndAllocated, err := e.mgr.isDiscoveredAndAllocatedNode(name)
-			if err != nil || !isDiscoveredAndAllocated {
-				if err != nil {
-					logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-				}
-				// skip hosts that are not yet provisioned or not in discovered state
-				continue
-			}
-
-			isMasterNode, err := e.mgr.isMasterNode(name)
-			if err != nil || !isMasterNode {
-				if err != nil {
-					logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-				}
-				//skip the hosts that are not in master group
-				continue
-			}
-
-			// found a master node
-			masterCommissioned = true
-			break
-		}
-		if !masterCommissioned {
-			return errored.Errorf("Cannot commission a worker node without existence of a master node in the cluster, make sure atleast one master node is commissioned.")
-		}
+	if !e.mgr.isValidHostGroup(e.hostGroup) {
+		return errored.Errorf("invalid or empty host-group specified: %q", e.hostGroup)
 	}
-	return nil
+
+	// make sure that the roles are left with the required nodes and the nodes
+	// they depend on
+	return e.mgr.validateRoleChanges("commissioning", nodeGroupChanges(e.nodeNames, e.hostGroup))
 }
 
 // prepareInventory adds the specified nodes to the specified host-group
@@ -149,16 +118,15 @@ func (e *commissionEvent) prepareInventory() error {
 	return nil
 }
 
-// configureOrCleanupOnErrorRunner is the job runner that runs configuration playbooks on one or more nodes.
-// It runs cleanup playbook on failure
+// configureOrCleanupOnErrorRunner is the job runner that runs configuration playbooks on one or more nodes
+// and verifies the configuration. It runs cleanup playbook on failure
 func (e *commissionEvent) configureOrCleanupOnErrorRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
-	outReader, cancelFunc, errCh := e.mgr.configuration.Configure(e._hosts, e.extraVars)
-	cfgErr := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs)
+	cfgErr := e.mgr.configureAndVerify(e.nodeNames, e._hosts, e.extraVars, cancelCh, jobLogs)
 	if cfgErr == nil {
 		return nil
 	}
 	logrus.Errorf("configuration failed, starting cleanup. Error: %s", cfgErr)
-	outReader, cancelFunc, errCh = e.mgr.configuration.Cleanup(e._hosts, e.extraVars)
+	outReader, cancelFunc, errCh := e.mgr.configuration.Cleanup(e._hosts, e.extraVars)
 	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
 		logrus.Errorf("cleanup failed. Error: %s", err)
 	}
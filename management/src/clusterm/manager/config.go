@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"text/template"
+	"time"
 
 	"github.com/contiv/cluster/management/src/boltdb"
 	"github.com/contiv/cluster/management/src/collins"
 	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/monitor"
 	"github.com/contiv/errored"
 	"github.com/imdario/mergo"
 	"github.com/mapuri/serf/client"
@@ -15,6 +18,168 @@ import (
 
 type clustermConfig struct {
 	Addr string `json:"addr"`
+	// JobHistorySize is the maximum number of jobs retained in the job history
+	JobHistorySize int `json:"job_history_size"`
+	// MaxQueuedJobs is the maximum number of jobs that can wait in the job queue
+	MaxQueuedJobs int `json:"max_queued_jobs"`
+	// MaxActiveJobs is the maximum number of jobs that can run concurrently.
+	// Only the jobs that touch disjoint set of nodes are run concurrently.
+	MaxActiveJobs int `json:"max_active_jobs"`
+	// ReappearPolicy is the action taken when a commissioned node reappears after
+	// a reboot or unreachability. It can be 'none', 'verify' or 'configure'
+	ReappearPolicy string `json:"reappear_policy"`
+	// ReappearHoldoff is the minimum time between the jobs triggered for a node
+	// on it's reappearance, like "5m". It keeps a flapping node from causing a job storm
+	ReappearHoldoff string `json:"reappear_holdoff"`
+	// DisappearPolicies specifies the action taken for the commissioned nodes that
+	// stay disappeared, per host-group
+	DisappearPolicies map[string]disappearPolicyConfig `json:"disappear_policies,omitempty"`
+	// RecoveryPolicy is the action taken on startup for the nodes that were left in a
+	// transitional status by a job interrupted by a restart. It can be 'rollback',
+	// 'rerun' or 'flag'
+	RecoveryPolicy string `json:"recovery_policy"`
+	// MonitorEventWindow is the time the monitor events are collected for, like "1s",
+	// before they are processed as a batch. The flaps of a node within the window
+	// are coalesced into the latest event. The events are not batched when it is "0s"
+	MonitorEventWindow string `json:"monitor_event_window"`
+	// NodeNameTemplate is the go template that the name of a discovered node is
+	// formed with, like "{{.Hostname}}-{{.Serial}}". The template can refer to
+	// the node's Label, Serial and Hostname. The characters that are not
+	// allowed in a collins asset tag are replaced with '-' in the name. The
+	// nodes are named "<label>-<serial>" when it is not set.
+	NodeNameTemplate string `json:"node_name_template"`
+	// FlapThreshold is the number of times a node can disappear within the
+	// FlapWindow before it is considered flapping. A flapping node can't be
+	// commissioned or updated. The flap detection is disabled when it is 0
+	FlapThreshold int `json:"flap_threshold"`
+	// FlapWindow is the time over which the node's flaps and uptime are
+	// computed, like "1h"
+	FlapWindow string `json:"flap_window"`
+	// JobStore is the boltdb that the job history is persisted in, when collins
+	// is used as the inventory. The job history is persisted in the boltdb
+	// inventory otherwise. With collins inventory, the job history is kept in
+	// memory and is lost on a restart, if it is not set
+	JobStore *boltdb.Config `json:"job_store,omitempty"`
+	// Roles declares the roles that the nodes can be commissioned into. The
+	// commission, update, adopt and decommission of nodes are validated against it
+	Roles []roleConfig `json:"roles,omitempty"`
+}
+
+// roleConfig declares a role that the nodes can be commissioned into
+type roleConfig struct {
+	// Name is the name that the role is referred to by the other roles
+	Name string `json:"name"`
+	// Group is the ansible host-group that the nodes of the role are configured
+	// in. It is same as the role's name, if not specified
+	Group string `json:"group,omitempty"`
+	// MinNodes and MaxNodes bound the number of commissioned nodes of the role,
+	// when the role has any. There is no upper bound when MaxNodes is 0
+	MinNodes int `json:"min_nodes,omitempty"`
+	MaxNodes int `json:"max_nodes,omitempty"`
+	// DependsOn lists the roles that need atleast one commissioned node, while
+	// the role has any
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Quorum requires an odd number of commissioned nodes in the role
+	Quorum bool `json:"quorum,omitempty"`
+}
+
+// disappearPolicyConfig specifies the action taken for the commissioned nodes of a
+// host-group that stay disappeared longer than a grace period
+type disappearPolicyConfig struct {
+	// GracePeriod is the time a node can stay disappeared before the action is taken, like "30m"
+	GracePeriod string `json:"grace_period"`
+	// Action is the action taken. It can be 'alert', 'maintenance' or 'replace'
+	Action string `json:"action"`
+}
+
+// reappearHoldoff validates the reappearance policy and returns the parsed holdoff time
+func (c *clustermConfig) reappearHoldoff() (time.Duration, error) {
+	switch c.ReappearPolicy {
+	case reappearPolicyNone, reappearPolicyVerify, reappearPolicyConfigure:
+	default:
+		return 0, errored.Errorf("invalid reappear policy %q, it can be one of %q, %q or %q",
+			c.ReappearPolicy, reappearPolicyNone, reappearPolicyVerify, reappearPolicyConfigure)
+	}
+	holdoff, err := time.ParseDuration(c.ReappearHoldoff)
+	if err != nil || holdoff < 0 {
+		return 0, errored.Errorf("invalid reappear holdoff %q", c.ReappearHoldoff)
+	}
+	return holdoff, nil
+}
+
+// roles validates the role declarations and returns the parsed roles. The
+// default roles are returned, if no role is declared
+func (c *clustermConfig) roles() (roles, error) {
+	if len(c.Roles) == 0 {
+		return defaultRoles, nil
+	}
+	return parseRoles(c.Roles)
+}
+
+// disappearPolicies validates the disappear policies and returns them with the
+// grace periods parsed
+func (c *clustermConfig) disappearPolicies() (map[string]disappearPolicy, error) {
+	roles, err := c.roles()
+	if err != nil {
+		return nil, err
+	}
+	policies := map[string]disappearPolicy{}
+	for group, pc := range c.DisappearPolicies {
+		if roles.byGroup(group) == nil {
+			return nil, errored.Errorf("invalid host-group %q specified in disappear policies", group)
+		}
+		switch pc.Action {
+		case disappearActionAlert, disappearActionMaintenance, disappearActionReplace:
+		default:
+			return nil, errored.Errorf("invalid disappear action %q for host-group %q, it can be one of %q, %q or %q",
+				pc.Action, group, disappearActionAlert, disappearActionMaintenance, disappearActionReplace)
+		}
+		grace, err := time.ParseDuration(pc.GracePeriod)
+		if err != nil || grace < 0 {
+			return nil, errored.Errorf("invalid disappear grace period %q for host-group %q", pc.GracePeriod, group)
+		}
+		policies[group] = disappearPolicy{grace: grace, action: pc.Action}
+	}
+	return policies, nil
+}
+
+// monitorEventWindow returns the parsed monitor event window
+func (c *clustermConfig) monitorEventWindow() (time.Duration, error) {
+	window, err := time.ParseDuration(c.MonitorEventWindow)
+	if err != nil || window < 0 {
+		return 0, errored.Errorf("invalid monitor event window %q", c.MonitorEventWindow)
+	}
+	return window, nil
+}
+
+// nodeNameTemplate returns the parsed node naming template, nil if it is not set
+func (c *clustermConfig) nodeNameTemplate() (*template.Template, error) {
+	if c.NodeNameTemplate == "" {
+		return nil, nil
+	}
+	return parseNodeNameTemplate(c.NodeNameTemplate)
+}
+
+// flapPolicy validates the flap detection config and returns the parsed policy
+func (c *clustermConfig) flapPolicy() (flapPolicy, error) {
+	if c.FlapThreshold < 0 {
+		return flapPolicy{}, errored.Errorf("invalid flap threshold %d", c.FlapThreshold)
+	}
+	window, err := time.ParseDuration(c.FlapWindow)
+	if err != nil || window <= 0 {
+		return flapPolicy{}, errored.Errorf("invalid flap window %q", c.FlapWindow)
+	}
+	return flapPolicy{threshold: c.FlapThreshold, window: window}, nil
+}
+
+// validateRecoveryPolicy validates the recovery policy
+func (c *clustermConfig) validateRecoveryPolicy() error {
+	switch c.RecoveryPolicy {
+	case recoveryPolicyRollback, recoveryPolicyRerun, recoveryPolicyFlag:
+		return nil
+	}
+	return errored.Errorf("invalid recovery policy %q, it can be one of %q, %q or %q",
+		c.RecoveryPolicy, recoveryPolicyRollback, recoveryPolicyRerun, recoveryPolicyFlag)
 }
 
 type inventorySubsysConfig struct {
@@ -22,9 +187,17 @@ type inventorySubsysConfig struct {
 	BoltDB  *boltdb.Config  `json:"boltdb,omitempty"`
 }
 
+// monitorSubsysConfig selects the monitoring subsystem. Serf is used, unless
+// the probe or the gossip config is set.
+type monitorSubsysConfig struct {
+	Probe  *monitor.ProbeConfig  `json:"probe,omitempty"`
+	Gossip *monitor.GossipConfig `json:"gossip,omitempty"`
+}
+
 // Config is the configuration to cluster manager daemon
 type Config struct {
 	Serf      client.Config                     `json:"serf"`
+	Monitor   monitorSubsysConfig               `json:"monitor"`
 	Inventory inventorySubsysConfig             `json:"inventory"`
 	Ansible   configuration.AnsibleSubsysConfig `json:"ansible"`
 	Manager   clustermConfig                    `json:"manager"`
@@ -50,11 +223,30 @@ func DefaultConfig() *Config {
 			PrivKeyFile:       "/vagrant/management/src/demo/files/insecure_private_key",
 		},
 		Manager: clustermConfig{
-			Addr: "0.0.0.0:9007",
+			Addr:               "0.0.0.0:9007",
+			JobHistorySize:     100,
+			MaxQueuedJobs:      20,
+			MaxActiveJobs:      4,
+			ReappearPolicy:     reappearPolicyNone,
+			ReappearHoldoff:    "5m",
+			RecoveryPolicy:     recoveryPolicyFlag,
+			MonitorEventWindow: "1s",
+			FlapWindow:         "1h",
+			Roles:              defaultRoleConfigs,
 		},
 	}
 }
 
+// validateReappearPolicy checks that a verification playbook is configured, when
+// the reappearing nodes are verified
+func (c *Config) validateReappearPolicy() error {
+	if c.Manager.ReappearPolicy == reappearPolicyVerify && c.Ansible.VerifyPlaybook == "" {
+		return errored.Errorf("reappear policy %q was specified but no verification playbook is configured",
+			reappearPolicyVerify)
+	}
+	return nil
+}
+
 // read parses the configuration from the specified reader
 // On success, it also return the updated receiver configuration
 func (c *Config) read(r io.Reader) (*Config, error) {
@@ -4,10 +4,12 @@ package manager
 
 import (
 	"strings"
+	"time"
 
 	"github.com/contiv/cluster/management/src/boltdb"
 	"github.com/contiv/cluster/management/src/collins"
 	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/monitor"
 	. "gopkg.in/check.v1"
 )
 
@@ -93,3 +95,179 @@ func (s *configSuite) TestMergeConfigSuccessBoltdbInventory(c *C) {
 	c.Assert(dst.Inventory.BoltDB, DeepEquals, exptdDst.Inventory.BoltDB)
 	c.Assert(dst.Inventory.Collins, Equals, (*collins.Config)(nil))
 }
+
+func (s *configSuite) TestReadConfigProbeMonitor(c *C) {
+	config := DefaultConfig()
+	confStr := `{
+		"monitor" : {
+			"probe" : {
+				"hosts" : [ "192.168.2.0/24" ],
+				"port" : 2222,
+				"identity_script" : "/usr/bin/identify"
+			}
+		}
+	}`
+	_, err := config.MergeFromReader(strings.NewReader(confStr))
+	c.Assert(err, IsNil)
+	c.Assert(config.Monitor.Probe, DeepEquals, &monitor.ProbeConfig{
+		Hosts:          []string{"192.168.2.0/24"},
+		Port:           2222,
+		IdentityScript: "/usr/bin/identify",
+	})
+	c.Assert(DefaultConfig().Monitor.Probe, Equals, (*monitor.ProbeConfig)(nil))
+}
+
+func (s *configSuite) TestReadConfigRoles(c *C) {
+	config := DefaultConfig()
+	confStr := `{
+		"manager" : {
+			"roles" : [
+				{ "name" : "service-master" },
+				{ "name" : "storage", "group" : "storage-nodes", "min_nodes" : 3, "quorum" : true, "depends_on" : [ "service-master" ] }
+			]
+		}
+	}`
+	_, err := config.MergeFromReader(strings.NewReader(confStr))
+	c.Assert(err, IsNil)
+	c.Assert(config.Manager.Roles, DeepEquals, []roleConfig{
+		{Name: "service-master"},
+		{Name: "storage", Group: "storage-nodes", MinNodes: 3, Quorum: true, DependsOn: []string{"service-master"}},
+	})
+	r, err := config.Manager.roles()
+	c.Assert(err, IsNil)
+	c.Assert(r, HasLen, 2)
+	c.Assert(r.byGroup(ansibleWorkerGroupName), IsNil)
+
+	// the default roles are used when none are declared
+	r, err = (&clustermConfig{}).roles()
+	c.Assert(err, IsNil)
+	c.Assert(r, DeepEquals, defaultRoles)
+}
+
+func (s *configSuite) TestReappearHoldoff(c *C) {
+	tests := map[string]struct {
+		config       clustermConfig
+		exptdHoldoff time.Duration
+		exptdErr     string
+	}{
+		"default": {
+			config:       DefaultConfig().Manager,
+			exptdHoldoff: 5 * time.Minute,
+		},
+		"invalid-policy": {
+			config:   clustermConfig{ReappearPolicy: "foo", ReappearHoldoff: "5m"},
+			exptdErr: "invalid reappear policy.*",
+		},
+		"invalid-holdoff": {
+			config:   clustermConfig{ReappearPolicy: reappearPolicyVerify, ReappearHoldoff: "foo"},
+			exptdErr: "invalid reappear holdoff.*",
+		},
+	}
+
+	for key, test := range tests {
+		holdoff, err := test.config.reappearHoldoff()
+		if test.exptdErr != "" {
+			c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("test: %s", key))
+		c.Assert(holdoff, Equals, test.exptdHoldoff, Commentf("test: %s", key))
+	}
+}
+
+func (s *configSuite) TestValidateReappearPolicy(c *C) {
+	config := DefaultConfig()
+	c.Assert(config.validateReappearPolicy(), IsNil)
+	config.Manager.ReappearPolicy = reappearPolicyVerify
+	c.Assert(config.validateReappearPolicy(), ErrorMatches,
+		"reappear policy \"verify\" was specified but no verification playbook is configured")
+	config.Ansible.VerifyPlaybook = "verify.yml"
+	c.Assert(config.validateReappearPolicy(), IsNil)
+}
+
+func (s *configSuite) TestMonitorEventWindow(c *C) {
+	window, err := DefaultConfig().Manager.monitorEventWindow()
+	c.Assert(err, IsNil)
+	c.Assert(window, Equals, time.Second)
+	config := clustermConfig{MonitorEventWindow: "0s"}
+	window, err = config.monitorEventWindow()
+	c.Assert(err, IsNil)
+	c.Assert(window, Equals, time.Duration(0))
+	config = clustermConfig{MonitorEventWindow: "-1s"}
+	_, err = config.monitorEventWindow()
+	c.Assert(err, ErrorMatches, "invalid monitor event window.*")
+}
+
+func (s *configSuite) TestFlapPolicy(c *C) {
+	policy, err := DefaultConfig().Manager.flapPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, DeepEquals, flapPolicy{threshold: 0, window: time.Hour})
+	config := clustermConfig{FlapThreshold: 3, FlapWindow: "10m"}
+	policy, err = config.flapPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, DeepEquals, flapPolicy{threshold: 3, window: 10 * time.Minute})
+	config = clustermConfig{FlapThreshold: -1, FlapWindow: "10m"}
+	_, err = config.flapPolicy()
+	c.Assert(err, ErrorMatches, "invalid flap threshold.*")
+	config = clustermConfig{FlapWindow: "0s"}
+	_, err = config.flapPolicy()
+	c.Assert(err, ErrorMatches, "invalid flap window.*")
+}
+
+func (s *configSuite) TestValidateRecoveryPolicy(c *C) {
+	c.Assert(DefaultConfig().Manager.validateRecoveryPolicy(), IsNil)
+	config := clustermConfig{RecoveryPolicy: recoveryPolicyRerun}
+	c.Assert(config.validateRecoveryPolicy(), IsNil)
+	config = clustermConfig{RecoveryPolicy: "foo"}
+	c.Assert(config.validateRecoveryPolicy(), ErrorMatches, "invalid recovery policy.*")
+}
+
+func (s *configSuite) TestDisappearPolicies(c *C) {
+	tests := map[string]struct {
+		policies      map[string]disappearPolicyConfig
+		exptdPolicies map[string]disappearPolicy
+		exptdErr      string
+	}{
+		"none": {
+			policies:      nil,
+			exptdPolicies: map[string]disappearPolicy{},
+		},
+		"valid": {
+			policies: map[string]disappearPolicyConfig{
+				ansibleMasterGroupName: {GracePeriod: "30m", Action: disappearActionReplace},
+			},
+			exptdPolicies: map[string]disappearPolicy{
+				ansibleMasterGroupName: {grace: 30 * time.Minute, action: disappearActionReplace},
+			},
+		},
+		"invalid-group": {
+			policies: map[string]disappearPolicyConfig{
+				"foo": {GracePeriod: "30m", Action: disappearActionAlert},
+			},
+			exptdErr: "invalid host-group.*",
+		},
+		"invalid-action": {
+			policies: map[string]disappearPolicyConfig{
+				ansibleWorkerGroupName: {GracePeriod: "30m", Action: "foo"},
+			},
+			exptdErr: "invalid disappear action.*",
+		},
+		"invalid-grace-period": {
+			policies: map[string]disappearPolicyConfig{
+				ansibleWorkerGroupName: {GracePeriod: "foo", Action: disappearActionAlert},
+			},
+			exptdErr: "invalid disappear grace period.*",
+		},
+	}
+
+	for key, test := range tests {
+		config := clustermConfig{DisappearPolicies: test.policies}
+		policies, err := config.disappearPolicies()
+		if test.exptdErr != "" {
+			c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("test: %s", key))
+		c.Assert(policies, DeepEquals, test.exptdPolicies, Commentf("test: %s", key))
+	}
+}
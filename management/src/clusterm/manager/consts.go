@@ -11,10 +11,26 @@ const (
 	// to decommission one or more assets
 	PostNodesDecommission = "decommission/nodes"
 
+	// PostNodesAdopt is the prefix for the POST REST endpoint
+	// to commission one or more already configured assets, without configuring them
+	PostNodesAdopt = "adopt/nodes"
+
+	// PostNodesPurge is the prefix for the POST REST endpoint
+	// to delete one or more decommissioned assets from the inventory
+	PostNodesPurge = "purge/nodes"
+
+	// PostNodesLabels is the prefix for the POST REST endpoint
+	// to set or remove the user defined labels of one or more assets
+	PostNodesLabels = "labels/nodes"
+
 	// PostNodesUpdate is the prefix for the POST REST endpoint
 	// to update configuration of one or more assets
 	PostNodesUpdate = "update/nodes"
 
+	// PostNodesUpgrade is the prefix for the POST REST endpoint
+	// to perform a rolling upgrade of one or more assets
+	PostNodesUpgrade = "upgrade/nodes"
+
 	// PostNodesDiscover is the prefix for the POST REST endpoint
 	// to provision one or more specified nodes for discovery
 	PostNodesDiscover = "discover/nodes"
@@ -23,34 +39,89 @@ const (
 	// to set global configuration values
 	PostGlobals = "globals"
 
+	// PostJobCancelPrefix is the prefix for the POST REST endpoint
+	// to cancel a provisioning job. {job} value can be 'active' or a job id.
+	// A queued job is removed from the job queue.
+	PostJobCancelPrefix = "cancel/job"
+	postJobCancel       = PostJobCancelPrefix + "/{job}"
+
 	// PostMonitorEvent is the prefix for the POST REST endpoint
 	// to post a monitor event for one or more nodes.
 	PostMonitorEvent = "monitor/event"
 
+	// PostNodeForceStatusPrefix is the prefix, followed by the node name and
+	// PostNodeForceStatusSuffix, for the POST REST endpoint to force the status
+	// and state of an asset bypassing the lifecycle validations
+	PostNodeForceStatusPrefix = "admin/node"
+	PostNodeForceStatusSuffix = "status"
+	postNodeForceStatus       = PostNodeForceStatusPrefix + "/{tag}/" + PostNodeForceStatusSuffix
+
 	// GetNodeInfoPrefix is the prefix for the GET REST endpoint
 	// to fetch info for an asset
 	GetNodeInfoPrefix = "info/node"
 	getNodeInfo       = GetNodeInfoPrefix + "/{tag}"
 
+	// GetNodeLogsSuffix is the suffix, following the node name, for the GET REST
+	// endpoint to fetch the event logs of an asset, oldest first
+	GetNodeLogsSuffix = "logs"
+	getNodeLogs       = getNodeInfo + "/" + GetNodeLogsSuffix
+
 	// GetNodesInfo is the prefix for the GET REST endpoint
 	// to fetch info for all know assets
 	GetNodesInfo = "info/nodes"
 
+	// GetQuarantinedNodes is the prefix for the GET REST endpoint to fetch the
+	// nodes that are not added to the inventory, as their identity is missing
+	// or conflicting
+	GetQuarantinedNodes = "info/quarantined"
+
 	// GetGlobals is the prefix for the GET REST endpoint
 	// to fetch the global configuration values
 	GetGlobals = "info/globals"
 
 	// GetJobPrefix is the prefix for the GET REST endpoint
 	// to fetch the status and logs of a provisioning job. {job} value can be
-	// 'active' or 'last'
+	// 'active', 'last' or a job id
 	GetJobPrefix = "info/job"
 	getJob       = GetJobPrefix + "/{job}"
 
+	// GetJobLogsSuffix is the suffix, following the job label, for the GET REST
+	// endpoint to stream the logs of a provisioning job. The 'offset' query
+	// parameter specifies the byte offset to start the logs from and 'follow'
+	// query parameter keeps streaming the logs until the job is done.
+	GetJobLogsSuffix = "logs"
+	getJobLogs       = getJob + "/" + GetJobLogsSuffix
+
+	// GetJobsInfo is the prefix for the GET REST endpoint
+	// to fetch the status of all the jobs in the job history
+	GetJobsInfo = "info/jobs"
+
 	// GetPostConfig is the prefix for the REST endpoint
 	// to GET current or POST updated clusterm's configuration
 	GetPostConfig = "config"
 )
 
+const (
+	// the policies for the commissioned nodes that reappear
+	reappearPolicyNone      = "none"
+	reappearPolicyVerify    = "verify"
+	reappearPolicyConfigure = "configure"
+)
+
+const (
+	// the actions for the commissioned nodes that stay disappeared
+	disappearActionAlert       = "alert"
+	disappearActionMaintenance = "maintenance"
+	disappearActionReplace     = "replace"
+)
+
+const (
+	// the policies for the nodes left in a transitional status by an interrupted job
+	recoveryPolicyRollback = "rollback"
+	recoveryPolicyRerun    = "rerun"
+	recoveryPolicyFlag     = "flag"
+)
+
 const (
 	ansibleMasterGroupName   = "service-master"
 	ansibleWorkerGroupName   = "service-worker"
@@ -58,6 +129,14 @@ const (
 	ansibleNodeNameHostVar   = "node_name"
 	ansibleNodeAddrHostVar   = "node_addr"
 
+	// nodeConfigAttr is the inventory attribute used to persist a node's configuration state
+	nodeConfigAttr = "configuration_state"
+	// nodeLabelsAttr is the inventory attribute used to persist a node's user defined labels
+	nodeLabelsAttr = "labels"
+	// labelKeyRole is the built-in label that selects the nodes by their host-group.
+	// It is reserved and can't be set by the user.
+	labelKeyRole = "role"
+
 	jobLabelActive = "active"
 	jobLabelLast   = "last"
 )
@@ -14,30 +14,40 @@ type decommissionEvent struct {
 	mgr       *Manager
 	nodeNames []string
 	extraVars string
-
-	_hosts  configuration.SubsysHosts
-	_enodes map[string]*node
+	// force allows decommissioning the nodes that are not reachable i.e. not in
+	// discovered state. The cleanup is skipped for such nodes.
+	force bool
+
+	_hosts       configuration.SubsysHosts
+	_enodes      map[string]*node
+	_unreachable []string
+	_job         *Job
 }
 
 // newDecommissionEvent creates and returns decommissionEvent
-func newDecommissionEvent(mgr *Manager, nodeNames []string, extraVars string) *decommissionEvent {
+func newDecommissionEvent(mgr *Manager, nodeNames []string, extraVars string, force bool) *decommissionEvent {
 	return &decommissionEvent{
 		mgr:       mgr,
 		nodeNames: nodeNames,
 		extraVars: extraVars,
+		force:     force,
 	}
 }
 
 func (e *decommissionEvent) String() string {
-	return fmt.Sprintf("decommissionEvent: nodes:%v extra-vars: %v", e.nodeNames, e.extraVars)
+	return fmt.Sprintf("decommissionEvent: nodes:%v extra-vars: %v force: %v", e.nodeNames, e.extraVars, e.force)
+}
+
+func (e *decommissionEvent) job() *Job {
+	return e._job
 }
 
 func (e *decommissionEvent) process() error {
-	// err shouldn't be redefined below
 	var err error
-
-	err = e.mgr.checkAndSetActiveJob(
+	e._job, err = e.mgr.submitJob(
 		e.String(),
+		e.nodeNames,
+		e.prepareJob,
 		e.cleanupRunner,
 		func(status JobStatus, errRet error) {
 			if status == Errored {
@@ -47,17 +57,14 @@ func (e *decommissionEvent) process() error {
 			// set assets as decommissioned
 			e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetDecommissioned)
 		})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			e.mgr.resetActiveJob()
-		}
-	}()
+	return err
+}
 
+// prepareJob is run before the node cleanup is triggered
+func (e *decommissionEvent) prepareJob() error {
+	var err error
 	// validate event data
-	if e._enodes, err = e.mgr.commonEventValidate(e.nodeNames); err != nil {
+	if e._enodes, err = e.eventValidate(); err != nil {
 		return err
 	}
 
@@ -67,58 +74,54 @@ func (e *decommissionEvent) process() error {
 	}
 
 	// set assets as cancelled
-	if err = e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetCancelled,
-		e.mgr.inventory.SetAssetCommissioned); err != nil {
-		return err
-	}
-
-	// trigger node cleanup
-	go e.mgr.runActiveJob()
-
-	return nil
+	return e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetCancelled,
+		e.mgr.inventory.SetAssetCommissioned)
 }
 
-// prepareInventory validates that after the cleanup on the nodes in the event,
-// one of following is still true:
-// - all nodes have been cleaned up; OR
-// - there is atleast one master node left
-func (e *decommissionEvent) prepareInventory() error {
-	mastersLeft := 0
-	workersLeft := 0
-	for name := range e.mgr.nodes {
-		if _, ok := e._enodes[name]; ok {
-			// skip the node in the event
-			continue
-		}
-		isDiscoveredAndAllocated, err := e.mgr.isDiscoveredAndAllocatedNode(name)
-		if err != nil || !isDiscoveredAndAllocated {
-			if err != nil {
-				logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-			}
-			// skip hosts that are not yet provisioned or not in discovered state
-			continue
-		}
-		isWorkerNode, err := e.mgr.isWorkerNode(name)
+// eventValidate performs the validations. The nodes that are not in discovered
+// state are accepted only when the decommission is forced
+func (e *decommissionEvent) eventValidate() (map[string]*node, error) {
+	if !e.force {
+		return e.mgr.commonEventValidate(e.nodeNames)
+	}
+
+	if len(e.nodeNames) == 0 {
+		return nil, errored.Errorf("atleast one node should be specified")
+	}
+	enodes := map[string]*node{}
+	for _, name := range e.nodeNames {
+		node, err := e.mgr.findNode(name)
 		if err != nil {
-			// skip this node
-			logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-			continue
+			return nil, err
 		}
-		if isWorkerNode {
-			workersLeft++
-		} else {
-			mastersLeft++
+		if node.Cfg == nil {
+			return nil, nodeConfigNotExistsError(name)
 		}
+		enodes[name] = node
 	}
+	return enodes, nil
+}
 
-	if workersLeft > 0 && mastersLeft <= 0 {
-		return errored.Errorf("decommissioning the specified node(s) will leave only worker nodes in the cluster, make sure all worker nodes are decommissioned before last master node.")
+// prepareInventory validates that after the cleanup on the nodes in the event,
+// the roles are left with the required nodes and the nodes they depend on
+func (e *decommissionEvent) prepareInventory() error {
+	if err := e.mgr.validateRoleChanges("decommissioning", nodeGroupChanges(e.nodeNames, "")); err != nil {
+		return err
 	}
 
-	// prepare the inventory
+	// prepare the inventory. The unreachable nodes are skipped for cleanup
 	hosts := []*configuration.AnsibleHost{}
-	for _, node := range e._enodes {
-		hosts = append(hosts, node.Cfg.(*configuration.AnsibleHost))
+	e._unreachable = []string{}
+	for _, name := range e.nodeNames {
+		isDiscovered, err := e.mgr.isDiscoveredNode(name)
+		if err != nil {
+			return err
+		}
+		if !isDiscovered {
+			e._unreachable = append(e._unreachable, name)
+			continue
+		}
+		hosts = append(hosts, e._enodes[name].Cfg.(*configuration.AnsibleHost))
 	}
 	e._hosts = hosts
 
@@ -127,6 +130,12 @@ func (e *decommissionEvent) prepareInventory() error {
 
 // cleanupRunner is the job runner that runs cleanup playbooks on one or more nodes
 func (e *decommissionEvent) cleanupRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
+	if len(e._unreachable) > 0 {
+		fmt.Fprintf(jobLogs, "skipping cleanup of unreachable nodes: %v\n", e._unreachable)
+	}
+	if len(e._hosts.([]*configuration.AnsibleHost)) == 0 {
+		return nil
+	}
 	outReader, cancelFunc, errCh := e.mgr.configuration.Cleanup(e._hosts, e.extraVars)
 	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
 		return err
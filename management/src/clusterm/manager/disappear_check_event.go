@@ -0,0 +1,185 @@
+package manager
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+)
+
+// disappearCheckInterval is the interval at which the disappeared nodes are checked
+// against the disappear policies
+var disappearCheckInterval = 30 * time.Second
+
+// disappearPolicy is the parsed form of disappearPolicyConfig
+type disappearPolicy struct {
+	grace  time.Duration
+	action string
+}
+
+// disappearance records when a node disappeared and whether the disappear
+// policy has been acted upon for it. For the replace policy, it also records the
+// job commissioning the replacement and the nodes that failed to replace it.
+type disappearance struct {
+	since       time.Time
+	handled     bool
+	replacement string
+	replaceJob  *Job
+	failedNodes map[string]bool
+}
+
+// disappearCheckLoop periodically posts the event to check the disappeared nodes
+func (m *Manager) disappearCheckLoop() {
+	for range time.Tick(disappearCheckInterval) {
+		m.reqQ <- newDisappearCheckEvent(m)
+	}
+}
+
+// disappearCheckEvent checks for the commissioned nodes that have stayed disappeared
+// beyond the grace period of their host-group and takes the action specified by the
+// disappear policy. The action is taken once per disappearance of a node.
+type disappearCheckEvent struct {
+	mgr *Manager
+}
+
+// newDisappearCheckEvent creates and returns disappearCheckEvent
+func newDisappearCheckEvent(mgr *Manager) *disappearCheckEvent {
+	return &disappearCheckEvent{
+		mgr: mgr,
+	}
+}
+
+func (e *disappearCheckEvent) String() string {
+	return "disappearCheckEvent"
+}
+
+func (e *disappearCheckEvent) process() error {
+	// check the nodes in a fixed order, so that the replacements are predictable
+	names := []string{}
+	for name := range e.mgr.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		n := e.mgr.nodes[name]
+		if n.Inv == nil || n.Cfg == nil {
+			continue
+		}
+		status, state := n.Inv.GetStatus()
+		if state != inventory.Disappeared {
+			delete(e.mgr.disappeared, name)
+			continue
+		}
+		d, ok := e.mgr.disappeared[name]
+		if !ok {
+			// this can happen for the nodes that were restored in disappeared state
+			d = &disappearance{since: time.Now()}
+			e.mgr.disappeared[name] = d
+		}
+		if d.replaceJob != nil && e.replacementFailed(name, d) {
+			// the policy is acted upon again with another replacement
+			d.handled = false
+		}
+		// the node is left in maintenance when it's replacement fails
+		if d.handled || (status != inventory.Allocated && len(d.failedNodes) == 0) {
+			continue
+		}
+		policy, ok := e.mgr.disappearPolicies[n.Cfg.GetGroup()]
+		if !ok || time.Since(d.since) < policy.grace {
+			continue
+		}
+		d.handled = true
+		e.handleDisappearance(name, n.Cfg.GetGroup(), policy, d)
+	}
+	return nil
+}
+
+// handleDisappearance takes the action specified by the policy for the disappeared node
+func (e *disappearCheckEvent) handleDisappearance(name, group string, policy disappearPolicy, d *disappearance) {
+	logrus.Errorf("ALERT: commissioned node %q of host-group %q has been disappeared for more than %s",
+		name, group, policy.grace)
+	if policy.action == disappearActionAlert {
+		return
+	}
+
+	// move the node to maintenance, it is not counted as a commissioned node anymore
+	e.mgr.setAssetsStatusBestEffort([]string{name}, e.mgr.inventory.SetAssetInMaintenance)
+	if policy.action == disappearActionMaintenance {
+		return
+	}
+
+	replacement := e.findReplacement(d.failedNodes)
+	if replacement == "" {
+		logrus.Errorf("ALERT: no discovered and unallocated node found to replace node %q of host-group %q",
+			name, group)
+		return
+	}
+	extraVars, err := validateAndSanitizeEmptyExtraVars("last applied extra vars",
+		e.mgr.nodes[name].Cfg.(*configuration.AnsibleHost).GetExtraVars())
+	if err != nil {
+		logrus.Errorf("failed to replace node %q with %q. Error: %v", name, replacement, err)
+		return
+	}
+	logrus.Infof("replacing node %q of host-group %q with node %q", name, group, replacement)
+	ce := newCommissionEvent(e.mgr, []string{replacement}, extraVars, group)
+	if err := ce.process(); err != nil {
+		logrus.Errorf("failed to replace node %q with %q. Error: %v", name, replacement, err)
+		d.addFailedNode(replacement)
+		d.handled = false
+		return
+	}
+	d.replacement = replacement
+	d.replaceJob = ce.job()
+}
+
+// replacementFailed checks if the job commissioning the replacement of a node
+// has failed. The job is forgotten once it finishes.
+func (e *disappearCheckEvent) replacementFailed(name string, d *disappearance) bool {
+	status, err := d.replaceJob.Status()
+	switch status {
+	case Complete:
+		d.replaceJob = nil
+		return false
+	case Errored:
+		logrus.Errorf("failed to replace node %q with %q. Error: %v", name, d.replacement, err)
+		d.addFailedNode(d.replacement)
+		d.replaceJob = nil
+		return true
+	}
+	return false
+}
+
+// addFailedNode records a node that failed to replace the disappeared node
+func (d *disappearance) addFailedNode(name string) {
+	if d.failedNodes == nil {
+		d.failedNodes = map[string]bool{}
+	}
+	d.failedNodes[name] = true
+}
+
+// findReplacement returns the first healthy node i.e. a discovered, unallocated
+// and not flapping node, in the order of their names. The nodes that failed to
+// replace the node before are skipped. It returns an empty string if none is found
+func (e *disappearCheckEvent) findReplacement(failedNodes map[string]bool) string {
+	names := []string{}
+	for name, n := range e.mgr.nodes {
+		if n.Inv == nil || n.Cfg == nil || failedNodes[name] {
+			continue
+		}
+		if err := e.mgr.areNotFlappingNodes([]string{name}); err != nil {
+			continue
+		}
+		status, state := n.Inv.GetStatus()
+		if status == inventory.Unallocated && state == inventory.Discovered {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
@@ -0,0 +1,121 @@
+// +build unittest
+
+package manager
+
+import (
+	"time"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/errored"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type disappearCheckSuite struct {
+}
+
+var _ = Suite(&disappearCheckSuite{})
+
+func (s *disappearCheckSuite) TestDisappearCheckAlert(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"foo": {inventory.Allocated, inventory.Disappeared},
+	})
+	mgr.disappearPolicies = map[string]disappearPolicy{
+		ansibleMasterGroupName: {grace: time.Hour, action: disappearActionAlert},
+	}
+
+	// the node is within the grace period
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	c.Assert(mgr.disappeared["foo"], NotNil)
+	c.Assert(mgr.disappeared["foo"].handled, Equals, false)
+
+	// the node is past the grace period, the node's status is left as is on alert
+	mgr.disappeared["foo"].since = time.Now().Add(-2 * time.Hour)
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	c.Assert(mgr.disappeared["foo"].handled, Equals, true)
+	status, _ := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Allocated)
+}
+
+func (s *disappearCheckSuite) TestDisappearCheckMaintenance(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Allocated, inventory.Disappeared},
+		"bar": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.disappearPolicies = map[string]disappearPolicy{
+		ansibleMasterGroupName: {grace: 0, action: disappearActionMaintenance},
+	}
+
+	// only the disappeared node is moved to maintenance, once
+	mClient.EXPECT().SetAssetStatus("foo", inventory.Maintenance.String(),
+		inventory.Disappeared.String(), gomock.Any())
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeInfo, gomock.Any())
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	status, _ := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Maintenance)
+	c.Assert(mgr.disappeared["bar"], IsNil)
+}
+
+func (s *disappearCheckSuite) TestFindReplacement(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Discovered},
+		"n2": {inventory.Unallocated, inventory.Disappeared},
+		"n3": {inventory.Unallocated, inventory.Discovered},
+		"n4": {inventory.Unallocated, inventory.Discovered},
+		"n5": {inventory.Unallocated, inventory.Discovered},
+	})
+	c.Assert(newDisappearCheckEvent(mgr).findReplacement(nil), Equals, "n3")
+
+	// the nodes that failed to replace the node and the flapping nodes are skipped
+	mgr.flapPolicy = flapPolicy{threshold: 1, window: time.Hour}
+	mgr.timelines["n4"] = &nodeTimeline{transitions: []monitorTransition{
+		{State: inventory.Discovered.String(), Time: time.Now().Add(-2 * time.Minute)},
+		{State: inventory.Disappeared.String(), Time: time.Now().Add(-time.Minute)},
+		{State: inventory.Discovered.String(), Time: time.Now()},
+	}}
+	c.Assert(newDisappearCheckEvent(mgr).findReplacement(map[string]bool{"n3": true}), Equals, "n5")
+
+	mgr = testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Discovered},
+	})
+	c.Assert(newDisappearCheckEvent(mgr).findReplacement(nil), Equals, "")
+}
+
+func (s *disappearCheckSuite) TestDisappearCheckReplaceFailed(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Maintenance, inventory.Disappeared},
+		"n3":  {inventory.Unallocated, inventory.Discovered},
+	})
+	mgr.disappearPolicies = map[string]disappearPolicy{
+		ansibleMasterGroupName: {grace: 0, action: disappearActionReplace},
+	}
+	j := NewJob("replace", nil, nil)
+	d := &disappearance{since: time.Now(), handled: true, replacement: "n3", replaceJob: j}
+	mgr.disappeared["foo"] = d
+
+	// the policy is not acted upon again while the replacement job runs
+	j.setStatus(Running, nil)
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	c.Assert(d.handled, Equals, true)
+	c.Assert(d.replaceJob, Equals, j)
+
+	// a failed replacement is retried with another node. There is none left, so
+	// the node stays in maintenance
+	j.setStatus(Errored, errored.Errorf("test failure"))
+	c.Assert(newDisappearCheckEvent(mgr).process(), IsNil)
+	c.Assert(d.handled, Equals, true)
+	c.Assert(d.replaceJob, IsNil)
+	c.Assert(d.failedNodes, DeepEquals, map[string]bool{"n3": true})
+	status, _ := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Maintenance)
+}
@@ -2,7 +2,9 @@ package manager
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/contiv/cluster/management/src/inventory"
 	"github.com/contiv/cluster/management/src/monitor"
 )
 
@@ -21,24 +23,47 @@ func newDisappearedEvent(mgr *Manager, nodes []monitor.SubsysNode) *disappearedE
 }
 
 func (e *disappearedEvent) String() string {
-	return fmt.Sprintf("disappearedEvent: %+v", e.nodes[0])
+	return fmt.Sprintf("disappearedEvent: nodes: %s", monitorNodesString(e.nodes))
 }
 
 func (e *disappearedEvent) process() error {
-	//XXX: need to form the name that adheres to collins tag requirements
-	name := e.nodes[0].GetLabel() + "-" + e.nodes[0].GetSerial()
+	return processMonitorNodes(e.nodes, func(n monitor.SubsysNode) error {
+		return e.mgr.setNodeDisappeared(n, "node disappeared from monitoring subsystem")
+	})
+}
+
+// setNodeDisappeared moves the node to disappeared state and records it's
+// disappearance, with the specified message in the node's logs
+func (m *Manager) setNodeDisappeared(mon monitor.SubsysNode, msg string) error {
+	// a quarantined node is not in the inventory, it is just released
+	if m.releaseQuarantinedNode(mon) {
+		return nil
+	}
 
-	node, err := e.mgr.findNode(name)
+	name, err := m.nodeName(mon)
+	if err != nil {
+		return err
+	}
+
+	node, err := m.findNode(name)
 	if err != nil {
 		return err
 	}
 
 	// update node's monitoring info to the one received in the event.
-	node.Mon = e.nodes[0]
+	node.Mon = mon
 
-	if err := e.mgr.inventory.SetAssetDisappeared(name); err != nil {
-		// XXX. Log this to collins
+	m.addAssetLogs([]string{name}, inventory.LogTypeNote, "%s", msg)
+	if err := m.inventory.SetAssetDisappeared(name); err != nil {
+		m.addAssetLogs([]string{name}, inventory.LogTypeError,
+			"setting asset to disappeared failed. Error: %v", err)
 		return err
 	}
+
+	// record the disappearance, for the disappear policies to act on
+	if _, ok := m.disappeared[name]; !ok {
+		m.disappeared[name] = &disappearance{since: time.Now()}
+	}
+	m.recordTransition(name, inventory.Disappeared)
 	return nil
 }
@@ -16,6 +16,7 @@ type discoverEvent struct {
 	extraVars string
 
 	_hosts configuration.SubsysHosts
+	_job   *Job
 }
 
 // newDiscoverEvent creates and returns discoverEvent
@@ -31,27 +32,27 @@ func (e *discoverEvent) String() string {
 	return fmt.Sprintf("discoverEvent: addr: %v extra-vars: %v", e.nodeAddrs, e.extraVars)
 }
 
+func (e *discoverEvent) job() *Job {
+	return e._job
+}
+
 func (e *discoverEvent) process() error {
-	// err shouldn't be redefined below
 	var err error
-
-	err = e.mgr.checkAndSetActiveJob(
+	e._job, err = e.mgr.submitJob(
 		e.String(),
+		e.nodeAddrs,
+		e.prepareJob,
 		e.discoverRunner,
 		func(status JobStatus, errRet error) {
 			if status == Errored {
 				logrus.Errorf("provisioning discovery job failed. Error: %v", errRet)
 			}
 		})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			e.mgr.resetActiveJob()
-		}
-	}()
+	return err
+}
 
+// prepareJob is run before the node discovery provisioning is triggered
+func (e *discoverEvent) prepareJob() error {
 	// validate
 	existingNodes := []string{}
 	for _, addr := range e.nodeAddrs {
@@ -61,19 +62,11 @@ func (e *discoverEvent) process() error {
 		}
 	}
 	if len(existingNodes) > 0 {
-		err = errored.Errorf("one or more nodes already exist with the specified management addresses. Existing nodes: %v", existingNodes)
-		return err
+		return errored.Errorf("one or more nodes already exist with the specified management addresses. Existing nodes: %v", existingNodes)
 	}
 
 	// prepare inventory
-	if err = e.pepareInventory(); err != nil {
-		return err
-	}
-
-	// trigger node discovery provisioning
-	go e.mgr.runActiveJob()
-
-	return nil
+	return e.pepareInventory()
 }
 
 // pepareInventory prepares the inventory
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
 	"github.com/contiv/cluster/management/src/monitor"
 )
 
@@ -23,22 +24,32 @@ func newDiscoveredEvent(mgr *Manager, nodes []monitor.SubsysNode) *discoveredEve
 }
 
 func (e *discoveredEvent) String() string {
-	return fmt.Sprintf("discoveredEvent: %+v", e.nodes[0])
+	return fmt.Sprintf("discoveredEvent: nodes: %s", monitorNodesString(e.nodes))
 }
 
 func (e *discoveredEvent) process() error {
-	//XXX: need to form the name that adheres to collins tag requirements
-	name := e.nodes[0].GetLabel() + "-" + e.nodes[0].GetSerial()
+	return processMonitorNodes(e.nodes, e.nodeDiscovered)
+}
+
+// nodeDiscovered adds a newly discovered node or moves a known node to discovered
+// state. The node is quarantined if it's identity is missing or conflicting.
+func (e *discoveredEvent) nodeDiscovered(mon monitor.SubsysNode) error {
+	name, err := e.mgr.identifyNode(mon)
+	if err != nil {
+		e.mgr.quarantineNode(mon, err.Error())
+		return nil
+	}
+	e.mgr.releaseQuarantinedNode(mon)
 
 	enode, err := e.mgr.findNode(name)
 	if err != nil && err.Error() == nodeNotExistsError(name).Error() {
 		e.mgr.nodes[name] = &node{
-			// XXX: node's role/group shall come from manager's role assignment logic or
-			// from user configuration
-			Cfg: configuration.NewAnsibleHost(name, e.nodes[0].GetMgmtAddress(),
-				ansibleMasterGroupName, map[string]string{
+			// the node's host-group is set by the user when it is commissioned,
+			// till then it is placed in the default host-group of the roles
+			Cfg: configuration.NewAnsibleHost(name, mon.GetMgmtAddress(),
+				e.mgr.getRoles().defaultGroup(), map[string]string{
 					ansibleNodeNameHostVar: name,
-					ansibleNodeAddrHostVar: e.nodes[0].GetMgmtAddress(),
+					ansibleNodeAddrHostVar: mon.GetMgmtAddress(),
 				}),
 		}
 		enode = e.mgr.nodes[name]
@@ -47,19 +58,50 @@ func (e *discoveredEvent) process() error {
 	}
 
 	// update node's monitoring info to the one received in the event
-	enode.Mon = e.nodes[0]
+	enode.Mon = mon
 	enode.Inv = e.mgr.inventory.GetAsset(name)
 	if enode.Inv == nil {
 		if err := e.mgr.inventory.AddAsset(name); err != nil {
-			// XXX. Log this to collins
 			logrus.Errorf("adding asset %q to discovered in inventory failed. Error: %s", name, err)
 			return err
 		}
 		enode.Inv = e.mgr.inventory.GetAsset(name)
-	} else if err := e.mgr.inventory.SetAssetDiscovered(name); err != nil {
-		// XXX. Log this to collins
+		e.mgr.addAssetLogs([]string{name}, inventory.LogTypeInfo,
+			"node discovered by monitoring subsystem, management address: %s", enode.Mon.GetMgmtAddress())
+		e.mgr.recordTransition(name, inventory.Discovered)
+		// persist the configuration state of the newly added node
+		if err := e.mgr.saveNodeConfig(name); err != nil {
+			logrus.Errorf("saving configuration state of %q in inventory failed. Error: %s", name, err)
+			return err
+		}
+		return nil
+	}
+
+	delete(e.mgr.disappeared, name)
+	status, state := enode.Inv.GetStatus()
+	e.mgr.addAssetLogs([]string{name}, inventory.LogTypeInfo,
+		"node discovered by monitoring subsystem, management address: %s", enode.Mon.GetMgmtAddress())
+	if err := e.mgr.inventory.SetAssetDiscovered(name); err != nil {
+		e.mgr.addAssetLogs([]string{name}, inventory.LogTypeError,
+			"setting asset to discovered failed. Error: %v", err)
 		logrus.Errorf("setting asset %q to discovered in inventory failed. Error: %s", name, err)
 		return err
 	}
+	e.mgr.recordTransition(name, inventory.Discovered)
+
+	// persist the configuration state of a node that doesn't have one yet, like
+	// the nodes added to the inventory before the configuration state was persisted
+	if enode.Inv.GetAttribute(nodeConfigAttr) == "" {
+		if err := e.mgr.saveNodeConfig(name); err != nil {
+			logrus.Errorf("saving configuration state of %q in inventory failed. Error: %s", name, err)
+			return err
+		}
+	}
+
+	// a commissioned node that reappears might have rebooted, take the
+	// corrective action as per reappear policy
+	if status == inventory.Allocated && state == inventory.Disappeared {
+		e.mgr.nodeReappeared(name)
+	}
 	return nil
 }
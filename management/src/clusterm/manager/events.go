@@ -8,6 +8,13 @@ type event interface {
 	process() error
 }
 
+// jobEvent is an event that submits a job as part of it's processing
+type jobEvent interface {
+	event
+	// job returns the job submitted by the event, if any
+	job() *Job
+}
+
 func (m *Manager) eventLoop() {
 	for {
 		me := <-m.reqQ
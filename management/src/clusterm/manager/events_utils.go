@@ -8,6 +8,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
 	"github.com/contiv/errored"
 )
 
@@ -77,3 +78,23 @@ func (m *Manager) commonEventValidate(nodeNames []string) (map[string]*node, err
 
 	return enodes, nil
 }
+
+// configureAndVerify runs the configuration on the nodes, moves them to provisioned
+// status and then runs the configuration verification on them. It returns the error
+// from the configuration or the verification, if any.
+func (m *Manager) configureAndVerify(nodeNames []string, hosts configuration.SubsysHosts,
+	extraVars string, cancelCh CancelChannel, jobLogs io.Writer) error {
+	outReader, cancelFunc, errCh := m.configuration.Configure(hosts, extraVars)
+	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
+		return err
+	}
+
+	// set assets as provisioned, while their configuration is verified
+	m.setJobAssetsStatus(nodeNames, m.inventory.SetAssetProvisioned, nil)
+
+	outReader, cancelFunc, errCh = m.configuration.Verify(hosts, extraVars)
+	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
+		return errored.Errorf("configuration verification failed. Error: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// ForceStatusOptions specifies the status and state an asset is forced to
+type ForceStatusOptions struct {
+	// Status is the inventory status of the asset, like "Allocated" or "Unallocated"
+	Status string `json:"status"`
+	// State is the inventory state of the asset, like "Discovered" or "Disappeared".
+	// The current state of the asset is retained, when it is not set.
+	State string `json:"state,omitempty"`
+	// Reason is the reason for the override. It is required
+	Reason string `json:"reason"`
+}
+
+// forceStatusEvent sets the status and state of a node's asset bypassing the
+// lifecycle validations. It is an administrative override to fix an asset whose
+// status doesn't reflect the reality.
+type forceStatusEvent struct {
+	mgr      *Manager
+	nodeName string
+	opts     ForceStatusOptions
+}
+
+// newForceStatusEvent creates and returns forceStatusEvent
+func newForceStatusEvent(mgr *Manager, nodeName string, opts ForceStatusOptions) *forceStatusEvent {
+	return &forceStatusEvent{
+		mgr:      mgr,
+		nodeName: nodeName,
+		opts:     opts,
+	}
+}
+
+func (e *forceStatusEvent) String() string {
+	return fmt.Sprintf("forceStatusEvent: node: %s status: %q state: %q reason: %q",
+		e.nodeName, e.opts.Status, e.opts.State, e.opts.Reason)
+}
+
+func (e *forceStatusEvent) process() error {
+	asset := e.mgr.inventory.GetAsset(e.nodeName)
+	if asset == nil {
+		return nodeInventoryNotExistsError(e.nodeName)
+	}
+
+	// the status set by a job's done callback would override the forced status
+	if j := e.mgr.jobs.findNodeJob(e.nodeName); j != nil {
+		return errored.Errorf("node %q is being acted upon by job %d, cancel the job or wait for it to finish before forcing it's status",
+			e.nodeName, j.ID())
+	}
+
+	status, ok := lookupAssetStatus(e.opts.Status)
+	if !ok {
+		return errored.Errorf("invalid asset status specified: %q", e.opts.Status)
+	}
+	_, state := asset.GetStatus()
+	if e.opts.State != "" {
+		if state, ok = inventory.AssetStateVals[strings.ToUpper(e.opts.State)]; !ok {
+			return errored.Errorf("invalid asset state specified: %q", e.opts.State)
+		}
+	}
+
+	return e.mgr.inventory.ForceAssetStatus(e.nodeName, status, state, e.opts.Reason)
+}
+
+// lookupAssetStatus returns the asset status with specified name, matched
+// case-insensitively like the status filter of the node listing
+func lookupAssetStatus(name string) (inventory.AssetStatus, bool) {
+	for s, status := range inventory.AssetStatusVals {
+		if strings.EqualFold(s, name) {
+			return status, true
+		}
+	}
+	return inventory.AssetStatus(0), false
+}
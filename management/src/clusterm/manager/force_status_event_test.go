@@ -0,0 +1,95 @@
+// +build unittest
+
+package manager
+
+import (
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type forceStatusSuite struct {
+}
+
+var _ = Suite(&forceStatusSuite{})
+
+func (s *forceStatusSuite) TestForceStatus(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Cancelled, inventory.Disappeared},
+	})
+	mgr.jobs = newJobQueue(1, 1)
+
+	// the current state is retained, when it is not specified
+	mClient.EXPECT().SetAssetStatus("foo", inventory.Allocated.String(), inventory.Disappeared.String(), "cleanup was done by hand")
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeNote, gomock.Any())
+	c.Assert(newForceStatusEvent(mgr, "foo", ForceStatusOptions{
+		Status: inventory.Allocated.String(),
+		Reason: "cleanup was done by hand",
+	}).process(), IsNil)
+	status, state := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Allocated)
+	c.Assert(state, Equals, inventory.Disappeared)
+
+	// the status and state are matched case-insensitively
+	mClient.EXPECT().SetAssetStatus("foo", inventory.Maintenance.String(), inventory.Discovered.String(), "checked by hand")
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeNote, gomock.Any())
+	c.Assert(newForceStatusEvent(mgr, "foo", ForceStatusOptions{
+		Status: "maintenance",
+		State:  "discovered",
+		Reason: "checked by hand",
+	}).process(), IsNil)
+	status, state = mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Maintenance)
+	c.Assert(state, Equals, inventory.Discovered)
+}
+
+func (s *forceStatusSuite) TestForceStatusFailures(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"foo": {inventory.Cancelled, inventory.Disappeared},
+	})
+	mgr.jobs = newJobQueue(1, 1)
+
+	tests := map[string]struct {
+		name     string
+		opts     ForceStatusOptions
+		exptdErr string
+	}{
+		"unknown-node": {
+			name:     "bar",
+			opts:     ForceStatusOptions{Status: inventory.Allocated.String(), Reason: "foo"},
+			exptdErr: "the inventory info for node \"bar\" doesn't exist",
+		},
+		"invalid-status": {
+			name:     "foo",
+			opts:     ForceStatusOptions{Status: "Commissioned", Reason: "foo"},
+			exptdErr: "invalid asset status specified.*",
+		},
+		"invalid-state": {
+			name:     "foo",
+			opts:     ForceStatusOptions{Status: inventory.Allocated.String(), State: "Gone", Reason: "foo"},
+			exptdErr: "invalid asset state specified.*",
+		},
+		"no-reason": {
+			name:     "foo",
+			opts:     ForceStatusOptions{Status: inventory.Allocated.String()},
+			exptdErr: "a reason is required.*",
+		},
+	}
+	for key, test := range tests {
+		err := newForceStatusEvent(mgr, test.name, test.opts).process()
+		c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+
+	// the node being acted upon by a job can't be forced
+	c.Assert(mgr.jobs.tryActivate(testJob(1, "foo")), Equals, true)
+	err := newForceStatusEvent(mgr, "foo", ForceStatusOptions{
+		Status: inventory.Allocated.String(),
+		Reason: "foo",
+	}).process()
+	c.Assert(err, ErrorMatches, "node \"foo\" is being acted upon by job 1.*")
+}
@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// getNodeEvent looks up a node along with it's monitoring history. The node is
+// looked up in the event loop so that the node table and the monitoring
+// timelines are not read while they are being updated.
+type getNodeEvent struct {
+	mgr      *Manager
+	nodeName string
+
+	_out []byte
+}
+
+// newGetNodeEvent creates and returns getNodeEvent
+func newGetNodeEvent(mgr *Manager, nodeName string) *getNodeEvent {
+	return &getNodeEvent{
+		mgr:      mgr,
+		nodeName: nodeName,
+	}
+}
+
+func (e *getNodeEvent) String() string {
+	return fmt.Sprintf("getNodeEvent: node: %s", e.nodeName)
+}
+
+func (e *getNodeEvent) process() error {
+	enode, err := e.mgr.findNode(e.nodeName)
+	if err != nil {
+		return err
+	}
+
+	e._out, err = json.Marshal(struct {
+		*node
+		History monitorHistory `json:"monitoring_history"`
+	}{
+		node:    enode,
+		History: e.mgr.nodeHistory(e.nodeName),
+	})
+	return err
+}
@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// getNodeLogsEvent looks up the inventory logs of a node. The node is looked up
+// in the event loop so that the node table is not read while it is being updated.
+type getNodeLogsEvent struct {
+	mgr      *Manager
+	nodeName string
+
+	_out []byte
+}
+
+// newGetNodeLogsEvent creates and returns getNodeLogsEvent
+func newGetNodeLogsEvent(mgr *Manager, nodeName string) *getNodeLogsEvent {
+	return &getNodeLogsEvent{
+		mgr:      mgr,
+		nodeName: nodeName,
+	}
+}
+
+func (e *getNodeLogsEvent) String() string {
+	return fmt.Sprintf("getNodeLogsEvent: node: %s", e.nodeName)
+}
+
+func (e *getNodeLogsEvent) process() error {
+	if _, err := e.mgr.findNode(e.nodeName); err != nil {
+		return err
+	}
+
+	logs, err := e.mgr.inventory.GetAssetLogs(e.nodeName)
+	if err != nil {
+		return err
+	}
+
+	e._out, err = json.Marshal(logs)
+	return err
+}
@@ -0,0 +1,33 @@
+// +build unittest
+
+package manager
+
+import (
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type getNodeLogsSuite struct {
+}
+
+var _ = Suite(&getNodeLogsSuite{})
+
+func (s *getNodeLogsSuite) TestGetNodeLogs(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Discovered},
+	})
+
+	mClient.EXPECT().GetAssetLogs("foo-1").Return([]string{"log1", "log2"}, nil)
+	e := newGetNodeLogsEvent(mgr, "foo-1")
+	c.Assert(e.process(), IsNil)
+	c.Assert(string(e._out), Equals, `["log1","log2"]`)
+
+	c.Assert(newGetNodeLogsEvent(mgr, "bar-1").process(), ErrorMatches,
+		".*node with name or address \"bar-1\" doesn't exists.*")
+}
@@ -0,0 +1,34 @@
+package manager
+
+import "fmt"
+
+// jobDoneEvent is posted when a job finishes. It runs the job's done callback,
+// records the job's outcome, applies the management address changes deferred
+// while the job ran and triggers the dispatch of the queued jobs that were
+// waiting for the job to finish.
+type jobDoneEvent struct {
+	mgr *Manager
+	job *Job
+}
+
+// newJobDoneEvent creates and returns jobDoneEvent
+func newJobDoneEvent(mgr *Manager, job *Job) *jobDoneEvent {
+	return &jobDoneEvent{
+		mgr: mgr,
+		job: job,
+	}
+}
+
+func (e *jobDoneEvent) String() string {
+	return fmt.Sprintf("jobDoneEvent: job: %d %s", e.job.ID(), e.job)
+}
+
+func (e *jobDoneEvent) process() error {
+	e.job.finish()
+	e.mgr.saveJob(e.job)
+	e.mgr.addJobDoneLogs(e.job)
+	e.mgr.jobs.done(e.job)
+	e.mgr.applyDeferredUpdates(e.job.nodes)
+	e.mgr.dispatchJobs()
+	return nil
+}
@@ -0,0 +1,250 @@
+package manager
+
+import (
+	"sync"
+
+	"github.com/contiv/errored"
+)
+
+func errJobQueueFull(maxQueued int) error {
+	return errored.Errorf("the job queue is full with %d jobs, please try in sometime", maxQueued)
+}
+
+// JobPrepare is called before a job is run. It validates the event data and
+// prepares the inventory for the job. The job is not run if it returns an error.
+type JobPrepare func() error
+
+// queuedJob is a job waiting in the job queue along with it's prepare function
+type queuedJob struct {
+	job     *Job
+	prepare JobPrepare
+}
+
+// jobQueue is a bounded FIFO queue of jobs. The jobs are dispatched in the order
+// they are submitted. The jobs that touch disjoint set of nodes are run
+// concurrently, upto a limit of active jobs. A job that touches no nodes (like a
+// configuration change) is run exclusively.
+type jobQueue struct {
+	sync.Mutex
+	maxQueued int
+	maxActive int
+	queued    []*queuedJob
+	active    []*Job // ordered by the time jobs are dispatched
+	lastJob   *Job
+}
+
+// newJobQueue creates and returns an empty job queue
+func newJobQueue(maxQueued, maxActive int) *jobQueue {
+	return &jobQueue{
+		maxQueued: maxQueued,
+		maxActive: maxActive,
+		queued:    []*queuedJob{},
+		active:    []*Job{},
+	}
+}
+
+// conflicts returns true if the two jobs can't be run at same time
+func conflicts(j1, j2 *Job) bool {
+	if len(j1.nodes) == 0 || len(j2.nodes) == 0 {
+		return true
+	}
+	nodes := map[string]struct{}{}
+	for _, n := range j1.nodes {
+		nodes[n] = struct{}{}
+	}
+	for _, n := range j2.nodes {
+		if _, ok := nodes[n]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// canRun checks if the job can be dispatched, given that it is preceded by
+// the first 'ahead' jobs in the queue. Caller shall hold the lock.
+func (q *jobQueue) canRun(j *Job, ahead int) bool {
+	if len(q.active) >= q.maxActive {
+		return false
+	}
+	for _, aj := range q.active {
+		if conflicts(j, aj) {
+			return false
+		}
+	}
+	// a job can't overtake a conflicting job that was submitted before it
+	for _, qj := range q.queued[:ahead] {
+		if conflicts(j, qj.job) {
+			return false
+		}
+	}
+	return true
+}
+
+// tryActivate marks the job as active if it can be run right away,
+// without being queued. It returns false if the job can't be run right away.
+func (q *jobQueue) tryActivate(j *Job) bool {
+	q.Lock()
+	defer q.Unlock()
+	if !q.canRun(j, len(q.queued)) {
+		return false
+	}
+	q.active = append(q.active, j)
+	return true
+}
+
+// checkRoom returns an error if the queue is full
+func (q *jobQueue) checkRoom() error {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.queued) >= q.maxQueued {
+		return errJobQueueFull(q.maxQueued)
+	}
+	return nil
+}
+
+// push adds the job to the end of the queue
+func (q *jobQueue) push(j *Job, prepare JobPrepare) error {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.queued) >= q.maxQueued {
+		return errJobQueueFull(q.maxQueued)
+	}
+	q.queued = append(q.queued, &queuedJob{job: j, prepare: prepare})
+	return nil
+}
+
+// popRunnable removes and returns the first queued job that can be run and marks
+// it as active. It returns nil if no queued job can be run at the moment.
+func (q *jobQueue) popRunnable() *queuedJob {
+	q.Lock()
+	defer q.Unlock()
+	for i, qj := range q.queued {
+		if !q.canRun(qj.job, i) {
+			continue
+		}
+		q.queued = append(q.queued[:i], q.queued[i+1:]...)
+		q.active = append(q.active, qj.job)
+		return qj
+	}
+	return nil
+}
+
+// remove removes the job with specified id from the queue. It returns nil if the
+// job is not queued.
+func (q *jobQueue) remove(id uint64) *Job {
+	q.Lock()
+	defer q.Unlock()
+	for i, qj := range q.queued {
+		if qj.job.ID() == id {
+			q.queued = append(q.queued[:i], q.queued[i+1:]...)
+			return qj.job
+		}
+	}
+	return nil
+}
+
+// removeActive removes the job from the set of active jobs. Caller shall hold the lock.
+func (q *jobQueue) removeActive(j *Job) {
+	for i, aj := range q.active {
+		if aj == j {
+			q.active = append(q.active[:i], q.active[i+1:]...)
+			return
+		}
+	}
+}
+
+// discard removes an active job that couldn't be run, without recording it as the last job
+func (q *jobQueue) discard(j *Job) {
+	q.Lock()
+	defer q.Unlock()
+	q.removeActive(j)
+}
+
+// done removes the job from the set of active jobs and records it as the last job
+func (q *jobQueue) done(j *Job) {
+	q.Lock()
+	defer q.Unlock()
+	q.removeActive(j)
+	q.lastJob = j
+}
+
+// activeJob returns the oldest active job, if any
+func (q *jobQueue) activeJob() *Job {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.active) == 0 {
+		return nil
+	}
+	return q.active[0]
+}
+
+// activeJobs returns the active jobs, oldest first
+func (q *jobQueue) activeJobs() []*Job {
+	q.Lock()
+	defer q.Unlock()
+	return append([]*Job{}, q.active...)
+}
+
+// isIdle returns true if no job is active or queued
+func (q *jobQueue) isIdle() bool {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.active) == 0 && len(q.queued) == 0
+}
+
+// getLastJob returns the job that finished last, if any
+func (q *jobQueue) getLastJob() *Job {
+	q.Lock()
+	defer q.Unlock()
+	return q.lastJob
+}
+
+// findJob returns the active or queued job with specified id. It returns nil
+// if no such job is found.
+func (q *jobQueue) findJob(id uint64) *Job {
+	q.Lock()
+	defer q.Unlock()
+	for _, aj := range q.active {
+		if aj.ID() == id {
+			return aj
+		}
+	}
+	for _, qj := range q.queued {
+		if qj.job.ID() == id {
+			return qj.job
+		}
+	}
+	return nil
+}
+
+// findNodeJob returns the active or queued job that touches the specified node.
+// It returns nil if no such job is found.
+func (q *jobQueue) findNodeJob(name string) *Job {
+	q.Lock()
+	defer q.Unlock()
+	jobs := append([]*Job{}, q.active...)
+	for _, qj := range q.queued {
+		jobs = append(jobs, qj.job)
+	}
+	return nodeJob(jobs, name)
+}
+
+// findActiveNodeJob returns the active job that touches the specified node.
+// It returns nil if no such job is found.
+func (q *jobQueue) findActiveNodeJob(name string) *Job {
+	q.Lock()
+	defer q.Unlock()
+	return nodeJob(q.active, name)
+}
+
+// nodeJob returns the first of the jobs that touches the specified node
+func nodeJob(jobs []*Job, name string) *Job {
+	for _, j := range jobs {
+		for _, n := range j.nodes {
+			if n == name {
+				return j
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// +build unittest
+
+package manager
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type jobQueueSuite struct {
+}
+
+var _ = Suite(&jobQueueSuite{})
+
+func testJob(id uint64, nodes ...string) *Job {
+	j := NewJob("", nil, nil)
+	j.id = id
+	j.nodes = nodes
+	return j
+}
+
+func noopPrepare() error {
+	return nil
+}
+
+func (s *jobQueueSuite) TestConflicts(c *C) {
+	tests := map[string]struct {
+		j1      *Job
+		j2      *Job
+		exptdOk bool
+	}{
+		"disjoint-nodes": {
+			j1:      testJob(1, "foo", "bar"),
+			j2:      testJob(2, "baz"),
+			exptdOk: false,
+		},
+		"common-nodes": {
+			j1:      testJob(1, "foo", "bar"),
+			j2:      testJob(2, "baz", "bar"),
+			exptdOk: true,
+		},
+		"no-nodes": {
+			j1:      testJob(1, "foo"),
+			j2:      testJob(2),
+			exptdOk: true,
+		},
+	}
+
+	for key, test := range tests {
+		c.Assert(conflicts(test.j1, test.j2), Equals, test.exptdOk, Commentf("key: %s", key))
+		c.Assert(conflicts(test.j2, test.j1), Equals, test.exptdOk, Commentf("key: %s", key))
+	}
+}
+
+func (s *jobQueueSuite) TestActivateDisjointJobs(c *C) {
+	q := newJobQueue(5, 2)
+	j1 := testJob(1, "foo")
+	j2 := testJob(2, "bar")
+	j3 := testJob(3, "baz")
+	c.Assert(q.tryActivate(j1), Equals, true)
+	c.Assert(q.tryActivate(j2), Equals, true)
+	// the limit of active jobs is reached
+	c.Assert(q.tryActivate(j3), Equals, false)
+	c.Assert(q.activeJob(), Equals, j1)
+	c.Assert(q.findJob(2), Equals, j2)
+}
+
+func (s *jobQueueSuite) TestActivateConflictingJobs(c *C) {
+	q := newJobQueue(5, 5)
+	c.Assert(q.tryActivate(testJob(1, "foo", "bar")), Equals, true)
+	c.Assert(q.tryActivate(testJob(2, "bar")), Equals, false)
+	c.Assert(q.tryActivate(testJob(3)), Equals, false)
+}
+
+func (s *jobQueueSuite) TestQueueFull(c *C) {
+	q := newJobQueue(1, 1)
+	c.Assert(q.push(testJob(1, "foo"), noopPrepare), IsNil)
+	c.Assert(q.push(testJob(2, "bar"), noopPrepare), ErrorMatches, errJobQueueFull(1).Error())
+}
+
+func (s *jobQueueSuite) TestDispatchOrder(c *C) {
+	q := newJobQueue(5, 5)
+	j1 := testJob(1, "foo")
+	j2 := testJob(2, "foo", "bar")
+	j3 := testJob(3, "bar")
+	j4 := testJob(4, "baz")
+	c.Assert(q.tryActivate(j1), Equals, true)
+	c.Assert(q.push(j2, noopPrepare), IsNil)
+	c.Assert(q.push(j3, noopPrepare), IsNil)
+	c.Assert(q.push(j4, noopPrepare), IsNil)
+
+	// j2 conflicts with active j1 and j3 can't overtake j2, so only j4 is runnable
+	qj := q.popRunnable()
+	c.Assert(qj, NotNil)
+	c.Assert(qj.job, Equals, j4)
+	c.Assert(q.popRunnable(), IsNil)
+
+	// once j1 is done, j2 is runnable but j3 still waits for j2
+	q.done(j1)
+	c.Assert(q.getLastJob(), Equals, j1)
+	qj = q.popRunnable()
+	c.Assert(qj, NotNil)
+	c.Assert(qj.job, Equals, j2)
+	c.Assert(q.popRunnable(), IsNil)
+
+	q.done(j2)
+	qj = q.popRunnable()
+	c.Assert(qj, NotNil)
+	c.Assert(qj.job, Equals, j3)
+}
+
+func (s *jobQueueSuite) TestRemove(c *C) {
+	q := newJobQueue(5, 5)
+	j1 := testJob(1, "foo")
+	c.Assert(q.push(j1, noopPrepare), IsNil)
+	c.Assert(q.findJob(1), Equals, j1)
+	c.Assert(q.remove(1), Equals, j1)
+	c.Assert(q.remove(1), IsNil)
+	c.Assert(q.findJob(1), IsNil)
+	c.Assert(q.popRunnable(), IsNil)
+}
+
+func (s *jobQueueSuite) TestFindNodeJob(c *C) {
+	q := newJobQueue(5, 5)
+	j1 := testJob(1, "foo")
+	j2 := testJob(2, "bar", "baz")
+	c.Assert(q.tryActivate(j1), Equals, true)
+	c.Assert(q.push(j2, noopPrepare), IsNil)
+	c.Assert(q.findNodeJob("foo"), Equals, j1)
+	c.Assert(q.findNodeJob("baz"), Equals, j2)
+	c.Assert(q.findNodeJob("qux"), IsNil)
+}
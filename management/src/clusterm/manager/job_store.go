@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/contiv/errored"
+)
+
+// memJobStore keeps the job history in memory. It is used when no persistent
+// store is configured for the job history, in which case the history is lost
+// when clusterm restarts.
+type memJobStore struct {
+	sync.Mutex
+	lastID uint64
+	jobs   map[uint64][]byte
+}
+
+// newMemJobStore creates and returns an empty in-memory job store
+func newMemJobStore() *memJobStore {
+	return &memJobStore{
+		jobs: map[uint64][]byte{},
+	}
+}
+
+// NextJobID returns a unique ID for a new job
+func (s *memJobStore) NextJobID() (uint64, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.lastID++
+	return s.lastID, nil
+}
+
+// PutJob creates or updates the info of the job with specified ID
+func (s *memJobStore) PutJob(id uint64, info []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.jobs[id] = append([]byte{}, info...)
+	if id > s.lastID {
+		s.lastID = id
+	}
+	return nil
+}
+
+// GetJob returns the info of the job with specified ID
+func (s *memJobStore) GetJob(id uint64) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	info, ok := s.jobs[id]
+	if !ok {
+		return nil, errored.Errorf("No job found for id: %d", id)
+	}
+	return append([]byte{}, info...), nil
+}
+
+// GetAllJobs returns the info of all the jobs in the order of their IDs
+func (s *memJobStore) GetAllJobs() ([][]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	infos := [][]byte{}
+	for _, id := range s.sortedIDs() {
+		infos = append(infos, append([]byte{}, s.jobs[id]...))
+	}
+	return infos, nil
+}
+
+// PruneJobs deletes the oldest jobs such that atmost maxJobs jobs are retained
+func (s *memJobStore) PruneJobs(maxJobs int) error {
+	s.Lock()
+	defer s.Unlock()
+	ids := s.sortedIDs()
+	if len(ids) <= maxJobs {
+		return nil
+	}
+	for _, id := range ids[:len(ids)-maxJobs] {
+		delete(s.jobs, id)
+	}
+	return nil
+}
+
+// sortedIDs returns the IDs of the jobs in ascending order. Caller shall hold the lock.
+func (s *memJobStore) sortedIDs() []uint64 {
+	ids := []uint64{}
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Sort(uint64Slice(ids))
+	return ids
+}
+
+type uint64Slice []uint64
+
+func (p uint64Slice) Len() int           { return len(p) }
+func (p uint64Slice) Less(i, j int) bool { return p[i] < p[j] }
+func (p uint64Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
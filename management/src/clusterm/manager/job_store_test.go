@@ -0,0 +1,36 @@
+// +build unittest
+
+package manager
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type jobStoreSuite struct {
+}
+
+var _ = Suite(&jobStoreSuite{})
+
+func (s *jobStoreSuite) TestMemJobStore(c *C) {
+	store := newMemJobStore()
+	for i := 0; i < 3; i++ {
+		id, err := store.NextJobID()
+		c.Assert(err, IsNil)
+		c.Assert(id, Equals, uint64(i+1))
+		c.Assert(store.PutJob(id, []byte{byte('a' + i)}), IsNil)
+	}
+	info, err := store.GetJob(2)
+	c.Assert(err, IsNil)
+	c.Assert(info, DeepEquals, []byte("b"))
+
+	// the oldest jobs are pruned and the job ids are not reused
+	c.Assert(store.PruneJobs(2), IsNil)
+	infos, err := store.GetAllJobs()
+	c.Assert(err, IsNil)
+	c.Assert(infos, DeepEquals, [][]byte{[]byte("b"), []byte("c")})
+	_, err = store.GetJob(1)
+	c.Assert(err, ErrorMatches, "No job found for id: 1")
+	id, err := store.NextJobID()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, uint64(4))
+}
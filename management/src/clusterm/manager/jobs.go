@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/contiv/errored"
 )
@@ -26,22 +27,52 @@ type DoneCallback func(status JobStatus, errVal error)
 // Job corresponds to a long running task, triggered by an event
 type Job struct {
 	sync.Mutex
-	runner   JobRunner
-	done     DoneCallback
-	cancelCh CancelChannel
-	status   JobStatus
-	errVal   error
-	logs     bytes.Buffer
-	desc     string
+	id        uint64
+	nodes     []string
+	runner    JobRunner
+	done      DoneCallback
+	cancelCh  CancelChannel
+	status    JobStatus
+	errVal    error
+	logs      bytes.Buffer
+	desc      string
+	startTime time.Time
+	endTime   time.Time
+}
+
+// jobInfo is the json representation of a job
+type jobInfo struct {
+	ID        uint64     `json:"id"`
+	Desc      string     `json:"desc"`
+	Task      string     `json:"task"`
+	Nodes     []string   `json:"nodes"`
+	Status    string     `json:"status"`
+	ErrVal    string     `json:"error"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Logs      []string   `json:"logs,omitempty"`
+}
+
+// jobLogWriter serializes the writes to a job's logs with the reads
+type jobLogWriter struct {
+	j *Job
+}
+
+func (w *jobLogWriter) Write(p []byte) (int, error) {
+	w.j.Lock()
+	defer w.j.Unlock()
+	return w.j.logs.Write(p)
 }
 
 // NewJob initializes and returns an instance of a job described by the runner and done callback
 func NewJob(desc string, jr JobRunner, done DoneCallback) *Job {
+	// the cancel channel is buffered so that cancel doesn't block on a runner
+	// that is not waiting for the signal at the moment
 	return &Job{
 		runner:   jr,
 		done:     done,
 		desc:     desc,
-		cancelCh: make(chan struct{}),
+		cancelCh: make(chan struct{}, 1),
 		status:   Queued,
 		errVal:   nil,
 	}
@@ -63,20 +94,54 @@ func (j *Job) setStatus(status JobStatus, err error) {
 	j.Unlock()
 }
 
-// Run begins the job and wait for completion. This function blocks
+// abort marks a job that could not be run as errored. The done callback
+// is not called as the job never ran.
+func (j *Job) abort(err error) {
+	j.Lock()
+	j.status = Errored
+	j.errVal = err
+	j.endTime = time.Now()
+	j.Unlock()
+}
+
+// ID returns the unique ID of the job
+func (j *Job) ID() uint64 {
+	return j.id
+}
+
+// Run begins the job and wait for completion, followed by the done callback.
+// This function blocks
 func (j *Job) Run() {
+	j.run()
+	j.finish()
+}
+
+// run begins the job and wait for completion, without calling the done callback.
+// This function blocks
+func (j *Job) run() {
+	j.Lock()
+	j.startTime = time.Now()
+	j.Unlock()
 	j.setStatus(Running, nil)
 	defer func() {
-		j.done(j.status, j.errVal)
+		j.Lock()
+		j.endTime = time.Now()
+		j.Unlock()
 	}()
 
-	if err := j.runner(j.cancelCh, &j.logs); err != nil {
+	if err := j.runner(j.cancelCh, &jobLogWriter{j: j}); err != nil {
 		j.setStatus(Errored, err)
 		return
 	}
 	j.setStatus(Complete, nil)
 }
 
+// finish calls the done callback with the outcome of the job
+func (j *Job) finish() {
+	status, err := j.Status()
+	j.done(status, err)
+}
+
 //Cancel signals canceling a running job
 func (j *Job) Cancel() error {
 	// if job is running then run it's cancel function
@@ -84,7 +149,11 @@ func (j *Job) Cancel() error {
 	j.Lock()
 	defer j.Unlock()
 	if j.status == Running {
-		j.cancelCh <- struct{}{}
+		select {
+		case j.cancelCh <- struct{}{}:
+		default:
+			// a cancellation is already pending
+		}
 		return nil
 	}
 	return errored.Errorf("job is not Running")
@@ -92,34 +161,63 @@ func (j *Job) Cancel() error {
 
 // Status returns the status of a job at the time of call
 func (j *Job) Status() (JobStatus, error) {
+	j.Lock()
+	defer j.Unlock()
 	return j.status, j.errVal
 }
 
+// isDone returns true if the job has finished
+func (j *Job) isDone() bool {
+	status, _ := j.Status()
+	return status == Complete || status == Errored
+}
+
 // Logs returns the current logs associated with the job.
 func (j *Job) Logs() io.Reader {
 	// instead of returning the buffer itself we instead need to return
 	// a reader created over current contents of the buffer without changing
 	// it's read offset. This will allow accessing logs over and over again.
-	return bytes.NewReader(j.logs.Bytes())
+	return bytes.NewReader(j.LogsFrom(0))
+}
+
+// LogsFrom returns a copy of the current logs associated with the job, starting
+// at the specified byte offset. It returns an empty slice if there are no logs
+// beyond the offset.
+func (j *Job) LogsFrom(offset int) []byte {
+	j.Lock()
+	defer j.Unlock()
+	logs := j.logs.Bytes()
+	if offset < 0 || offset >= len(logs) {
+		return []byte{}
+	}
+	out := make([]byte, len(logs)-offset)
+	copy(out, logs[offset:])
+	return out
 }
 
 // MarshalJSON marshals and returns the JSON for job info
 func (j *Job) MarshalJSON() ([]byte, error) {
-	toJSON := struct {
-		Desc   string   `json:"desc"`
-		Task   string   `json:"task"`
-		Status string   `json:"status"`
-		ErrVal string   `json:"error"`
-		Logs   []string `json:"logs"`
-	}{
+	j.Lock()
+	defer j.Unlock()
+	toJSON := jobInfo{
+		ID:     j.id,
 		Desc:   j.desc,
 		Task:   j.runnerName(),
+		Nodes:  j.nodes,
 		Status: j.status.String(),
 		Logs:   strings.Split(j.logs.String(), "\n"),
 	}
 	if j.errVal != nil {
 		toJSON.ErrVal = fmt.Sprintf("%v", j.errVal)
 	}
+	if !j.startTime.IsZero() {
+		startTime := j.startTime
+		toJSON.StartTime = &startTime
+	}
+	if !j.endTime.IsZero() {
+		endTime := j.endTime
+		toJSON.EndTime = &endTime
+	}
 
 	return json.Marshal(toJSON)
 }
@@ -154,6 +154,29 @@ func (s *jobsSuite) TestJobRunCancel(c *C) {
 	checkDoneCb(c, cbCh)
 }
 
+func (s *jobsSuite) TestJobCancelNotRunning(c *C) {
+	j := NewJob("", runner(&sync.WaitGroup{}, 0, nil), func(status JobStatus, errRet error) {})
+	c.Assert(j.Cancel(), ErrorMatches, "job is not Running")
+}
+
+func (s *jobsSuite) TestJobCancelNoWait(c *C) {
+	wg := &sync.WaitGroup{}
+	cbCh := make(chan struct{}, 1)
+	// runner that doesn't wait on cancel channel
+	j := NewJob("", runner(wg, 2*time.Second, nil), expectDoneCb(c, cbCh, Complete, nil))
+	wg.Add(1)
+	go j.Run()
+	// give some time for job to start
+	time.Sleep(500 * time.Millisecond)
+	// cancel shall not block even if the runner is not waiting for the signal
+	c.Assert(j.Cancel(), IsNil)
+	c.Assert(j.Cancel(), IsNil)
+
+	waitAndCheckJobStatus(c, wg, j, Complete, nil)
+
+	checkDoneCb(c, cbCh)
+}
+
 func (s *jobsSuite) TestJobLogs(c *C) {
 	wg := &sync.WaitGroup{}
 	cbCh := make(chan struct{}, 1)
@@ -217,11 +240,16 @@ func (s *jobsSuite) TestJobInfoMarshal(c *C) {
 	`
 	exptdDesc := "testJob"
 	exptdErr := errored.Errorf("testError")
+	exptdNodes := []string{"foo", "bar"}
+	exptdStartTime := time.Now()
 	j := &Job{
-		desc:   exptdDesc,
-		status: Running,
-		errVal: exptdErr,
-		logs:   *bytes.NewBuffer([]byte(exptdLogStr)),
+		id:        10,
+		nodes:     exptdNodes,
+		desc:      exptdDesc,
+		status:    Running,
+		errVal:    exptdErr,
+		logs:      *bytes.NewBuffer([]byte(exptdLogStr)),
+		startTime: exptdStartTime,
 	}
 
 	out, err := j.MarshalJSON()
@@ -229,13 +257,21 @@ func (s *jobsSuite) TestJobInfoMarshal(c *C) {
 
 	// verify the relevant fields
 	exptdInfo := struct {
-		Desc   string   `json:"desc"`
-		Status string   `json:"status"`
-		ErrVal string   `json:"error"`
-		Logs   []string `json:"logs"`
+		ID        uint64     `json:"id"`
+		Desc      string     `json:"desc"`
+		Nodes     []string   `json:"nodes"`
+		Status    string     `json:"status"`
+		ErrVal    string     `json:"error"`
+		StartTime *time.Time `json:"start_time"`
+		EndTime   *time.Time `json:"end_time"`
+		Logs      []string   `json:"logs"`
 	}{}
 	err = json.Unmarshal(out, &exptdInfo)
 	c.Assert(err, IsNil)
+	c.Assert(exptdInfo.ID, Equals, uint64(10))
+	c.Assert(exptdInfo.Nodes, DeepEquals, exptdNodes)
+	c.Assert(exptdInfo.StartTime.Equal(exptdStartTime), Equals, true)
+	c.Assert(exptdInfo.EndTime, IsNil)
 	c.Assert(exptdInfo.Desc, Equals, exptdDesc)
 	c.Assert(exptdInfo.Status, Equals, Running.String())
 	c.Assert(exptdInfo.ErrVal, Equals, fmt.Sprintf("%v", exptdErr))
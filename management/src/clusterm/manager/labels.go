@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/contiv/errored"
+)
+
+// labelRegexp is the format of a label's key or value. It is kept restrictive
+// so that the labels can be specified in a selector string like 'zone=a,rack=r1'
+var labelRegexp = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+// validateLabels checks the keys and values of the labels to be set on a node.
+// An empty value denotes removal of the label.
+func validateLabels(labels map[string]string) error {
+	if len(labels) == 0 {
+		return errored.Errorf("atleast one label should be specified")
+	}
+	for k, v := range labels {
+		if k == labelKeyRole {
+			return errored.Errorf("%q is a reserved label, use the host-group to change a node's role", k)
+		}
+		if !labelRegexp.MatchString(k) {
+			return errored.Errorf("invalid label key %q, it shall match %q", k, labelRegexp)
+		}
+		if v != "" && !labelRegexp.MatchString(v) {
+			return errored.Errorf("invalid value %q for label %q, it shall match %q", v, k, labelRegexp)
+		}
+	}
+	return nil
+}
+
+// parseSelector parses a selector of the form 'key1=value1,key2=value2' and
+// returns the labels that a node shall have to be selected
+func parseSelector(selector string) (map[string]string, error) {
+	sel := map[string]string{}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 || !labelRegexp.MatchString(kv[0]) || !labelRegexp.MatchString(kv[1]) {
+			return nil, errored.Errorf("invalid selector term %q, it shall be of the form 'key=value'", term)
+		}
+		sel[kv[0]] = kv[1]
+	}
+	return sel, nil
+}
+
+// matchesSelector returns true if the node has all the labels in the selector.
+// Besides the user defined labels, a node has the built-in 'role' label set to
+// it's host-group.
+func (n *node) matchesSelector(sel map[string]string) bool {
+	for k, v := range sel {
+		if k == labelKeyRole {
+			if n.group() != v {
+				return false
+			}
+			continue
+		}
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabels returns the labels as a sorted 'key1=value1,key2=value2' string
+func formatLabels(labels map[string]string) string {
+	kvs := []string{}
+	for k, v := range labels {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
+// matchingNodes returns the sorted names of the nodes that match the selector
+func (m *Manager) matchingNodes(sel map[string]string) []string {
+	names := []string{}
+	for name, n := range m.nodes {
+		if n.matchesSelector(sel) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectNodes returns the sorted names of the nodes that match the selector.
+// It is an error if no node matches the selector.
+func (m *Manager) selectNodes(selector string) ([]string, error) {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	names := m.matchingNodes(sel)
+	if len(names) == 0 {
+		return nil, errored.Errorf("no nodes match the selector %q", selector)
+	}
+	return names, nil
+}
+
+// resolveSelector sets the nodes of the request to the ones that match the
+// request's selector, if one is specified
+func (m *Manager) resolveSelector(req *APIRequest) error {
+	if req.Selector == "" {
+		return nil
+	}
+	if len(req.Nodes) > 0 {
+		return errored.Errorf("either the nodes or a selector shall be specified, not both")
+	}
+	e := newSelectNodesEvent(m, req.Selector)
+	me := newWaitableEvent(e)
+	m.reqQ <- me
+	if err := me.waitForCompletion(); err != nil {
+		return err
+	}
+	req.Nodes = e._nodes
+	return nil
+}
+
+// saveNodeLabels persists the specified labels of a node in the inventory, so
+// that they can be restored across clusterm restarts
+func (m *Manager) saveNodeLabels(name string, nodeLabels map[string]string) error {
+	labels, err := json.Marshal(nodeLabels)
+	if err != nil {
+		return errored.Errorf("failed to marshal labels of node %q. Error: %v", name, err)
+	}
+	return m.inventory.SetAssetAttribute(name, nodeLabelsAttr, string(labels))
+}
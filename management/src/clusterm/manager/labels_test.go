@@ -0,0 +1,141 @@
+// +build unittest
+
+package manager
+
+import (
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/errored"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type labelsSuite struct {
+}
+
+var _ = Suite(&labelsSuite{})
+
+func (s *labelsSuite) TestValidateLabels(c *C) {
+	tests := map[string]struct {
+		labels   map[string]string
+		exptdErr string
+	}{
+		"no-labels": {
+			labels:   map[string]string{},
+			exptdErr: "atleast one label should be specified",
+		},
+		"reserved-key": {
+			labels:   map[string]string{"role": "foo"},
+			exptdErr: "\"role\" is a reserved label.*",
+		},
+		"invalid-key": {
+			labels:   map[string]string{"zone a": "foo"},
+			exptdErr: "invalid label key \"zone a\".*",
+		},
+		"invalid-value": {
+			labels:   map[string]string{"zone": "a,b"},
+			exptdErr: "invalid value \"a,b\" for label \"zone\".*",
+		},
+	}
+	for key, test := range tests {
+		c.Assert(validateLabels(test.labels), ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+
+	// an empty value removes the label
+	c.Assert(validateLabels(map[string]string{"zone": "a", "rack": ""}), IsNil)
+}
+
+func (s *labelsSuite) TestSelectNodes(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Discovered},
+		"n2": {inventory.Allocated, inventory.Discovered},
+		"n3": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.nodes["n1"].Labels = map[string]string{"zone": "a"}
+	mgr.nodes["n2"].Labels = map[string]string{"zone": "a", "rack": "r1"}
+	mgr.nodes["n2"].Cfg = configuration.NewAnsibleHost("n2", "", ansibleWorkerGroupName, nil)
+
+	tests := map[string]struct {
+		selector   string
+		exptdNodes []string
+		exptdErr   string
+	}{
+		"one-label": {
+			selector:   "zone=a",
+			exptdNodes: []string{"n1", "n2"},
+		},
+		"many-labels": {
+			selector:   "zone=a, rack=r1",
+			exptdNodes: []string{"n2"},
+		},
+		"role": {
+			selector:   "zone=a,role=" + ansibleMasterGroupName,
+			exptdNodes: []string{"n1"},
+		},
+		"no-match": {
+			selector: "zone=b",
+			exptdErr: "no nodes match the selector \"zone=b\"",
+		},
+		"invalid-term": {
+			selector: "zone=a,rack",
+			exptdErr: "invalid selector term \"rack\".*",
+		},
+	}
+	for key, test := range tests {
+		nodes, err := mgr.selectNodes(test.selector)
+		if test.exptdErr != "" {
+			c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("test: %s", key))
+		c.Assert(nodes, DeepEquals, test.exptdNodes, Commentf("test: %s", key))
+	}
+}
+
+func (s *labelsSuite) TestSetLabels(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.nodes["n1"].Labels = map[string]string{"zone": "a", "rack": "r1"}
+
+	// none of the nodes are labelled, if any of them doesn't exist
+	err := newSetLabelsEvent(mgr, []string{"n1", "n2"}, map[string]string{"zone": "b"}).process()
+	c.Assert(err, ErrorMatches, "node with name or address \"n2\" doesn't exists")
+	c.Assert(mgr.nodes["n1"].Labels["zone"], Equals, "a")
+
+	mClient.EXPECT().SetAssetAttribute("n1", nodeLabelsAttr, `{"owner":"ops","zone":"b"}`)
+	mClient.EXPECT().AddAssetLog("n1", inventory.LogTypeInfo, "node labels set to: owner=ops,zone=b")
+	err = newSetLabelsEvent(mgr, []string{"n1"}, map[string]string{"zone": "b", "owner": "ops", "rack": ""}).process()
+	c.Assert(err, IsNil)
+	c.Assert(mgr.nodes["n1"].Labels, DeepEquals, map[string]string{"zone": "b", "owner": "ops"})
+}
+
+func (s *labelsSuite) TestSetLabelsSaveFailure(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Discovered},
+		"n2": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.nodes["n1"].Labels = map[string]string{"zone": "a"}
+	mgr.nodes["n2"].Labels = map[string]string{"zone": "a"}
+
+	// the labels saved for the earlier nodes are reverted, if saving fails for
+	// a node, and none of the nodes' labels are changed
+	gomock.InOrder(
+		mClient.EXPECT().SetAssetAttribute("n1", nodeLabelsAttr, `{"zone":"b"}`),
+		mClient.EXPECT().SetAssetAttribute("n2", nodeLabelsAttr, `{"zone":"b"}`).Return(errored.Errorf("test failure")),
+		mClient.EXPECT().SetAssetAttribute("n1", nodeLabelsAttr, `{"zone":"a"}`),
+	)
+	err := newSetLabelsEvent(mgr, []string{"n1", "n2"}, map[string]string{"zone": "b"}).process()
+	c.Assert(err, ErrorMatches, "failed to save labels of node \"n2\".*test failure")
+	c.Assert(mgr.nodes["n1"].Labels, DeepEquals, map[string]string{"zone": "a"})
+	c.Assert(mgr.nodes["n2"].Labels, DeepEquals, map[string]string{"zone": "a"})
+}
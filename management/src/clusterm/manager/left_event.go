@@ -0,0 +1,33 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/contiv/cluster/management/src/monitor"
+)
+
+// leftEvent processes the event of a node leaving the monitoring subsystem
+// gracefully. The node is not reachable anymore, so it is handled like a
+// disappeared node.
+type leftEvent struct {
+	mgr   *Manager
+	nodes []monitor.SubsysNode
+}
+
+// newLeftEvent creates and returns leftEvent event
+func newLeftEvent(mgr *Manager, nodes []monitor.SubsysNode) *leftEvent {
+	return &leftEvent{
+		mgr:   mgr,
+		nodes: nodes,
+	}
+}
+
+func (e *leftEvent) String() string {
+	return fmt.Sprintf("leftEvent: nodes: %s", monitorNodesString(e.nodes))
+}
+
+func (e *leftEvent) process() error {
+	return processMonitorNodes(e.nodes, func(n monitor.SubsysNode) error {
+		return e.mgr.setNodeDisappeared(n, "node left the monitoring subsystem gracefully")
+	})
+}
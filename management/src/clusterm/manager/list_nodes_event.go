@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// nodeListing is the json representation of a node in a listing of nodes.
+// Along with the node's info it contains the last job that touched the node.
+type nodeListing struct {
+	node
+	LastJob *nodeJobInfo `json:"last_job,omitempty"`
+}
+
+// nodeJobInfo is the summary of a job that touched a node
+type nodeJobInfo struct {
+	ID     uint64 `json:"id"`
+	Status string `json:"status"`
+}
+
+// listNodesEvent lists the nodes that match a query. The nodes are looked up
+// in the event loop so that the node table is not read while it is being updated.
+type listNodesEvent struct {
+	mgr   *Manager
+	query NodesQuery
+
+	_out []byte
+}
+
+// newListNodesEvent creates and returns listNodesEvent
+func newListNodesEvent(mgr *Manager, query NodesQuery) *listNodesEvent {
+	return &listNodesEvent{
+		mgr:   mgr,
+		query: query,
+	}
+}
+
+func (e *listNodesEvent) String() string {
+	return fmt.Sprintf("listNodesEvent: query: %+v", e.query)
+}
+
+func (e *listNodesEvent) process() error {
+	if err := e.query.validate(e.mgr.getRoles()); err != nil {
+		return err
+	}
+
+	sel := map[string]string{}
+	if e.query.Selector != "" {
+		var err error
+		if sel, err = parseSelector(e.query.Selector); err != nil {
+			return err
+		}
+	}
+
+	lastJobs, err := e.mgr.lastNodeJobs()
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, name := range e.mgr.matchingNodes(sel) {
+		if e.query.matches(name, e.mgr.nodes[name]) {
+			names = append(names, name)
+		}
+	}
+
+	// the nodes are listed in a map keyed by their name, unless a sort order is requested
+	if e.query.Sort == "" {
+		nodes := map[string]nodeListing{}
+		for _, name := range names {
+			nodes[name] = nodeListing{node: *e.mgr.nodes[name], LastJob: lastJobs[name]}
+		}
+		e._out, err = json.Marshal(nodes)
+		return err
+	}
+
+	// the names are already sorted, so the nodes with same key stay sorted by name
+	sort.Stable(&nodesByKey{names: names, nodes: e.mgr.nodes, key: nodeSortKeys[e.query.Sort]})
+	nodes := []nodeListing{}
+	for _, name := range names {
+		nodes = append(nodes, nodeListing{node: *e.mgr.nodes[name], LastJob: lastJobs[name]})
+	}
+	e._out, err = json.Marshal(nodes)
+	return err
+}
+
+// nodesByKey sorts the node names by the value of a node's field
+type nodesByKey struct {
+	names []string
+	nodes map[string]*node
+	key   func(name string, n *node) string
+}
+
+func (s *nodesByKey) Len() int      { return len(s.names) }
+func (s *nodesByKey) Swap(i, j int) { s.names[i], s.names[j] = s.names[j], s.names[i] }
+func (s *nodesByKey) Less(i, j int) bool {
+	return s.key(s.names[i], s.nodes[s.names[i]]) < s.key(s.names[j], s.nodes[s.names[j]])
+}
+
+// lastNodeJobs returns the last job in the job history that touched each node
+func (m *Manager) lastNodeJobs() (map[string]*nodeJobInfo, error) {
+	infos, err := m.jobStore.GetAllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	// the jobs are in the order of their IDs, so the later jobs take precedence
+	lastJobs := map[string]*nodeJobInfo{}
+	for _, info := range infos {
+		j := jobInfo{}
+		if err := json.Unmarshal(info, &j); err != nil {
+			return nil, err
+		}
+		for _, name := range j.Nodes {
+			lastJobs[name] = &nodeJobInfo{ID: j.ID, Status: j.Status}
+		}
+	}
+	return lastJobs, nil
+}
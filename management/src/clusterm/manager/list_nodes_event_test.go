@@ -0,0 +1,142 @@
+// +build unittest
+
+package manager
+
+import (
+	"encoding/json"
+
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	. "gopkg.in/check.v1"
+)
+
+type listNodesSuite struct {
+}
+
+var _ = Suite(&listNodesSuite{})
+
+// testListManager returns a manager with a few nodes in different status
+// and host-groups, and a job history
+func testListManager(c *C) *Manager {
+	mgr := testRecoveryManager(c, nil, map[string]assetStatus{
+		"node-1": {inventory.Allocated, inventory.Discovered},
+		"node-2": {inventory.Unallocated, inventory.Disappeared},
+		"node-3": {inventory.Allocated, inventory.Disappeared},
+		"host-1": {inventory.Decommissioned, inventory.Discovered},
+	}, []jobInfo{
+		{ID: 1, Nodes: []string{"node-1", "node-3"}, Status: Complete.String()},
+		{ID: 2, Nodes: []string{"node-3"}, Status: Errored.String()},
+	}, "")
+	mgr.nodes["node-3"].Cfg = configuration.NewAnsibleHost("node-3", "", ansibleWorkerGroupName, nil)
+	mgr.nodes["node-3"].Labels = map[string]string{"zone": "a"}
+	return mgr
+}
+
+func (s *listNodesSuite) TestListNodesFilters(c *C) {
+	mgr := testListManager(c)
+
+	tests := map[string]struct {
+		query      NodesQuery
+		exptdNodes []string
+	}{
+		"no-filter": {
+			query:      NodesQuery{},
+			exptdNodes: []string{"host-1", "node-1", "node-2", "node-3"},
+		},
+		"status": {
+			query:      NodesQuery{Status: "allocated"},
+			exptdNodes: []string{"node-1", "node-3"},
+		},
+		"status-and-state": {
+			query:      NodesQuery{Status: "Allocated", State: "Disappeared"},
+			exptdNodes: []string{"node-3"},
+		},
+		"host-group": {
+			query:      NodesQuery{HostGroup: ansibleMasterGroupName},
+			exptdNodes: []string{"host-1", "node-1", "node-2"},
+		},
+		"name": {
+			query:      NodesQuery{Name: "node-*", State: "Discovered"},
+			exptdNodes: []string{"node-1"},
+		},
+		"selector": {
+			query:      NodesQuery{Selector: "zone=a"},
+			exptdNodes: []string{"node-3"},
+		},
+		"no-match": {
+			query:      NodesQuery{Name: "foo*"},
+			exptdNodes: []string{},
+		},
+	}
+	for key, test := range tests {
+		e := newListNodesEvent(mgr, test.query)
+		c.Assert(e.process(), IsNil, Commentf("test: %s", key))
+		nodes := map[string]interface{}{}
+		c.Assert(json.Unmarshal(e._out, &nodes), IsNil, Commentf("test: %s", key))
+		names := []string{}
+		for name := range nodes {
+			names = append(names, name)
+		}
+		c.Assert(names, HasLen, len(test.exptdNodes), Commentf("test: %s", key))
+		for _, name := range test.exptdNodes {
+			c.Assert(nodes[name], NotNil, Commentf("test: %s, node: %s", key, name))
+		}
+	}
+}
+
+func (s *listNodesSuite) TestListNodesSorted(c *C) {
+	mgr := testListManager(c)
+
+	// the nodes with the same status stay sorted by name
+	e := newListNodesEvent(mgr, NodesQuery{Sort: "status"})
+	c.Assert(e.process(), IsNil)
+	nodes := []struct {
+		Inv     map[string]interface{} `json:"inventory_state"`
+		LastJob *nodeJobInfo           `json:"last_job"`
+	}{}
+	c.Assert(json.Unmarshal(e._out, &nodes), IsNil)
+	names := []string{}
+	for _, n := range nodes {
+		names = append(names, n.Inv["name"].(string))
+	}
+	c.Assert(names, DeepEquals, []string{"node-1", "node-3", "host-1", "node-2"})
+
+	// the last job that touched the node is listed
+	c.Assert(nodes[0].LastJob, DeepEquals, &nodeJobInfo{ID: 1, Status: Complete.String()})
+	c.Assert(nodes[1].LastJob, DeepEquals, &nodeJobInfo{ID: 2, Status: Errored.String()})
+	c.Assert(nodes[2].LastJob, IsNil)
+}
+
+func (s *listNodesSuite) TestListNodesInvalidQuery(c *C) {
+	mgr := testListManager(c)
+
+	tests := map[string]struct {
+		query    NodesQuery
+		exptdErr string
+	}{
+		"status": {
+			query:    NodesQuery{Status: "Commissioned"},
+			exptdErr: "invalid status specified in the query: \"Commissioned\"",
+		},
+		"state": {
+			query:    NodesQuery{State: "Gone"},
+			exptdErr: "invalid state specified in the query: \"Gone\"",
+		},
+		"host-group": {
+			query:    NodesQuery{HostGroup: ansibleDiscoverGroupName},
+			exptdErr: "invalid host-group specified in the query.*",
+		},
+		"name": {
+			query:    NodesQuery{Name: "node-["},
+			exptdErr: "invalid name pattern specified in the query.*",
+		},
+		"sort": {
+			query:    NodesQuery{Sort: "serial"},
+			exptdErr: "invalid sort field specified in the query: \"serial\"",
+		},
+	}
+	for key, test := range tests {
+		err := newListNodesEvent(mgr, test.query).process()
+		c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+}
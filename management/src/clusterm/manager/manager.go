@@ -12,6 +12,10 @@
 package manager
 
 import (
+	"text/template"
+	"time"
+
+	"github.com/Sirupsen/logrus"
 	"github.com/contiv/cluster/management/src/boltdb"
 	"github.com/contiv/cluster/management/src/configuration"
 	"github.com/contiv/cluster/management/src/inventory"
@@ -24,9 +28,24 @@ import (
 // node is an aggregate structure that contains information about a cluster
 // node as seen by cluster management subsystems.
 type node struct {
-	Mon monitor.SubsysNode       `json:"monitoring_state"`
-	Inv inventory.SubsysAsset    `json:"inventory_state"`
-	Cfg configuration.SubsysHost `json:"configuration_state"`
+	Mon    monitor.SubsysNode       `json:"monitoring_state"`
+	Inv    inventory.SubsysAsset    `json:"inventory_state"`
+	Cfg    configuration.SubsysHost `json:"configuration_state"`
+	Labels map[string]string        `json:"labels,omitempty"`
+}
+
+// jobStore provides the persistent storage for the job history
+type jobStore interface {
+	// NextJobID returns a unique ID for a new job
+	NextJobID() (uint64, error)
+	// PutJob creates or updates the info of the job with specified ID
+	PutJob(id uint64, info []byte) error
+	// GetJob returns the info of the job with specified ID
+	GetJob(id uint64) ([]byte, error)
+	// GetAllJobs returns the info of all the jobs in the order of their IDs
+	GetAllJobs() ([][]byte, error)
+	// PruneJobs deletes the oldest jobs such that atmost maxJobs jobs are retained
+	PruneJobs(maxJobs int) error
 }
 
 // Manager integrates the cluster infra services like node discovery, inventory
@@ -35,13 +54,34 @@ type Manager struct {
 	inventory     inventory.Subsys
 	configuration configuration.Subsys
 	monitor       monitor.Subsys
+	jobStore      jobStore
 	reqQ          chan event
 	addr          string
 	nodes         map[string]*node
-	activeJob     *Job // there can be only one active job at a time
-	lastJob       *Job
+	jobs          *jobQueue
 	config        *Config
 	configFile    string // file containing clusterm config, when clusterm is started with a config file
+	// reappearHoldoff and reappearedAt rate limit the jobs triggered on node reappearance
+	reappearHoldoff time.Duration
+	reappearedAt    map[string]time.Time
+	// disappearPolicies and disappeared track the nodes that stay disappeared
+	disappearPolicies map[string]disappearPolicy
+	disappeared       map[string]*disappearance
+	// monitorBatcher coalesces the monitor events into batches
+	monitorBatcher *monitorBatcher
+	// nodeNameTmpl forms the names of the discovered nodes and quarantined
+	// tracks the nodes whose identity is missing or conflicting
+	nodeNameTmpl *template.Template
+	quarantined  map[string]*quarantinedNode
+	// flapPolicy and timelines track the monitoring state transitions of the
+	// nodes, to detect the flapping nodes
+	flapPolicy flapPolicy
+	timelines  map[string]*nodeTimeline
+	// deferredUpdates are the monitoring info of the nodes whose management
+	// address changed while they were held by an active job
+	deferredUpdates map[string]monitor.SubsysNode
+	// roles are the roles that the nodes can be commissioned into
+	roles roles
 }
 
 // NewManager initializes and returns an instance of the Manager. It returns nil
@@ -58,31 +98,119 @@ func NewManager(config *Config, configFile string) (*Manager, error) {
 		return nil, err
 	}
 
+	roles, err := config.Manager.roles()
+	if err != nil {
+		return nil, err
+	}
+
+	reappearHoldoff, err := config.Manager.reappearHoldoff()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validateReappearPolicy(); err != nil {
+		return nil, err
+	}
+
+	disappearPolicies, err := config.Manager.disappearPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Manager.validateRecoveryPolicy(); err != nil {
+		return nil, err
+	}
+
+	monitorEventWindow, err := config.Manager.monitorEventWindow()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeNameTmpl, err := config.Manager.nodeNameTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	flapPolicy, err := config.Manager.flapPolicy()
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Manager{
-		monitor:       monitor.NewSerfSubsys(&config.Serf),
-		configuration: configuration.NewAnsibleSubsys(&config.Ansible),
-		reqQ:          make(chan event, 100),
-		addr:          config.Manager.Addr,
-		nodes:         make(map[string]*node),
-		config:        config,
-		configFile:    configFile,
-	}
-	// We give priority to boltdb inventory if both are set in config
-	if config.Inventory.BoltDB != nil {
-		if m.inventory, err = boltdbinv.NewBoltdbSubsys(*config.Inventory.BoltDB); err != nil {
+		configuration:     configuration.NewAnsibleSubsys(&config.Ansible),
+		reqQ:              make(chan event, 100),
+		addr:              config.Manager.Addr,
+		nodes:             make(map[string]*node),
+		jobs:              newJobQueue(config.Manager.MaxQueuedJobs, config.Manager.MaxActiveJobs),
+		config:            config,
+		configFile:        configFile,
+		reappearHoldoff:   reappearHoldoff,
+		reappearedAt:      make(map[string]time.Time),
+		disappearPolicies: disappearPolicies,
+		disappeared:       make(map[string]*disappearance),
+		nodeNameTmpl:      nodeNameTmpl,
+		quarantined:       make(map[string]*quarantinedNode),
+		flapPolicy:        flapPolicy,
+		timelines:         make(map[string]*nodeTimeline),
+		deferredUpdates:   make(map[string]monitor.SubsysNode),
+		roles:             roles,
+	}
+	m.monitorBatcher = newMonitorBatcher(monitorEventWindow, m.enqueueMonitorBatch)
+	switch {
+	case config.Monitor.Probe != nil && config.Monitor.Gossip != nil:
+		return nil, errored.Errorf("only one of probe or gossip monitor can be configured")
+	case config.Monitor.Probe != nil:
+		if m.monitor, err = monitor.NewProbeSubsys(config.Monitor.Probe); err != nil {
 			return nil, err
 		}
-	} else if config.Inventory.Collins != nil {
+	case config.Monitor.Gossip != nil:
+		if m.monitor, err = monitor.NewGossipSubsys(config.Monitor.Gossip); err != nil {
+			return nil, err
+		}
+	default:
+		m.monitor = monitor.NewSerfSubsys(&config.Serf)
+	}
+
+	// We give priority to boltdb inventory if both are set in config. The job
+	// history is persisted in the boltdb inventory, while with collins inventory
+	// it is persisted only if a boltdb is configured for it.
+	if config.Inventory.BoltDB == nil && config.Inventory.Collins != nil {
 		if m.inventory, err = collinsinv.NewCollinsSubsys(*config.Inventory.Collins); err != nil {
 			return nil, err
 		}
+		if config.Manager.JobStore != nil {
+			if m.jobStore, err = boltdb.NewClientFromConfig(*config.Manager.JobStore); err != nil {
+				return nil, err
+			}
+		} else {
+			logrus.Warnf("no job store is configured, the job history won't be retained across restarts")
+			m.jobStore = newMemJobStore()
+		}
 	} else {
-		// if no inventory config was provided then we default to boltDb
-		if m.inventory, err = boltdbinv.NewBoltdbSubsys(boltdb.DefaultConfig()); err != nil {
+		// if no boltdb config was provided then we default to default boltdb config
+		dbConfig := boltdb.DefaultConfig()
+		if config.Inventory.BoltDB != nil {
+			dbConfig = *config.Inventory.BoltDB
+		}
+		dbClient, err := boltdb.NewClientFromConfig(dbConfig)
+		if err != nil {
 			return nil, err
 		}
+		m.jobStore = dbClient
+		if m.inventory, err = boltdbinv.NewBoltdbSubsysFromClient(dbClient); err != nil {
+			return nil, err
+		}
+	}
+
+	// restore the nodes, so that their configuration state is retained across restarts
+	if err := m.restoreNodes(); err != nil {
+		return nil, err
 	}
 
+	// reconcile the nodes left behind by the jobs interrupted by a restart. This
+	// is the first event processed, once the event loop starts.
+	m.reqQ <- newRecoveryEvent(m)
+
 	if err := m.monitor.RegisterCb(monitor.Discovered, m.enqueueMonitorEvent); err != nil {
 		return nil, errored.Errorf("failed to register node discovery callback. Error: %s", err)
 	}
@@ -91,6 +219,18 @@ func NewManager(config *Config, configFile string) (*Manager, error) {
 		return nil, errored.Errorf("failed to register node disappearance callback. Error: %s", err)
 	}
 
+	if err := m.monitor.RegisterCb(monitor.Left, m.enqueueMonitorEvent); err != nil {
+		return nil, errored.Errorf("failed to register node leave callback. Error: %s", err)
+	}
+
+	if err := m.monitor.RegisterCb(monitor.Updated, m.enqueueMonitorEvent); err != nil {
+		return nil, errored.Errorf("failed to register node update callback. Error: %s", err)
+	}
+
+	if err := m.monitor.RegisterCb(monitor.Reaped, m.enqueueMonitorEvent); err != nil {
+		return nil, errored.Errorf("failed to register node reap callback. Error: %s", err)
+	}
+
 	return m, nil
 }
 
@@ -114,4 +254,9 @@ func (m *Manager) Run(errCh chan error) {
 
 	// start the event loop. It processes the events.
 	go m.eventLoop()
+
+	// start the loop that checks for the nodes that stay disappeared, if needed
+	if len(m.disappearPolicies) > 0 {
+		go m.disappearCheckLoop()
+	}
 }
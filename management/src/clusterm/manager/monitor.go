@@ -1,36 +1,170 @@
 package manager
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/contiv/cluster/management/src/monitor"
 )
 
-func (m *Manager) enqueueMonitorEvent(events []monitor.Event) {
-	// XXX: for now break the batch and inject one event per monitor event.
-	// revisit later as batching requirements become more clear
-	for _, e := range events {
+// monitorEventTypes lists the monitor events in the order their batches are
+// processed. The disappearances are processed before the discoveries, so that
+// a host that rejoins with a different address within the window doesn't
+// conflict with itself. The state changes are processed before the address
+// updates, so that a newly discovered node exists when it's address is updated.
+var monitorEventTypes = []monitor.EventType{
+	monitor.Disappeared,
+	monitor.Left,
+	monitor.Reaped,
+	monitor.Discovered,
+	monitor.Updated,
+}
+
+// pendingMonitorNode holds the monitor events received for a node within the
+// coalescing window. Only the latest change of node's state and the latest
+// update of node's info are retained. The nodes are told apart by their label,
+// serial and address, so that the hosts with conflicting identities are not coalesced.
+type pendingMonitorNode struct {
+	state  *monitor.Event
+	update *monitor.Event
+}
+
+// monitorBatcher coalesces the monitor events received within a window and
+// delivers them to the manager's event loop in batches, one per event type
+type monitorBatcher struct {
+	sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingMonitorNode
+	order   []string
+	flushCb func(t monitor.EventType, nodes []monitor.SubsysNode)
+}
+
+// newMonitorBatcher creates and returns monitorBatcher
+func newMonitorBatcher(window time.Duration, flushCb func(t monitor.EventType, nodes []monitor.SubsysNode)) *monitorBatcher {
+	return &monitorBatcher{
+		window:  window,
+		pending: map[string]*pendingMonitorNode{},
+		flushCb: flushCb,
+	}
+}
+
+// add adds the events to the pending batch. A flush is scheduled at the end of
+// the window, when the first events of a batch are added.
+func (b *monitorBatcher) add(events []monitor.Event) {
+	b.Lock()
+	startWindow := len(b.order) == 0
+	for i := range events {
+		e := events[i]
 		logrus.Debugf("processing monitor event: %+v", e)
-		eventName := ""
-		switch e.Type {
-		case monitor.Discovered:
-			eventName = monitor.Discovered.String()
-		case monitor.Disappeared:
-			eventName = monitor.Disappeared.String()
-		default:
-			logrus.Errorf("unexpected monitor event type %v", e.Type)
+		id := monitorNodeID(e.Node)
+		p, ok := b.pending[id]
+		if !ok {
+			p = &pendingMonitorNode{}
+			b.pending[id] = p
+			b.order = append(b.order, id)
+		}
+		if e.Type == monitor.Updated {
+			p.update = &e
 			continue
 		}
-		if err := NewClient(m.addr).PostMonitorEvent(eventName,
-			[]MonitorNode{
-				{
-					Label:    e.Node.GetLabel(),
-					Serial:   e.Node.GetSerial(),
-					MgmtAddr: e.Node.GetMgmtAddress(),
-				},
-			}); err != nil {
-			logrus.Errorf("error posting monitor event %q. Error: %v", eventName, err)
+		if p.state != nil && p.state.Type != e.Type {
+			logrus.Infof("coalescing monitor events %q and %q for node %q", p.state.Type, e.Type, id)
+		}
+		p.state = &e
+	}
+	startWindow = startWindow && len(b.order) > 0
+	b.Unlock()
+
+	if !startWindow {
+		return
+	}
+	if b.window <= 0 {
+		b.flush()
+		return
+	}
+	time.AfterFunc(b.window, b.flush)
+}
+
+// flush delivers the pending events in batches, one per event type
+func (b *monitorBatcher) flush() {
+	b.Lock()
+	pending, order := b.pending, b.order
+	b.pending, b.order = map[string]*pendingMonitorNode{}, nil
+	b.Unlock()
+
+	batches := map[monitor.EventType][]monitor.SubsysNode{}
+	for _, id := range order {
+		for _, e := range []*monitor.Event{pending[id].state, pending[id].update} {
+			if e != nil {
+				batches[e.Type] = append(batches[e.Type], e.Node)
+			}
+		}
+	}
+	for _, t := range monitorEventTypes {
+		if len(batches[t]) > 0 {
+			b.flushCb(t, batches[t])
+		}
+	}
+}
+
+// enqueueMonitorEvent is the callback registered with the monitoring subsystem.
+// The events are coalesced and delivered in-process to the event loop.
+func (m *Manager) enqueueMonitorEvent(events []monitor.Event) {
+	m.monitorBatcher.add(events)
+}
+
+// enqueueMonitorBatch queues the manager event for a batch of monitor events
+func (m *Manager) enqueueMonitorBatch(t monitor.EventType, nodes []monitor.SubsysNode) {
+	e, err := newMonitorEvent(m, t, nodes)
+	if err != nil {
+		logrus.Errorf("error queuing monitor event. Error: %v", err)
+		return
+	}
+	m.reqQ <- e
+}
+
+// newMonitorEvent returns the manager event that processes a batch of monitor events
+func newMonitorEvent(m *Manager, t monitor.EventType, nodes []monitor.SubsysNode) (event, error) {
+	switch t {
+	case monitor.Discovered:
+		return newDiscoveredEvent(m, nodes), nil
+	case monitor.Disappeared:
+		return newDisappearedEvent(m, nodes), nil
+	case monitor.Left:
+		return newLeftEvent(m, nodes), nil
+	case monitor.Updated:
+		return newUpdatedEvent(m, nodes), nil
+	case monitor.Reaped:
+		return newReapedEvent(m, nodes), nil
+	}
+	return nil, errInvalidEventName(t.String())
+}
+
+// monitorNodesString returns the identities of the nodes in a monitor event, for logging
+func monitorNodesString(nodes []monitor.SubsysNode) string {
+	names := []string{}
+	for _, n := range nodes {
+		names = append(names, monitorNodeID(n))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(names, " "))
+}
+
+// processMonitorNodes processes the nodes of a monitor event one at a time, it
+// continues on failures. The first failure is returned.
+func processMonitorNodes(nodes []monitor.SubsysNode, processCb func(n monitor.SubsysNode) error) error {
+	var firstErr error
+	for _, n := range nodes {
+		if err := processCb(n); err != nil {
+			logrus.Errorf("failed to process monitor event for node %q. Error: %v", monitorNodeID(n), err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
+	return firstErr
 }
 
 func (m *Manager) monitorLoop(errCh chan error) {
@@ -0,0 +1,213 @@
+// +build unittest
+
+package manager
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/cluster/management/src/monitor"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type monitorEventsSuite struct {
+}
+
+var _ = Suite(&monitorEventsSuite{})
+
+func (s *monitorEventsSuite) TestLeftEvent(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Discovered},
+	})
+
+	// the node that left is moved to disappeared state
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeNote, "node left the monitoring subsystem gracefully")
+	mClient.EXPECT().SetAssetStatus("foo-1", inventory.Allocated.String(),
+		inventory.Disappeared.String(), gomock.Any())
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeInfo, gomock.Any())
+	c.Assert(newLeftEvent(mgr, []monitor.SubsysNode{monitor.NewNode("foo", "1", "addr1")}).process(), IsNil)
+	_, state := mgr.nodes["foo-1"].Inv.GetStatus()
+	c.Assert(state, Equals, inventory.Disappeared)
+	c.Assert(mgr.disappeared["foo-1"], NotNil)
+}
+
+func (s *monitorEventsSuite) TestReapedEvent(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Disappeared},
+	})
+
+	mgr.recordTransition("foo-1", inventory.Disappeared)
+
+	// the node is already disappeared, so only the reap is logged. It's
+	// disappearance and timeline are left as is
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeNote,
+		"node reaped from monitoring subsystem, it is not monitored until it joins again")
+	c.Assert(newReapedEvent(mgr, []monitor.SubsysNode{monitor.NewNode("foo", "1", "addr1")}).process(), IsNil)
+	c.Assert(mgr.disappeared["foo-1"], IsNil)
+	c.Assert(mgr.timelines["foo-1"].transitions, HasLen, 1)
+
+	// reaping an unknown node fails
+	err := newReapedEvent(mgr, []monitor.SubsysNode{monitor.NewNode("bar", "1", "addr1")}).process()
+	c.Assert(err, ErrorMatches, ".*bar-1.*doesn't exists")
+}
+
+func (s *monitorEventsSuite) TestUpdatedEvent(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.nodes["foo-1"].Cfg = configuration.NewAnsibleHost("foo-1", "addr1", ansibleMasterGroupName,
+		map[string]string{ansibleNodeAddrHostVar: "addr1"})
+
+	// the address is unchanged, nothing is updated
+	c.Assert(newUpdatedEvent(mgr, []monitor.SubsysNode{monitor.NewNode("foo", "1", "addr1")}).process(), IsNil)
+
+	// the new address is set in the node's monitoring and configuration state
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeInfo,
+		"node's management address changed from addr1 to addr2")
+	saved := ""
+	mClient.EXPECT().SetAssetAttribute("foo-1", nodeConfigAttr, gomock.Any()).Do(
+		func(name, key, val string) { saved = val })
+	c.Assert(newUpdatedEvent(mgr, []monitor.SubsysNode{monitor.NewNode("foo", "1", "addr2")}).process(), IsNil)
+	c.Assert(mgr.nodes["foo-1"].addr(), Equals, "addr2")
+	host := mgr.nodes["foo-1"].Cfg.(*configuration.AnsibleHost)
+	c.Assert(host.GetAddr(), Equals, "addr2")
+	cfg := struct {
+		Vars map[string]string `json:"inventory_vars"`
+	}{}
+	c.Assert(json.Unmarshal([]byte(saved), &cfg), IsNil)
+	c.Assert(cfg.Vars[ansibleNodeAddrHostVar], Equals, "addr2")
+
+	// the address change is deferred while the node is held by an active job
+	j := &Job{nodes: []string{"foo-1"}}
+	c.Assert(mgr.jobs.tryActivate(j), Equals, true)
+	c.Assert(newUpdatedEvent(mgr, []monitor.SubsysNode{monitor.NewNode("foo", "1", "addr3")}).process(), IsNil)
+	c.Assert(host.GetAddr(), Equals, "addr2")
+	c.Assert(mgr.deferredUpdates["foo-1"], NotNil)
+
+	// and is applied once the job finishes
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeInfo,
+		"node's management address changed from addr2 to addr3")
+	mClient.EXPECT().SetAssetAttribute("foo-1", nodeConfigAttr, gomock.Any())
+	mgr.jobs.done(j)
+	mgr.applyDeferredUpdates(j.nodes)
+	c.Assert(host.GetAddr(), Equals, "addr3")
+	c.Assert(mgr.deferredUpdates, HasLen, 0)
+}
+
+func (s *monitorEventsSuite) TestDiscoveredSavesMissingConfig(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Unallocated, inventory.Disappeared},
+	})
+	mgr.quarantined = map[string]*quarantinedNode{}
+
+	// the configuration state of a known node is persisted once, if it's missing
+	mClient.EXPECT().AddAssetLog("foo-1", gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().SetAssetStatus("foo-1", gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().SetAssetAttribute("foo-1", nodeConfigAttr, gomock.Any())
+	node := monitor.NewNode("foo", "1", "addr1")
+	c.Assert(newDiscoveredEvent(mgr, []monitor.SubsysNode{node}).process(), IsNil)
+	c.Assert(newDiscoveredEvent(mgr, []monitor.SubsysNode{node}).process(), IsNil)
+	c.Assert(mgr.nodes["foo-1"].Inv.GetAttribute(nodeConfigAttr), Not(Equals), "")
+}
+
+func (s *monitorEventsSuite) TestMonitorBatcherCoalesce(c *C) {
+	type batch struct {
+		t     monitor.EventType
+		nodes []monitor.SubsysNode
+	}
+	batches := []batch{}
+	b := newMonitorBatcher(0, func(t monitor.EventType, nodes []monitor.SubsysNode) {
+		batches = append(batches, batch{t: t, nodes: nodes})
+	})
+	n1 := monitor.NewNode("foo", "1", "addr1")
+	n2 := monitor.NewNode("foo", "2", "addr2")
+	n3 := monitor.NewNode("foo", "3", "addr3")
+	n3Updated := monitor.NewNode("foo", "3", "addr4")
+
+	// the flap of n1 is coalesced into it's latest event, the disappearances are
+	// delivered first and the update of n3 is delivered after it's discovery
+	b.add([]monitor.Event{
+		{Type: monitor.Discovered, Node: n1},
+		{Type: monitor.Updated, Node: n3Updated},
+		{Type: monitor.Disappeared, Node: n2},
+		{Type: monitor.Disappeared, Node: n1},
+		{Type: monitor.Discovered, Node: n3},
+		{Type: monitor.Discovered, Node: n1},
+	})
+	c.Assert(batches, DeepEquals, []batch{
+		{t: monitor.Disappeared, nodes: []monitor.SubsysNode{n2}},
+		{t: monitor.Discovered, nodes: []monitor.SubsysNode{n1, n3}},
+		{t: monitor.Updated, nodes: []monitor.SubsysNode{n3Updated}},
+	})
+	c.Assert(b.pending, HasLen, 0)
+}
+
+func (s *monitorEventsSuite) TestMonitorBatcherWindow(c *C) {
+	flushed := make(chan []monitor.SubsysNode, 2)
+	b := newMonitorBatcher(50*time.Millisecond, func(t monitor.EventType, nodes []monitor.SubsysNode) {
+		flushed <- nodes
+	})
+	n1 := monitor.NewNode("foo", "1", "addr1")
+	n2 := monitor.NewNode("foo", "2", "addr2")
+
+	// the events added within the window are delivered as one batch
+	b.add([]monitor.Event{{Type: monitor.Discovered, Node: n1}})
+	b.add([]monitor.Event{{Type: monitor.Discovered, Node: n2}})
+	select {
+	case nodes := <-flushed:
+		c.Assert(nodes, DeepEquals, []monitor.SubsysNode{n1, n2})
+	case <-time.After(5 * time.Second):
+		c.Fatalf("monitor events were not flushed")
+	}
+	select {
+	case nodes := <-flushed:
+		c.Fatalf("unexpected batch of monitor events: %v", nodes)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (s *monitorEventsSuite) TestDisappearedEventBatch(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Discovered},
+		"foo-2": {inventory.Allocated, inventory.Disappeared},
+	})
+
+	// all nodes are processed, the failure for the unknown node is returned
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeNote, gomock.Any())
+	mClient.EXPECT().SetAssetStatus("foo-1", inventory.Allocated.String(),
+		inventory.Disappeared.String(), gomock.Any())
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeInfo, gomock.Any())
+	mClient.EXPECT().AddAssetLog("foo-2", inventory.LogTypeNote, gomock.Any())
+	err := newDisappearedEvent(mgr, []monitor.SubsysNode{
+		monitor.NewNode("foo", "1", "addr1"),
+		monitor.NewNode("bar", "1", "addr3"),
+		monitor.NewNode("foo", "2", "addr2"),
+	}).process()
+	c.Assert(err, ErrorMatches, ".*bar-1.*doesn't exists")
+	c.Assert(mgr.disappeared["foo-1"], NotNil)
+	c.Assert(mgr.disappeared["foo-2"], NotNil)
+}
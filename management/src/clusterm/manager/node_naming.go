@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/monitor"
+	"github.com/contiv/errored"
+)
+
+// invalidNodeNameChars matches the characters that are not allowed in a node
+// name, as the name is used as the asset tag in collins
+var invalidNodeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// nodeNameInfo is the info about a node that is available to the node naming
+// template. It doesn't include the node's management address, as the name shall
+// stay the same when the node moves to a new address.
+type nodeNameInfo struct {
+	// Label is the node's label in the monitoring subsystem, usually it's hostname
+	Label string
+	// Serial is the node's serial number
+	Serial string
+	// Hostname is the label up to the first '.', i.e. the short hostname
+	Hostname string
+}
+
+// parseNodeNameTemplate parses the node naming template and checks that it
+// produces a name
+func parseNodeNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("node-name").Parse(text)
+	if err != nil {
+		return nil, errored.Errorf("failed to parse node name template %q. Error: %v", text, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, nodeNameInfo{Label: "l", Serial: "s", Hostname: "h"}); err != nil {
+		return nil, errored.Errorf("invalid node name template %q. Error: %v", text, err)
+	}
+	if sanitizeNodeName(out.String()) == "" {
+		return nil, errored.Errorf("invalid node name template %q, it doesn't produce a name", text)
+	}
+	return tmpl, nil
+}
+
+// sanitizeNodeName replaces the characters that are not allowed in a node name with '-'
+func sanitizeNodeName(name string) string {
+	return strings.Trim(invalidNodeNameChars.ReplaceAllString(name, "-"), "-")
+}
+
+// nodeName returns the name of a node reported by the monitoring subsystem, as
+// per the node naming template. The node is named by it's label and serial as
+// is, when no template is configured. It fails if the node's label or serial
+// is missing.
+func (m *Manager) nodeName(n monitor.SubsysNode) (string, error) {
+	missing := []string{}
+	if n.GetLabel() == "" {
+		missing = append(missing, "label")
+	}
+	if n.GetSerial() == "" {
+		missing = append(missing, "serial")
+	}
+	if len(missing) > 0 {
+		return "", errored.Errorf("node's identity is missing %s", strings.Join(missing, " and "))
+	}
+
+	if m.nodeNameTmpl == nil {
+		return n.GetLabel() + "-" + n.GetSerial(), nil
+	}
+	var out bytes.Buffer
+	if err := m.nodeNameTmpl.Execute(&out, nodeNameInfo{
+		Label:    n.GetLabel(),
+		Serial:   n.GetSerial(),
+		Hostname: strings.SplitN(n.GetLabel(), ".", 2)[0],
+	}); err != nil {
+		return "", errored.Errorf("failed to form node's name. Error: %v", err)
+	}
+	name := sanitizeNodeName(out.String())
+	if name == "" {
+		return "", errored.Errorf("node name template produced an empty name")
+	}
+	return name, nil
+}
+
+// identifyNode returns the name of a discovered node. It fails if the node's
+// identity is missing, or if another node with same name is discovered at a
+// different address, like a host with a cloned serial number.
+func (m *Manager) identifyNode(n monitor.SubsysNode) (string, error) {
+	name, err := m.nodeName(n)
+	if err != nil {
+		return "", err
+	}
+
+	enode, ok := m.nodes[name]
+	if !ok || enode.Mon == nil || enode.Inv == nil || enode.addr() == n.GetMgmtAddress() {
+		return name, nil
+	}
+	if _, state := enode.Inv.GetStatus(); state != inventory.Discovered {
+		return name, nil
+	}
+	return "", errored.Errorf("node's identity conflicts with node %q discovered at address %s",
+		name, enode.addr())
+}
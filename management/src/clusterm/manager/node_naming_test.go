@@ -0,0 +1,135 @@
+// +build unittest
+
+package manager
+
+import (
+	"encoding/json"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/cluster/management/src/monitor"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type nodeNamingSuite struct {
+}
+
+var _ = Suite(&nodeNamingSuite{})
+
+func (s *nodeNamingSuite) TestParseNodeNameTemplate(c *C) {
+	tests := map[string]string{
+		"{{.Label}}-{{.Serial}}":             "",
+		"{{.Hostname}}_{{.Serial}}":          "",
+		"{{.Hostname}}_{{.Addr}}":            "invalid node name template.*can't evaluate field Addr.*",
+		"{{.Label":                           "failed to parse node name template.*",
+		"{{.Foo}}":                           "invalid node name template.*",
+		"...":                                "invalid node name template \"...\", it doesn't produce a name",
+		"{{ if false }}x{{ end }}":           "invalid node name template.*it doesn't produce a name",
+		"node-{{ .Serial | printf \"%s\" }}": "",
+	}
+	for tmpl, exptdErr := range tests {
+		_, err := parseNodeNameTemplate(tmpl)
+		if exptdErr == "" {
+			c.Assert(err, IsNil, Commentf("template: %s", tmpl))
+			continue
+		}
+		c.Assert(err, ErrorMatches, exptdErr, Commentf("template: %s", tmpl))
+	}
+}
+
+func (s *nodeNamingSuite) TestNodeName(c *C) {
+	mgr := &Manager{}
+	hostTmpl, err := parseNodeNameTemplate("{{.Hostname}}.{{.Serial}}")
+	c.Assert(err, IsNil)
+	labelTmpl, err := parseNodeNameTemplate("{{.Label}}-{{.Serial}}")
+	c.Assert(err, IsNil)
+
+	tests := map[string]struct {
+		mgr       *Manager
+		node      monitor.SubsysNode
+		exptdName string
+		exptdErr  string
+	}{
+		"default": {
+			mgr:       mgr,
+			node:      monitor.NewNode("foo", "1", "addr1"),
+			exptdName: "foo-1",
+		},
+		"default-unsanitized": {
+			mgr:       mgr,
+			node:      monitor.NewNode("foo.example.com", "SN 1/2", "addr1"),
+			exptdName: "foo.example.com-SN 1/2",
+		},
+		"sanitized": {
+			mgr:       &Manager{nodeNameTmpl: labelTmpl},
+			node:      monitor.NewNode("foo.example.com", "SN 1/2", "addr1"),
+			exptdName: "foo-example-com-SN-1-2",
+		},
+		"template": {
+			mgr:       &Manager{nodeNameTmpl: hostTmpl},
+			node:      monitor.NewNode("foo.example.com", "1", "addr1"),
+			exptdName: "foo-1",
+		},
+		"missing-serial": {
+			mgr:      mgr,
+			node:     monitor.NewNode("foo", "", "addr1"),
+			exptdErr: "node's identity is missing serial",
+		},
+		"missing-label-and-serial": {
+			mgr:      mgr,
+			node:     monitor.NewNode("", "", "addr1"),
+			exptdErr: "node's identity is missing label and serial",
+		},
+	}
+	for key, test := range tests {
+		name, err := test.mgr.nodeName(test.node)
+		if test.exptdErr != "" {
+			c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("test: %s", key))
+		c.Assert(name, Equals, test.exptdName, Commentf("test: %s", key))
+	}
+}
+
+func (s *nodeNamingSuite) TestDiscoveredQuarantine(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.quarantined = map[string]*quarantinedNode{}
+	mgr.nodes["foo-1"].Mon = monitor.NewNode("foo", "1", "addr1")
+
+	// the node with a cloned serial and the node without serial are quarantined
+	clone := monitor.NewNode("foo", "1", "addr2")
+	c.Assert(newDiscoveredEvent(mgr, []monitor.SubsysNode{
+		clone,
+		monitor.NewNode("bar", "", "addr3"),
+	}).process(), IsNil)
+	c.Assert(mgr.nodes, HasLen, 1)
+	c.Assert(mgr.nodes["foo-1"].addr(), Equals, "addr1")
+
+	e := newListQuarantinedEvent(mgr)
+	c.Assert(e.process(), IsNil)
+	nodes := []quarantinedNode{}
+	c.Assert(json.Unmarshal(e._out, &nodes), IsNil)
+	c.Assert(nodes, HasLen, 2)
+	reasons := map[string]string{}
+	for _, n := range nodes {
+		reasons[n.Addr] = n.Reason
+	}
+	c.Assert(reasons, DeepEquals, map[string]string{
+		"addr2": "node's identity conflicts with node \"foo-1\" discovered at address addr1",
+		"addr3": "node's identity is missing serial",
+	})
+
+	// the quarantined clone disappearing doesn't touch the original node
+	c.Assert(newDisappearedEvent(mgr, []monitor.SubsysNode{clone}).process(), IsNil)
+	c.Assert(mgr.quarantined, HasLen, 1)
+	_, state := mgr.nodes["foo-1"].Inv.GetStatus()
+	c.Assert(state, Equals, inventory.Discovered)
+}
@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// the query parameters of the nodes listing request
+const (
+	nodesQuerySelector  = "selector"
+	nodesQueryStatus    = "status"
+	nodesQueryState     = "state"
+	nodesQueryHostGroup = "host_group"
+	nodesQueryName      = "name"
+	nodesQuerySort      = "sort"
+)
+
+// NodesQuery denotes the filters and the sort order for a listing of nodes.
+// The filters that are not set are ignored.
+type NodesQuery struct {
+	// Selector lists the nodes that match the label selector, like 'zone=a'
+	Selector string
+	// Status lists the nodes in the inventory status, like 'Allocated'
+	Status string
+	// State lists the nodes in the inventory state, like 'Discovered'
+	State string
+	// HostGroup lists the nodes in the host-group
+	HostGroup string
+	// Name lists the nodes whose name matches the shell pattern, like 'node-*'
+	Name string
+	// Sort is the field the nodes are sorted by, one of the nodeSortKeys.
+	// The nodes are listed in an array instead of a map, when it is set.
+	Sort string
+}
+
+// nodeSortKeys maps the fields that a listing of nodes can be sorted by to
+// the function that returns the field's value for a node
+var nodeSortKeys = map[string]func(name string, n *node) string{
+	"name":       func(name string, n *node) string { return name },
+	"addr":       func(name string, n *node) string { return n.addr() },
+	"host_group": func(name string, n *node) string { return n.group() },
+	"status": func(name string, n *node) string {
+		status, _ := n.status()
+		return status
+	},
+	"state": func(name string, n *node) string {
+		_, state := n.status()
+		return state
+	},
+}
+
+// values returns the query as url query parameters
+func (q NodesQuery) values() url.Values {
+	v := url.Values{}
+	for key, val := range map[string]string{
+		nodesQuerySelector:  q.Selector,
+		nodesQueryStatus:    q.Status,
+		nodesQueryState:     q.State,
+		nodesQueryHostGroup: q.HostGroup,
+		nodesQueryName:      q.Name,
+		nodesQuerySort:      q.Sort,
+	} {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	return v
+}
+
+// nodesQueryFromValues returns the query specified by the url query parameters
+func nodesQueryFromValues(v url.Values) NodesQuery {
+	get := func(key string) string { return strings.TrimSpace(v.Get(key)) }
+	return NodesQuery{
+		Selector:  get(nodesQuerySelector),
+		Status:    get(nodesQueryStatus),
+		State:     get(nodesQueryState),
+		HostGroup: get(nodesQueryHostGroup),
+		Name:      get(nodesQueryName),
+		Sort:      get(nodesQuerySort),
+	}
+}
+
+// validate checks the values of the filters and the sort order
+func (q NodesQuery) validate(r roles) error {
+	if q.Status != "" {
+		found := false
+		for status := range inventory.AssetStatusVals {
+			found = found || strings.EqualFold(status, q.Status)
+		}
+		if !found {
+			return errored.Errorf("invalid status specified in the query: %q", q.Status)
+		}
+	}
+	if _, ok := inventory.AssetStateVals[strings.ToUpper(q.State)]; q.State != "" && !ok {
+		return errored.Errorf("invalid state specified in the query: %q", q.State)
+	}
+	if q.HostGroup != "" && r.byGroup(q.HostGroup) == nil {
+		return errored.Errorf("invalid host-group specified in the query: %q", q.HostGroup)
+	}
+	if _, err := path.Match(q.Name, ""); err != nil {
+		return errored.Errorf("invalid name pattern specified in the query: %q. Error: %v", q.Name, err)
+	}
+	if _, ok := nodeSortKeys[q.Sort]; q.Sort != "" && !ok {
+		return errored.Errorf("invalid sort field specified in the query: %q", q.Sort)
+	}
+	return nil
+}
+
+// matches returns true if the node passes all the filters of the query,
+// except the selector that is matched by the caller
+func (q NodesQuery) matches(name string, n *node) bool {
+	status, state := n.status()
+	if q.Status != "" && !strings.EqualFold(q.Status, status) {
+		return false
+	}
+	if q.State != "" && !strings.EqualFold(q.State, state) {
+		return false
+	}
+	if q.HostGroup != "" && q.HostGroup != n.group() {
+		return false
+	}
+	if q.Name != "" {
+		if ok, _ := path.Match(q.Name, name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// addr returns the management address of the node, if it is discovered
+func (n *node) addr() string {
+	if n.Mon == nil {
+		return ""
+	}
+	return n.Mon.GetMgmtAddress()
+}
+
+// group returns the host-group of the node
+func (n *node) group() string {
+	if n.Cfg == nil {
+		return ""
+	}
+	return n.Cfg.GetGroup()
+}
+
+// status returns the inventory status and state of the node
+func (n *node) status() (string, string) {
+	if n.Inv == nil {
+		return "", ""
+	}
+	status, state := n.Inv.GetStatus()
+	return status.String(), state.String()
+}
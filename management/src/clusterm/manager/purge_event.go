@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// purgeEvent deletes the decommissioned nodes from the inventory and the
+// manager's node table. A purged node that is discovered again is added back
+// to the inventory as a new node.
+type purgeEvent struct {
+	mgr       *Manager
+	nodeNames []string
+}
+
+// newPurgeEvent creates and returns purgeEvent
+func newPurgeEvent(mgr *Manager, nodeNames []string) *purgeEvent {
+	return &purgeEvent{
+		mgr:       mgr,
+		nodeNames: nodeNames,
+	}
+}
+
+func (e *purgeEvent) String() string {
+	return fmt.Sprintf("purgeEvent: nodes: %v", e.nodeNames)
+}
+
+func (e *purgeEvent) process() error {
+	if err := e.eventValidate(); err != nil {
+		return err
+	}
+
+	for i, name := range e.nodeNames {
+		if err := e.mgr.inventory.DeleteAsset(name); err != nil {
+			return errored.Errorf("failed to purge node %q, the nodes purged before the failure: %v. Error: %v",
+				name, e.nodeNames[:i], err)
+		}
+		delete(e.mgr.nodes, name)
+		delete(e.mgr.disappeared, name)
+		delete(e.mgr.reappearedAt, name)
+		delete(e.mgr.timelines, name)
+	}
+	return nil
+}
+
+// eventValidate checks that all the nodes are decommissioned and are not being
+// acted upon by a job, so that none of the nodes is purged if any of them can't
+// be purged. The nodes are still purged one at a time, so a failure to delete a
+// node from the inventory leaves the nodes before it purged.
+func (e *purgeEvent) eventValidate() error {
+	if len(e.nodeNames) == 0 {
+		return errored.Errorf("atleast one node should be specified")
+	}
+
+	for _, name := range e.nodeNames {
+		asset := e.mgr.inventory.GetAsset(name)
+		if asset == nil {
+			return nodeInventoryNotExistsError(name)
+		}
+		if status, _ := asset.GetStatus(); status != inventory.Decommissioned {
+			return errored.Errorf("node %q is in %q status, only the decommissioned nodes can be purged", name, status)
+		}
+		if j := e.mgr.jobs.findNodeJob(name); j != nil {
+			return errored.Errorf("node %q is being acted upon by job %d, it can't be purged", name, j.ID())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// +build unittest
+
+package manager
+
+import (
+	"bytes"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/errored"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type purgeSuite struct {
+}
+
+var _ = Suite(&purgeSuite{})
+
+func (s *purgeSuite) TestPurge(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Decommissioned, inventory.Disappeared},
+		"bar": {inventory.Allocated, inventory.Discovered},
+	})
+	mgr.jobs = newJobQueue(1, 1)
+
+	// none of the nodes are purged, if any of them is not decommissioned
+	err := newPurgeEvent(mgr, []string{"foo", "bar"}).process()
+	c.Assert(err, ErrorMatches, "node \"bar\" is in \"Allocated\" status.*")
+	c.Assert(mgr.nodes["foo"], NotNil)
+
+	mClient.EXPECT().DeleteAsset("foo")
+	c.Assert(newPurgeEvent(mgr, []string{"foo"}).process(), IsNil)
+	c.Assert(mgr.nodes["foo"], IsNil)
+	c.Assert(mgr.inventory.GetAsset("foo"), IsNil)
+
+	err = newPurgeEvent(mgr, []string{"foo"}).process()
+	c.Assert(err, ErrorMatches, "the inventory info for node \"foo\" doesn't exist")
+}
+
+func (s *purgeSuite) TestPurgeFailure(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"n1": {inventory.Decommissioned, inventory.Disappeared},
+		"n2": {inventory.Decommissioned, inventory.Disappeared},
+		"n3": {inventory.Decommissioned, inventory.Disappeared},
+	})
+
+	// the nodes purged before an inventory failure are reported
+	mClient.EXPECT().DeleteAsset("n1")
+	mClient.EXPECT().DeleteAsset("n2").Return(errored.Errorf("test failure"))
+	err := newPurgeEvent(mgr, []string{"n1", "n2", "n3"}).process()
+	c.Assert(err, ErrorMatches, "failed to purge node \"n2\", the nodes purged before the failure: \\[n1\\].*test failure")
+	c.Assert(mgr.nodes["n1"], IsNil)
+	c.Assert(mgr.nodes["n2"], NotNil)
+	c.Assert(mgr.nodes["n3"], NotNil)
+}
+
+func (s *purgeSuite) TestForceDecommissionSkipsUnreachable(c *C) {
+	mgr := testManager(c, nil, map[string]assetStatus{
+		"n1": {inventory.Allocated, inventory.Disappeared},
+		"n2": {inventory.Allocated, inventory.Discovered},
+	})
+
+	e := newDecommissionEvent(mgr, []string{"n1"}, "", false)
+	_, err := e.eventValidate()
+	c.Assert(err, ErrorMatches, "one or more nodes are not in discovered state.*")
+
+	e = newDecommissionEvent(mgr, []string{"n1"}, "", true)
+	e._enodes, err = e.eventValidate()
+	c.Assert(err, IsNil)
+	c.Assert(e.prepareInventory(), IsNil)
+	c.Assert(e._unreachable, DeepEquals, []string{"n1"})
+
+	// the configuration subsystem is not touched as there is no node to cleanup
+	var logs bytes.Buffer
+	c.Assert(e.cleanupRunner(make(CancelChannel), &logs), IsNil)
+	c.Assert(logs.String(), Equals, "skipping cleanup of unreachable nodes: [n1]\n")
+}
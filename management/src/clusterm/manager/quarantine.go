@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/monitor"
+)
+
+// quarantinedNode is a node reported by the monitoring subsystem that is not
+// added to the inventory, as it's identity is missing or conflicts with that
+// of another node. A node stays quarantined until it disappears or is
+// discovered again with a valid identity.
+type quarantinedNode struct {
+	Label  string    `json:"label"`
+	Serial string    `json:"serial"`
+	Addr   string    `json:"addr"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+}
+
+// monitorNodeID returns the identity of a node reported by the monitoring
+// subsystem, as reported i.e. before it is named
+func monitorNodeID(n monitor.SubsysNode) string {
+	return fmt.Sprintf("%s/%s/%s", n.GetLabel(), n.GetSerial(), n.GetMgmtAddress())
+}
+
+// quarantineNode records a node whose discovery is rejected
+func (m *Manager) quarantineNode(n monitor.SubsysNode, reason string) {
+	id := monitorNodeID(n)
+	if _, ok := m.quarantined[id]; ok {
+		m.quarantined[id].Reason = reason
+		return
+	}
+	logrus.Warnf("quarantining node with label %q, serial %q and address %s. Reason: %s",
+		n.GetLabel(), n.GetSerial(), n.GetMgmtAddress(), reason)
+	m.quarantined[id] = &quarantinedNode{
+		Label:  n.GetLabel(),
+		Serial: n.GetSerial(),
+		Addr:   n.GetMgmtAddress(),
+		Reason: reason,
+		Since:  time.Now(),
+	}
+}
+
+// releaseQuarantinedNode removes a node from quarantine. It returns true if
+// the node was quarantined.
+func (m *Manager) releaseQuarantinedNode(n monitor.SubsysNode) bool {
+	id := monitorNodeID(n)
+	if _, ok := m.quarantined[id]; !ok {
+		return false
+	}
+	logrus.Infof("releasing node with label %q, serial %q and address %s from quarantine",
+		n.GetLabel(), n.GetSerial(), n.GetMgmtAddress())
+	delete(m.quarantined, id)
+	return true
+}
+
+// listQuarantinedEvent lists the quarantined nodes, ordered by the time they
+// were quarantined
+type listQuarantinedEvent struct {
+	mgr *Manager
+
+	_out []byte
+}
+
+// newListQuarantinedEvent creates and returns listQuarantinedEvent
+func newListQuarantinedEvent(mgr *Manager) *listQuarantinedEvent {
+	return &listQuarantinedEvent{
+		mgr: mgr,
+	}
+}
+
+func (e *listQuarantinedEvent) String() string {
+	return "listQuarantinedEvent"
+}
+
+func (e *listQuarantinedEvent) process() error {
+	nodes := quarantinedNodes{}
+	for _, n := range e.mgr.quarantined {
+		nodes = append(nodes, n)
+	}
+	sort.Sort(nodes)
+
+	var err error
+	e._out, err = json.Marshal(nodes)
+	return err
+}
+
+// quarantinedNodes sorts the quarantined nodes by the time they were quarantined
+type quarantinedNodes []*quarantinedNode
+
+func (q quarantinedNodes) Len() int      { return len(q) }
+func (q quarantinedNodes) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q quarantinedNodes) Less(i, j int) bool {
+	if q[i].Since.Equal(q[j].Since) {
+		return q[i].Addr < q[j].Addr
+	}
+	return q[i].Since.Before(q[j].Since)
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/monitor"
+)
+
+// reapedEvent processes the event of a failed or left node being removed from
+// the monitoring subsystem. The node stays disappeared and no more events are
+// received for it, until it joins again.
+type reapedEvent struct {
+	mgr   *Manager
+	nodes []monitor.SubsysNode
+}
+
+// newReapedEvent creates and returns reapedEvent event
+func newReapedEvent(mgr *Manager, nodes []monitor.SubsysNode) *reapedEvent {
+	return &reapedEvent{
+		mgr:   mgr,
+		nodes: nodes,
+	}
+}
+
+func (e *reapedEvent) String() string {
+	return fmt.Sprintf("reapedEvent: nodes: %s", monitorNodesString(e.nodes))
+}
+
+func (e *reapedEvent) process() error {
+	return processMonitorNodes(e.nodes, e.nodeReaped)
+}
+
+// nodeReaped moves the node to disappeared state, unless it has already
+// disappeared or left, in which case just the reap is logged
+func (e *reapedEvent) nodeReaped(mon monitor.SubsysNode) error {
+	const msg = "node reaped from monitoring subsystem, it is not monitored until it joins again"
+	if e.mgr.releaseQuarantinedNode(mon) {
+		return nil
+	}
+
+	name, err := e.mgr.nodeName(mon)
+	if err != nil {
+		return err
+	}
+	node, err := e.mgr.findNode(name)
+	if err != nil {
+		return err
+	}
+	if node.Inv != nil {
+		if _, state := node.Inv.GetStatus(); state == inventory.Disappeared {
+			node.Mon = mon
+			e.mgr.addAssetLogs([]string{name}, inventory.LogTypeNote, "%s", msg)
+			return nil
+		}
+	}
+	return e.mgr.setNodeDisappeared(mon, msg)
+}
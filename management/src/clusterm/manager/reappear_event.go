@@ -0,0 +1,133 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/errored"
+)
+
+// nodeReappeared triggers the verification or configuration of a commissioned
+// node that reappeared, as per the reappear policy. The jobs are rate limited
+// per node, so that a flapping node doesn't cause a job storm.
+func (m *Manager) nodeReappeared(name string) {
+	policy := m.config.Manager.ReappearPolicy
+	if policy == reappearPolicyNone {
+		return
+	}
+
+	if last, ok := m.reappearedAt[name]; ok && time.Since(last) < m.reappearHoldoff {
+		logrus.Infof("node %q reappeared within %s of last reappearance, skipping %s job",
+			name, m.reappearHoldoff, policy)
+		return
+	}
+	m.reappearedAt[name] = time.Now()
+
+	if err := newReappearEvent(m, name, policy).process(); err != nil {
+		logrus.Errorf("failed to trigger %s job for reappeared node %q. Error: %v", policy, name, err)
+	}
+}
+
+// reappearEvent triggers the verification or configuration of a commissioned node
+// that reappeared after a reboot or unreachability
+type reappearEvent struct {
+	mgr      *Manager
+	nodeName string
+	policy   string
+
+	_hosts []*configuration.AnsibleHost
+	_job   *Job
+}
+
+// newReappearEvent creates and returns reappearEvent
+func newReappearEvent(mgr *Manager, nodeName, policy string) *reappearEvent {
+	return &reappearEvent{
+		mgr:      mgr,
+		nodeName: nodeName,
+		policy:   policy,
+	}
+}
+
+func (e *reappearEvent) String() string {
+	return fmt.Sprintf("reappearEvent: node: %s policy: %s", e.nodeName, e.policy)
+}
+
+func (e *reappearEvent) job() *Job {
+	return e._job
+}
+
+func (e *reappearEvent) process() error {
+	var err error
+	e._job, err = e.mgr.submitJob(
+		e.String(),
+		[]string{e.nodeName},
+		e.prepareJob,
+		e.reappearRunner,
+		func(status JobStatus, errRet error) {
+			if status == Errored {
+				logrus.Errorf("%s job failed for reappeared node %q. Error: %v", e.policy, e.nodeName, errRet)
+				if e.policy == reappearPolicyConfigure {
+					// the configuration has been cleaned up, set asset as unallocated
+					e.mgr.setAssetsStatusBestEffort([]string{e.nodeName}, e.mgr.inventory.SetAssetUnallocated)
+				}
+				// a node that fails verification is left in maintenance, for the
+				// user to take the corrective action
+				return
+			}
+			// set asset as commissioned
+			e.mgr.setAssetsStatusBestEffort([]string{e.nodeName}, e.mgr.inventory.SetAssetCommissioned)
+		})
+	return err
+}
+
+// prepareJob is run before the node verification or configuration is triggered
+func (e *reappearEvent) prepareJob() error {
+	// the node may have changed by the time a queued job is dispatched,
+	// so make sure it is still commissioned
+	enodes, err := e.mgr.commonEventValidate([]string{e.nodeName})
+	if err != nil {
+		return err
+	}
+	isDiscoveredAndAllocated, err := e.mgr.isDiscoveredAndAllocatedNode(e.nodeName)
+	if err != nil {
+		return err
+	}
+	if !isDiscoveredAndAllocated {
+		return errored.Errorf("node %q is not commissioned, skipping the %s job", e.nodeName, e.policy)
+	}
+	e._hosts = []*configuration.AnsibleHost{enodes[e.nodeName].Cfg.(*configuration.AnsibleHost)}
+
+	//set asset as in-maintenance
+	return e.mgr.setAssetsStatusAtomic([]string{e.nodeName}, e.mgr.inventory.SetAssetInMaintenance,
+		e.mgr.inventory.SetAssetCommissioned)
+}
+
+// reappearRunner is the job runner that verifies the configuration of the node or
+// re-runs the configuration on it, as per the policy. The configuration is run with
+// the extra vars that were last applied to the node and it is cleaned up on failure.
+func (e *reappearEvent) reappearRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
+	extraVars, err := validateAndSanitizeEmptyExtraVars("last applied extra vars", e._hosts[0].GetExtraVars())
+	if err != nil {
+		return err
+	}
+	if e.policy == reappearPolicyVerify {
+		outReader, cancelFunc, errCh := e.mgr.configuration.Verify(e._hosts, extraVars)
+		return logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs)
+	}
+
+	cfgErr := e.mgr.configureAndVerify([]string{e.nodeName}, e._hosts, extraVars, cancelCh, jobLogs)
+	if cfgErr == nil {
+		return nil
+	}
+	logrus.Errorf("configuration failed, starting cleanup. Error: %s", cfgErr)
+	outReader, cancelFunc, errCh := e.mgr.configuration.Cleanup(e._hosts, extraVars)
+	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
+		logrus.Errorf("cleanup failed. Error: %s", err)
+	}
+
+	//return the error status from provisioning
+	return cfgErr
+}
@@ -0,0 +1,60 @@
+// +build unittest
+
+package manager
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/contiv/cluster/management/src/configuration"
+	. "gopkg.in/check.v1"
+)
+
+type reappearEventSuite struct {
+}
+
+var _ = Suite(&reappearEventSuite{})
+
+// verifyRecorder is a configuration subsystem that records the extra vars passed
+// to the verify calls
+type verifyRecorder struct {
+	configuration.Subsys
+	extraVars []string
+}
+
+func (r *verifyRecorder) Verify(nodes configuration.SubsysHosts, extraVars string) (io.Reader, context.CancelFunc, chan error) {
+	r.extraVars = append(r.extraVars, extraVars)
+	errCh := make(chan error, 1)
+	errCh <- nil
+	return strings.NewReader(""), func() {}, errCh
+}
+
+func (s *reappearEventSuite) TestReappearRunnerVerify(c *C) {
+	host := configuration.NewAnsibleHost("foo", "", ansibleMasterGroupName, nil)
+	host.SetExtraVars(`{"foo": "bar"}`)
+	rec := &verifyRecorder{}
+	e := newReappearEvent(&Manager{configuration: rec}, "foo", reappearPolicyVerify)
+	e._hosts = []*configuration.AnsibleHost{host}
+
+	c.Assert(e.reappearRunner(make(CancelChannel), &bytes.Buffer{}), IsNil)
+	c.Assert(rec.extraVars, DeepEquals, []string{`{"foo": "bar"}`})
+}
+
+func (s *reappearEventSuite) TestNodeReappearedHoldoff(c *C) {
+	config := DefaultConfig()
+	config.Manager.ReappearPolicy = reappearPolicyVerify
+	last := time.Now()
+	m := &Manager{
+		config:          config,
+		reappearHoldoff: time.Hour,
+		reappearedAt:    map[string]time.Time{"foo": last},
+	}
+
+	// the node reappeared within the holdoff time, so no job is triggered
+	m.nodeReappeared("foo")
+	c.Assert(m.reappearedAt["foo"], Equals, last)
+}
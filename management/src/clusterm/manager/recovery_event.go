@@ -0,0 +1,215 @@
+package manager
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// errJobInterrupted is the error recorded for the jobs interrupted by a restart
+const errJobInterrupted = "job was interrupted by a restart of clusterm"
+
+// recoveryEvent reconciles the assets that were left in a transitional status
+// (i.e. provisioning, provisioned, cancelled or maintenance) by the jobs that were
+// interrupted by a restart of clusterm. Each asset is rolled back to it's previous
+// status, has it's interrupted job re-run or is flagged for operator attention as
+// per the recovery policy.
+type recoveryEvent struct {
+	mgr *Manager
+}
+
+// newRecoveryEvent creates and returns recoveryEvent
+func newRecoveryEvent(mgr *Manager) *recoveryEvent {
+	return &recoveryEvent{
+		mgr: mgr,
+	}
+}
+
+func (e *recoveryEvent) String() string {
+	return "recoveryEvent"
+}
+
+func (e *recoveryEvent) process() error {
+	interrupted := e.interruptedJobNodes()
+
+	assets, ok := e.mgr.inventory.GetAllAssets().(map[string]*inventory.Asset)
+	if !ok {
+		logrus.Errorf("unexpected type of inventory assets: %T, skipping recovery", e.mgr.inventory.GetAllAssets())
+		return nil
+	}
+	// recover the nodes in a fixed order, so that the re-run jobs are predictable
+	names := []string{}
+	for name := range assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	policy := e.mgr.config.Manager.RecoveryPolicy
+	for _, name := range names {
+		status, _ := assets[name].GetStatus()
+		switch status {
+		case inventory.Provisioning, inventory.Provisioned, inventory.Cancelled:
+			// these statuses are only set for the duration of a job
+		case inventory.Maintenance:
+			// a node is also left in maintenance by the disappear policy or a failed
+			// verification, so only the nodes of an interrupted job are recovered
+			if _, ok := interrupted[name]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+		e.recoverNode(name, status, interrupted[name], policy)
+	}
+	return nil
+}
+
+// interruptedJobNodes marks the jobs that were queued or running at the time of the
+// restart as errored in the job history and returns the nodes touched by them, along
+// with the interrupted job of each node. A running job takes precedence over a
+// queued one, as only the running job could have changed the node's status.
+func (e *recoveryEvent) interruptedJobNodes() map[string]*jobInfo {
+	nodes := map[string]*jobInfo{}
+	infos, err := e.mgr.jobStore.GetAllJobs()
+	if err != nil {
+		logrus.Errorf("failed to read the job history, the interrupted jobs can't be determined. Error: %v", err)
+		return nodes
+	}
+	for _, info := range infos {
+		ji := &jobInfo{}
+		if err := json.Unmarshal(info, ji); err != nil {
+			logrus.Errorf("failed to unmarshal job info. Error: %v", err)
+			continue
+		}
+		if ji.Status != Queued.String() && ji.Status != Running.String() {
+			continue
+		}
+		for _, name := range ji.Nodes {
+			if prev, ok := nodes[name]; !ok || prev.Status != Running.String() {
+				nodes[name] = ji
+			}
+		}
+		e.mgr.addAssetLogs(ji.Nodes, inventory.LogTypeError, "job %d failed. Error: %s", ji.ID, errJobInterrupted)
+
+		errJi := *ji
+		errJi.Status = Errored.String()
+		errJi.ErrVal = errJobInterrupted
+		out, err := json.Marshal(errJi)
+		if err != nil {
+			logrus.Errorf("failed to marshal info of job %d. Error: %v", ji.ID, err)
+			continue
+		}
+		if err := e.mgr.jobStore.PutJob(ji.ID, out); err != nil {
+			logrus.Errorf("failed to save info of job %d. Error: %v", ji.ID, err)
+		}
+	}
+	return nodes
+}
+
+// recoverNode reconciles a node as per the recovery policy. The node is flagged
+// for operator attention if the policy can't be applied.
+func (e *recoveryEvent) recoverNode(name string, status inventory.AssetStatus, ji *jobInfo, policy string) {
+	var err error
+	switch policy {
+	case recoveryPolicyRollback:
+		err = e.rollback(name, status)
+	case recoveryPolicyRerun:
+		err = e.rerun(name, status, ji)
+	default:
+		e.flag(name)
+		return
+	}
+	if err != nil {
+		logrus.Errorf("failed to %s the interrupted job of node %q. Error: %v", policy, name, err)
+		e.mgr.addAssetLogs([]string{name}, inventory.LogTypeError,
+			"recovery: failed to %s the interrupted job. Error: %v", policy, err)
+		e.flag(name)
+	}
+}
+
+// rollback moves the node back to the status it was in before the interrupted job.
+// Only the inventory is updated, the node itself is left as is.
+func (e *recoveryEvent) rollback(name string, status inventory.AssetStatus) error {
+	setStatus := e.mgr.inventory.SetAssetCommissioned
+	if status == inventory.Provisioning || status == inventory.Provisioned {
+		setStatus = e.mgr.inventory.SetAssetUnallocated
+	}
+	if err := setStatus(name); err != nil {
+		return err
+	}
+	newStatus, _ := e.mgr.inventory.GetAsset(name).GetStatus()
+	logrus.Infof("recovery: rolled back node %q from status %q to %q", name, status, newStatus)
+	e.mgr.addAssetLogs([]string{name}, inventory.LogTypeNote,
+		"recovery: rolled back the interrupted job, status moved from %q to %q", status, newStatus)
+	return nil
+}
+
+// rerun re-runs the node's interrupted job, as recorded in the job history, with
+// the extra vars that were last applied to the node. Only the commission,
+// decommission, update and upgrade jobs are re-run, for just the node.
+func (e *recoveryEvent) rerun(name string, status inventory.AssetStatus, ji *jobInfo) error {
+	if ji == nil {
+		return errored.Errorf("no interrupted job found for the node in the job history")
+	}
+	n, err := e.mgr.findNode(name)
+	if err != nil {
+		return err
+	}
+	if n.Cfg == nil {
+		return nodeConfigNotExistsError(name)
+	}
+	extraVars, err := validateAndSanitizeEmptyExtraVars("last applied extra vars",
+		n.Cfg.(*configuration.AnsibleHost).GetExtraVars())
+	if err != nil {
+		return err
+	}
+
+	var ev event
+	switch jobEventName(ji.Desc) {
+	case "commissionEvent":
+		// the commission starts from an unallocated node
+		if err := e.mgr.inventory.SetAssetUnallocated(name); err != nil {
+			return err
+		}
+		ev = newCommissionEvent(e.mgr, []string{name}, extraVars, n.Cfg.GetGroup())
+	case "decommissionEvent":
+		ev = newDecommissionEvent(e.mgr, []string{name}, extraVars, false)
+	case "updateEvent":
+		ev = newUpdateEvent(e.mgr, []string{name}, extraVars, "")
+	case "upgradeEvent":
+		// the upgrade starts from a commissioned node, so that just the node's
+		// upgrade is re-run instead of a full cleanup and configure
+		if status == inventory.Maintenance {
+			if err := e.mgr.inventory.SetAssetCommissioned(name); err != nil {
+				return err
+			}
+		}
+		ev = newUpgradeEvent(e.mgr, []string{name}, extraVars, UpgradeOptions{})
+	default:
+		return errored.Errorf("the interrupted job %d (%s) can't be re-run", ji.ID, ji.Desc)
+	}
+	logrus.Infof("recovery: re-running the interrupted job %d of node %q as %s", ji.ID, name, ev)
+	e.mgr.addAssetLogs([]string{name}, inventory.LogTypeNote,
+		"recovery: re-running the interrupted job %d as %s", ji.ID, ev)
+	return ev.process()
+}
+
+// jobEventName returns the name of the event that a job was triggered by, from
+// the job's description
+func jobEventName(desc string) string {
+	return strings.TrimSpace(strings.SplitN(desc, ":", 2)[0])
+}
+
+// flag leaves the node in it's current status and alerts the operator to fix it
+func (e *recoveryEvent) flag(name string) {
+	status, _ := e.mgr.inventory.GetAsset(name).GetStatus()
+	logrus.Errorf("ALERT: node %q was left in status %q by an interrupted job and needs operator attention",
+		name, status)
+	e.mgr.addAssetLogs([]string{name}, inventory.LogTypeNote,
+		"recovery: left in status %q by an interrupted job, needs operator attention", status)
+}
@@ -0,0 +1,141 @@
+// +build unittest
+
+package manager
+
+import (
+	"encoding/json"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type recoverySuite struct {
+}
+
+var _ = Suite(&recoverySuite{})
+
+// testRecoveryManager returns a manager with the specified nodes and the job history
+func testRecoveryManager(c *C, client inventory.SubsysClient, nodes map[string]assetStatus,
+	jobs []jobInfo, policy string) *Manager {
+	mgr := testManager(c, client, nodes)
+	mgr.config = &Config{Manager: clustermConfig{RecoveryPolicy: policy}}
+	store := newMemJobStore()
+	for _, ji := range jobs {
+		info, err := json.Marshal(ji)
+		c.Assert(err, IsNil)
+		c.Assert(store.PutJob(ji.ID, info), IsNil)
+	}
+	mgr.jobStore = store
+	return mgr
+}
+
+func (s *recoverySuite) TestRecoveryFlag(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testRecoveryManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Provisioning, inventory.Discovered},
+		"bar": {inventory.Maintenance, inventory.Disappeared},
+		"baz": {inventory.Allocated, inventory.Discovered},
+	}, []jobInfo{
+		{ID: 1, Nodes: []string{"baz"}, Status: Complete.String()},
+		{ID: 2, Nodes: []string{"foo"}, Status: Running.String()},
+	}, recoveryPolicyFlag)
+
+	// the interrupted job is recorded and the node is flagged. The node in
+	// maintenance is not touched by an interrupted job, so it is left as is.
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeError, "job 2 failed. Error: "+errJobInterrupted)
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeNote, gomock.Any())
+	c.Assert(newRecoveryEvent(mgr).process(), IsNil)
+
+	status, _ := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Provisioning)
+	out, err := mgr.jobStore.GetJob(2)
+	c.Assert(err, IsNil)
+	ji := &jobInfo{}
+	c.Assert(json.Unmarshal(out, ji), IsNil)
+	c.Assert(ji.Status, Equals, Errored.String())
+	c.Assert(ji.ErrVal, Equals, errJobInterrupted)
+}
+
+func (s *recoverySuite) TestRecoveryRollback(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testRecoveryManager(c, mClient, map[string]assetStatus{
+		"n1": {inventory.Provisioned, inventory.Discovered},
+		"n2": {inventory.Cancelled, inventory.Discovered},
+		"n3": {inventory.Maintenance, inventory.Disappeared},
+	}, []jobInfo{
+		{ID: 1, Nodes: []string{"n3"}, Status: Queued.String()},
+	}, recoveryPolicyRollback)
+
+	mClient.EXPECT().SetAssetStatus("n1", inventory.Unallocated.String(), inventory.Discovered.String(), gomock.Any())
+	mClient.EXPECT().SetAssetStatus("n2", inventory.Allocated.String(), inventory.Discovered.String(), gomock.Any())
+	mClient.EXPECT().SetAssetStatus("n3", inventory.Allocated.String(), inventory.Disappeared.String(), gomock.Any())
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	c.Assert(newRecoveryEvent(mgr).process(), IsNil)
+
+	exptdStatus := map[string]inventory.AssetStatus{
+		"n1": inventory.Unallocated,
+		"n2": inventory.Allocated,
+		"n3": inventory.Allocated,
+	}
+	for name, exptd := range exptdStatus {
+		status, _ := mgr.nodes[name].Inv.GetStatus()
+		c.Assert(status, Equals, exptd, Commentf("node: %s", name))
+	}
+}
+
+func (s *recoverySuite) TestRecoveryRerunFailureFlags(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testRecoveryManager(c, mClient, map[string]assetStatus{
+		"foo": {inventory.Cancelled, inventory.Discovered},
+	}, []jobInfo{
+		{ID: 1, Desc: "decommissionEvent: nodes:[foo]", Nodes: []string{"foo"}, Status: Running.String()},
+	}, recoveryPolicyRerun)
+	// the node's configuration state was not restored, so the job can't be re-run
+	mgr.nodes["foo"].Cfg = nil
+
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeError, "job 1 failed. Error: "+errJobInterrupted)
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeError, gomock.Any())
+	mClient.EXPECT().AddAssetLog("foo", inventory.LogTypeNote, gomock.Any())
+	c.Assert(newRecoveryEvent(mgr).process(), IsNil)
+	status, _ := mgr.nodes["foo"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Cancelled)
+}
+
+func (s *recoverySuite) TestRecoveryRerunInterruptedJob(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testRecoveryManager(c, mClient, map[string]assetStatus{
+		"n1": {inventory.Maintenance, inventory.Discovered},
+		"n2": {inventory.Maintenance, inventory.Discovered},
+	}, []jobInfo{
+		{ID: 1, Desc: "upgradeEvent: nodes: [n1]", Nodes: []string{"n1"}, Status: Running.String()},
+		{ID: 2, Desc: "adoptEvent: nodes: [n2]", Nodes: []string{"n2"}, Status: Running.String()},
+	}, recoveryPolicyRerun)
+	mgr.reqQ = make(chan event, 10)
+	mgr.configuration = &upgradeRecorder{mgr: mgr}
+
+	// the node interrupted in middle of an upgrade is upgraded again, instead of
+	// being updated. The adopt job can't be re-run, so that node is flagged.
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().SetAssetStatus("n1", inventory.Allocated.String(), inventory.Discovered.String(), gomock.Any())
+	c.Assert(newRecoveryEvent(mgr).process(), IsNil)
+	j := mgr.jobs.findActiveNodeJob("n1")
+	c.Assert(j, NotNil)
+	c.Assert(j.desc, Matches, "upgradeEvent: nodes: \\[n1\\].*")
+	c.Assert(mgr.jobs.findActiveNodeJob("n2"), IsNil)
+	status, _ := mgr.nodes["n2"].Inv.GetStatus()
+	c.Assert(status, Equals, inventory.Maintenance)
+}
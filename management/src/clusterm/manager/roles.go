@@ -0,0 +1,237 @@
+package manager
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// defaultRoleConfigs are the roles used when none are declared in the config.
+// The workers can't be commissioned without a master.
+var defaultRoleConfigs = []roleConfig{
+	{Name: ansibleMasterGroupName},
+	{Name: ansibleWorkerGroupName, DependsOn: []string{ansibleMasterGroupName}},
+}
+
+var defaultRoles = mustParseRoles(defaultRoleConfigs)
+
+// role is the parsed form of roleConfig
+type role struct {
+	name      string
+	group     string
+	minNodes  int
+	maxNodes  int
+	dependsOn []string
+	quorum    bool
+}
+
+// roles are the roles that the nodes can be commissioned into
+type roles []*role
+
+// byName returns the role with specified name, nil if there is none
+func (r roles) byName(name string) *role {
+	for _, rl := range r {
+		if rl.name == name {
+			return rl
+		}
+	}
+	return nil
+}
+
+// byGroup returns the role whose nodes are configured in the specified
+// host-group, nil if there is none
+func (r roles) byGroup(group string) *role {
+	for _, rl := range r {
+		if rl.group == group {
+			return rl
+		}
+	}
+	return nil
+}
+
+// defaultGroup returns the host-group of the first role that doesn't depend on
+// another role. The newly discovered nodes are placed in this host-group.
+func (r roles) defaultGroup() string {
+	for _, rl := range r {
+		if len(rl.dependsOn) == 0 {
+			return rl.group
+		}
+	}
+	return ""
+}
+
+// parseRoles validates the role declarations and returns the parsed roles
+func parseRoles(configs []roleConfig) (roles, error) {
+	if len(configs) == 0 {
+		return nil, errored.Errorf("atleast one role should be specified")
+	}
+	parsed := roles{}
+	for _, rc := range configs {
+		rl := &role{
+			name:      rc.Name,
+			group:     rc.Group,
+			minNodes:  rc.MinNodes,
+			maxNodes:  rc.MaxNodes,
+			dependsOn: rc.DependsOn,
+			quorum:    rc.Quorum,
+		}
+		if rl.group == "" {
+			rl.group = rl.name
+		}
+		if rl.name == "" {
+			return nil, errored.Errorf("a role is specified without name")
+		}
+		if parsed.byName(rl.name) != nil {
+			return nil, errored.Errorf("role %q is specified more than once", rl.name)
+		}
+		if parsed.byGroup(rl.group) != nil {
+			return nil, errored.Errorf("host-group %q of role %q is used by another role", rl.group, rl.name)
+		}
+		if rl.minNodes < 0 || rl.maxNodes < 0 || (rl.maxNodes > 0 && rl.maxNodes < rl.minNodes) {
+			return nil, errored.Errorf("invalid node counts for role %q, min: %d max: %d",
+				rl.name, rl.minNodes, rl.maxNodes)
+		}
+		parsed = append(parsed, rl)
+	}
+
+	for _, rl := range parsed {
+		for _, dep := range rl.dependsOn {
+			if parsed.byName(dep) == nil {
+				return nil, errored.Errorf("role %q depends on an unknown role %q", rl.name, dep)
+			}
+		}
+		if parsed.dependsOn(rl.name, rl.name, map[string]bool{}) {
+			return nil, errored.Errorf("role %q depends on itself", rl.name)
+		}
+	}
+	return parsed, nil
+}
+
+// mustParseRoles is same as parseRoles, but panics on an error
+func mustParseRoles(configs []roleConfig) roles {
+	r, err := parseRoles(configs)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// dependsOn returns true if the role 'from' depends on the role 'to', directly
+// or through other roles
+func (r roles) dependsOn(from, to string, visited map[string]bool) bool {
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, dep := range r.byName(from).dependsOn {
+		if dep == to || r.dependsOn(dep, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// getRoles returns the roles declared in the config, or the default roles
+func (m *Manager) getRoles() roles {
+	if m.roles == nil {
+		return defaultRoles
+	}
+	return m.roles
+}
+
+// isValidHostGroup checks if the host-group belongs to a role
+func (m *Manager) isValidHostGroup(group string) bool {
+	return m.getRoles().byGroup(group) != nil
+}
+
+// nodeGroupChanges returns the changes that move the nodes to the specified host-group
+func nodeGroupChanges(names []string, group string) map[string]string {
+	changes := map[string]string{}
+	for _, name := range names {
+		changes[name] = group
+	}
+	return changes
+}
+
+// isRoleMemberNode checks if a node is counted in the role of it's host-group.
+// Along with the commissioned nodes in discovered state, the nodes that are
+// being provisioned are counted, as they are being configured in their target
+// host-group. The nodes held by an active job are also counted if inJob is set.
+func (m *Manager) isRoleMemberNode(name string, inJob bool) bool {
+	if inJob && m.jobs != nil && m.jobs.findActiveNodeJob(name) != nil {
+		return true
+	}
+	isDiscoveredAndAllocated, err := m.isDiscoveredAndAllocatedNode(name)
+	if err != nil {
+		logrus.Debugf("a node check failed for %q. Error: %s", name, err)
+		return false
+	}
+	status, _ := m.nodes[name].Inv.GetStatus()
+	return isDiscoveredAndAllocated || status == inventory.Provisioning
+}
+
+// roleNodeCounts returns the number of commissioned nodes of each role, as they
+// are now and as they would be after the nodes in 'changes' are moved to the
+// specified host-groups. A node with empty host-group is removed from it's role.
+// The job making the changes is already active when they are validated, so the
+// nodes in 'changes' are counted as they are recorded in the inventory, while
+// the nodes held by the other active jobs are counted in their target host-group.
+func (m *Manager) roleNodeCounts(changes map[string]string) (current, after map[string]int) {
+	current, after = map[string]int{}, map[string]int{}
+	for name := range m.nodes {
+		group, changed := changes[name]
+		// skip hosts that are not yet provisioned or not in discovered state
+		if m.isRoleMemberNode(name, !changed) {
+			if rl := m.getRoles().byGroup(m.nodes[name].group()); rl != nil {
+				current[rl.name]++
+				if !changed {
+					after[rl.name]++
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+		if rl := m.getRoles().byGroup(group); rl != nil {
+			after[rl.name]++
+		}
+	}
+	return current, after
+}
+
+// validateRoleChanges checks that the roles satisfy their node counts and
+// dependencies after the nodes in 'changes' are moved to the specified
+// host-groups. Only the roles affected by the change are checked, so that a
+// node that disappears doesn't block the changes to the unrelated roles. The
+// action is used in the error message.
+func (m *Manager) validateRoleChanges(action string, changes map[string]string) error {
+	current, after := m.roleNodeCounts(changes)
+	for _, rl := range m.getRoles() {
+		count := after[rl.name]
+		changed := count != current[rl.name]
+		if changed && count > 0 {
+			switch {
+			case count < rl.minNodes:
+				return errored.Errorf("%s the specified node(s) will leave %d node(s) of role %q, make sure atleast %d node(s) are commissioned in the role.",
+					action, count, rl.name, rl.minNodes)
+			case rl.maxNodes > 0 && count > rl.maxNodes:
+				return errored.Errorf("%s the specified node(s) will leave %d node(s) of role %q, atmost %d node(s) can be commissioned in the role.",
+					action, count, rl.name, rl.maxNodes)
+			case rl.quorum && count%2 == 0:
+				return errored.Errorf("%s the specified node(s) will leave %d node(s) of role %q, an odd number of nodes should be commissioned in the role for quorum.",
+					action, count, rl.name)
+			}
+		}
+
+		if count == 0 {
+			continue
+		}
+		for _, dep := range rl.dependsOn {
+			if after[dep] == 0 && (changed || current[dep] > 0) {
+				return errored.Errorf("%s the specified node(s) will leave node(s) of role %q without a node of role %q, make sure atleast one node of role %q is commissioned before and decommissioned after the nodes of role %q.",
+					action, rl.name, dep, dep, rl.name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+// +build unittest
+
+package manager
+
+import (
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	. "gopkg.in/check.v1"
+)
+
+type rolesSuite struct {
+}
+
+var _ = Suite(&rolesSuite{})
+
+func (s *rolesSuite) TestParseRoles(c *C) {
+	tests := map[string]struct {
+		configs  []roleConfig
+		exptdErr string
+	}{
+		"defaults": {
+			configs: defaultRoleConfigs,
+		},
+		"no-roles": {
+			configs:  []roleConfig{},
+			exptdErr: "atleast one role should be specified",
+		},
+		"no-name": {
+			configs:  []roleConfig{{Group: "foo"}},
+			exptdErr: "a role is specified without name",
+		},
+		"duplicate-name": {
+			configs:  []roleConfig{{Name: "foo"}, {Name: "foo", Group: "bar"}},
+			exptdErr: "role \"foo\" is specified more than once",
+		},
+		"duplicate-group": {
+			configs:  []roleConfig{{Name: "foo"}, {Name: "bar", Group: "foo"}},
+			exptdErr: "host-group \"foo\" of role \"bar\" is used by another role",
+		},
+		"invalid-counts": {
+			configs:  []roleConfig{{Name: "foo", MinNodes: 3, MaxNodes: 2}},
+			exptdErr: "invalid node counts for role \"foo\", min: 3 max: 2",
+		},
+		"unknown-dependency": {
+			configs:  []roleConfig{{Name: "foo", DependsOn: []string{"bar"}}},
+			exptdErr: "role \"foo\" depends on an unknown role \"bar\"",
+		},
+		"dependency-cycle": {
+			configs: []roleConfig{
+				{Name: "foo", DependsOn: []string{"bar"}},
+				{Name: "bar", DependsOn: []string{"baz"}},
+				{Name: "baz", DependsOn: []string{"foo"}},
+			},
+			exptdErr: "role \"foo\" depends on itself",
+		},
+	}
+	for key, test := range tests {
+		_, err := parseRoles(test.configs)
+		if test.exptdErr == "" {
+			c.Assert(err, IsNil, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+
+	r, err := parseRoles([]roleConfig{{Name: "storage", Group: "storage-nodes", Quorum: true}})
+	c.Assert(err, IsNil)
+	c.Assert(r.byGroup("storage-nodes").name, Equals, "storage")
+	c.Assert(r.byName("storage").quorum, Equals, true)
+	c.Assert(r.byGroup("storage"), IsNil)
+
+	// the default host-group is of the first role without dependencies
+	c.Assert(defaultRoles.defaultGroup(), Equals, ansibleMasterGroupName)
+	r, err = parseRoles([]roleConfig{
+		{Name: "worker", DependsOn: []string{"master"}},
+		{Name: "master", Group: "masters"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(r.defaultGroup(), Equals, "masters")
+}
+
+// testRolesManager returns a manager with the commissioned nodes in the specified host-groups
+func testRolesManager(c *C, configs []roleConfig, groups map[string]string) *Manager {
+	nodes := map[string]assetStatus{}
+	for name := range groups {
+		nodes[name] = assetStatus{inventory.Allocated, inventory.Discovered}
+	}
+	mgr := testManager(c, nil, nodes)
+	for name, group := range groups {
+		mgr.nodes[name].Cfg = configuration.NewAnsibleHost(name, "", group, nil)
+	}
+	if configs != nil {
+		var err error
+		mgr.roles, err = parseRoles(configs)
+		c.Assert(err, IsNil)
+	}
+	return mgr
+}
+
+func (s *rolesSuite) TestValidateRoleChangesDefaultRoles(c *C) {
+	mgr := testRolesManager(c, nil, map[string]string{
+		"m1": ansibleMasterGroupName,
+		"w1": ansibleWorkerGroupName,
+	})
+	mgr.nodes["n1"] = &node{Cfg: configuration.NewAnsibleHost("n1", "", ansibleMasterGroupName, nil)}
+
+	// workers can be added while there is a master
+	c.Assert(mgr.validateRoleChanges("commissioning", nodeGroupChanges([]string{"n1"}, ansibleWorkerGroupName)), IsNil)
+	// the last master can't be removed before the workers
+	c.Assert(mgr.validateRoleChanges("decommissioning", nodeGroupChanges([]string{"m1"}, "")), ErrorMatches,
+		"decommissioning the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*")
+	c.Assert(mgr.validateRoleChanges("updating", nodeGroupChanges([]string{"m1"}, ansibleWorkerGroupName)), ErrorMatches,
+		"updating the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*")
+	c.Assert(mgr.validateRoleChanges("decommissioning", nodeGroupChanges([]string{"m1", "w1"}, "")), IsNil)
+
+	// a worker can't be commissioned without a master
+	mgr = testRolesManager(c, nil, map[string]string{})
+	mgr.nodes["n1"] = &node{Cfg: configuration.NewAnsibleHost("n1", "", ansibleMasterGroupName, nil)}
+	c.Assert(mgr.validateRoleChanges("commissioning", nodeGroupChanges([]string{"n1"}, ansibleWorkerGroupName)), ErrorMatches,
+		"commissioning the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*")
+}
+
+func (s *rolesSuite) TestValidateRoleChanges(c *C) {
+	configs := []roleConfig{
+		{Name: "master", Group: ansibleMasterGroupName},
+		{Name: "storage", MinNodes: 3, MaxNodes: 5, Quorum: true, DependsOn: []string{"master"}},
+		{Name: "ingress", MaxNodes: 1},
+	}
+	mgr := testRolesManager(c, configs, map[string]string{
+		"m1": ansibleMasterGroupName,
+		"s1": "storage",
+		"s2": "storage",
+		"s3": "storage",
+	})
+	for _, name := range []string{"n1", "n2", "n3"} {
+		mgr.nodes[name] = &node{Cfg: configuration.NewAnsibleHost(name, "", ansibleMasterGroupName, nil)}
+	}
+
+	tests := map[string]struct {
+		action   string
+		names    []string
+		group    string
+		exptdErr string
+	}{
+		"quorum-add": {
+			action:   "commissioning",
+			names:    []string{"n1"},
+			group:    "storage",
+			exptdErr: ".*leave 4 node\\(s\\) of role \"storage\", an odd number of nodes should be commissioned.*",
+		},
+		"quorum-add-pair": {
+			action: "commissioning",
+			names:  []string{"n1", "n2"},
+			group:  "storage",
+		},
+		"max-nodes": {
+			action:   "commissioning",
+			names:    []string{"n1", "n2", "n3"},
+			group:    "storage",
+			exptdErr: ".*leave 6 node\\(s\\) of role \"storage\", atmost 5 node\\(s\\) can be commissioned.*",
+		},
+		"min-nodes": {
+			action:   "decommissioning",
+			names:    []string{"s1", "s2"},
+			group:    "",
+			exptdErr: ".*leave 1 node\\(s\\) of role \"storage\", make sure atleast 3 node\\(s\\) are commissioned.*",
+		},
+		"remove-role": {
+			action: "decommissioning",
+			names:  []string{"s1", "s2", "s3"},
+			group:  "",
+		},
+		"dependency": {
+			action:   "decommissioning",
+			names:    []string{"m1"},
+			group:    "",
+			exptdErr: ".*leave node\\(s\\) of role \"storage\" without a node of role \"master\".*",
+		},
+		"independent-role": {
+			action: "commissioning",
+			names:  []string{"n1"},
+			group:  "ingress",
+		},
+		"independent-role-max-nodes": {
+			action:   "commissioning",
+			names:    []string{"n1", "n2"},
+			group:    "ingress",
+			exptdErr: ".*leave 2 node\\(s\\) of role \"ingress\", atmost 1 node\\(s\\) can be commissioned.*",
+		},
+	}
+	for key, test := range tests {
+		err := mgr.validateRoleChanges(test.action, nodeGroupChanges(test.names, test.group))
+		if test.exptdErr == "" {
+			c.Assert(err, IsNil, Commentf("test: %s", key))
+			continue
+		}
+		c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+
+	// a role left short of nodes by a disappeared node doesn't block the
+	// changes to other roles
+	mgr.nodes["s3"].Inv = inventory.NewAssetWithState(nil, "s3", inventory.Allocated, inventory.Disappeared, nil)
+	c.Assert(mgr.validateRoleChanges("commissioning", nodeGroupChanges([]string{"n1"}, "ingress")), IsNil)
+	c.Assert(mgr.isValidHostGroup("ingress"), Equals, true)
+	c.Assert(mgr.isValidHostGroup(ansibleWorkerGroupName), Equals, false)
+}
+
+func (s *rolesSuite) TestValidateRoleChangesInFlightNodes(c *C) {
+	configs := []roleConfig{
+		{Name: "master", Group: ansibleMasterGroupName},
+		{Name: "worker", DependsOn: []string{"master"}},
+		{Name: "ingress", MaxNodes: 1},
+	}
+	mgr := testRolesManager(c, configs, map[string]string{})
+	mgr.jobs = newJobQueue(5, 5)
+	for _, name := range []string{"m1", "i1", "n1", "n2"} {
+		mgr.nodes[name] = &node{
+			Inv: inventory.NewAssetWithState(nil, name, inventory.Unallocated, inventory.Discovered, nil),
+			Cfg: configuration.NewAnsibleHost(name, "", ansibleMasterGroupName, nil),
+		}
+	}
+
+	// a master is being provisioned and an ingress node is held by an active job
+	mgr.nodes["m1"].Inv = inventory.NewAssetWithState(nil, "m1", inventory.Provisioning, inventory.Discovered, nil)
+	mgr.nodes["i1"].Cfg.(*configuration.AnsibleHost).SetGroup("ingress")
+	c.Assert(mgr.jobs.tryActivate(&Job{nodes: []string{"i1"}}), Equals, true)
+
+	// the nodes being commissioned are counted in their target host-group
+	c.Assert(mgr.validateRoleChanges("commissioning", nodeGroupChanges([]string{"n1"}, "worker")), IsNil)
+	c.Assert(mgr.validateRoleChanges("commissioning", nodeGroupChanges([]string{"n2"}, "ingress")), ErrorMatches,
+		".*leave 2 node\\(s\\) of role \"ingress\", atmost 1 node\\(s\\) can be commissioned.*")
+}
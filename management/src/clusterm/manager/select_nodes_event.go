@@ -0,0 +1,31 @@
+package manager
+
+import "fmt"
+
+// selectNodesEvent looks up the nodes that match a label selector. It is
+// processed in the event loop so that the node table is not read while
+// it is being updated.
+type selectNodesEvent struct {
+	mgr      *Manager
+	selector string
+
+	_nodes []string
+}
+
+// newSelectNodesEvent creates and returns selectNodesEvent
+func newSelectNodesEvent(mgr *Manager, selector string) *selectNodesEvent {
+	return &selectNodesEvent{
+		mgr:      mgr,
+		selector: selector,
+	}
+}
+
+func (e *selectNodesEvent) String() string {
+	return fmt.Sprintf("selectNodesEvent: selector: %q", e.selector)
+}
+
+func (e *selectNodesEvent) process() error {
+	var err error
+	e._nodes, err = e.mgr.selectNodes(e.selector)
+	return err
+}
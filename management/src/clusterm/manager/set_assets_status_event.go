@@ -0,0 +1,45 @@
+package manager
+
+import "fmt"
+
+// setAssetsStatusEvent sets the status of the assets on behalf of a running job.
+// The jobs run outside the event loop, so they post this event instead of
+// changing the inventory themselves. The status is set atomically if a revert
+// callback is specified, else it is set on best effort basis.
+type setAssetsStatusEvent struct {
+	mgr            *Manager
+	nodeNames      []string
+	newStatusCb    setInvStateCallback
+	revertStatusCb setInvStateCallback
+}
+
+// newSetAssetsStatusEvent creates and returns setAssetsStatusEvent
+func newSetAssetsStatusEvent(mgr *Manager, nodeNames []string,
+	newStatusCb, revertStatusCb setInvStateCallback) *setAssetsStatusEvent {
+	return &setAssetsStatusEvent{
+		mgr:            mgr,
+		nodeNames:      nodeNames,
+		newStatusCb:    newStatusCb,
+		revertStatusCb: revertStatusCb,
+	}
+}
+
+func (e *setAssetsStatusEvent) String() string {
+	return fmt.Sprintf("setAssetsStatusEvent: nodes: %v", e.nodeNames)
+}
+
+func (e *setAssetsStatusEvent) process() error {
+	if e.revertStatusCb == nil {
+		e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.newStatusCb)
+		return nil
+	}
+	return e.mgr.setAssetsStatusAtomic(e.nodeNames, e.newStatusCb, e.revertStatusCb)
+}
+
+// setJobAssetsStatus sets the status of the assets from a running job and
+// waits for the event loop to process it
+func (m *Manager) setJobAssetsStatus(names []string, newStatusCb, revertStatusCb setInvStateCallback) error {
+	me := newWaitableEvent(newSetAssetsStatusEvent(m, names, newStatusCb, revertStatusCb))
+	m.reqQ <- me
+	return me.waitForCompletion()
+}
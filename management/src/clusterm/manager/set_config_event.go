@@ -2,7 +2,6 @@ package manager
 
 import (
 	"fmt"
-	"io"
 	"reflect"
 
 	"github.com/contiv/errored"
@@ -12,6 +11,10 @@ func configChangeNotPermittedError(config string) error {
 	return errored.Errorf("%q configuration can't be changed. Only changes to ansible configuration are allowed.", config)
 }
 
+func errConfigChangeJobsPending() error {
+	return errored.Errorf("configuration can't be changed while jobs are active or queued, please try once they are done")
+}
+
 // setConfigEvent triggers the update to global configuration
 type setConfigEvent struct {
 	mgr    *Manager
@@ -31,23 +34,11 @@ func (e *setConfigEvent) String() string {
 }
 
 func (e *setConfigEvent) process() error {
-	// err shouldn't be redefined below
-	var err error
-
-	// we set a noop job to ensure that even for the short time this event is
-	// run no other job get's enqueued and catches us in middle of things
-	err = e.mgr.checkAndSetActiveJob(
-		e.String(),
-		e.noopRunner,
-		func(status JobStatus, errRet error) { return })
-	if err != nil {
-		return err
+	// the config is changed only when no job is active or queued, so that no
+	// job catches us in middle of things
+	if !e.mgr.jobs.isIdle() {
+		return errConfigChangeJobsPending()
 	}
-	defer func() {
-		if err != nil {
-			e.mgr.resetActiveJob()
-		}
-	}()
 
 	// merge the config with default and validate
 	finalConfig, err := DefaultConfig().MergeFromConfig(e.config)
@@ -55,17 +46,13 @@ func (e *setConfigEvent) process() error {
 		return err
 	}
 	e.config = finalConfig
-	err = e.eventValidate()
-	if err != nil {
+	if err := e.eventValidate(); err != nil {
 		return err
 	}
 
 	// update manager's config
 	e.mgr.config = e.config
 
-	// trigger the noop job
-	go e.mgr.runActiveJob()
-
 	return nil
 }
 
@@ -76,6 +63,9 @@ func (e *setConfigEvent) eventValidate() error {
 	if !reflect.DeepEqual(e.config.Serf, e.mgr.config.Serf) {
 		return configChangeNotPermittedError("serf")
 	}
+	if !reflect.DeepEqual(e.config.Monitor, e.mgr.config.Monitor) {
+		return configChangeNotPermittedError("monitor")
+	}
 	if !reflect.DeepEqual(e.config.Inventory, e.mgr.config.Inventory) {
 		return configChangeNotPermittedError("inventory")
 	}
@@ -83,9 +73,10 @@ func (e *setConfigEvent) eventValidate() error {
 		return configChangeNotPermittedError("manager")
 	}
 
-	return nil
-}
+	// the verification playbook is needed by the reappear policy
+	if err := e.config.validateReappearPolicy(); err != nil {
+		return err
+	}
 
-func (e *setConfigEvent) noopRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
 	return nil
 }
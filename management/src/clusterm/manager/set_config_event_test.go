@@ -0,0 +1,50 @@
+// +build unittest
+
+package manager
+
+import (
+	"github.com/contiv/cluster/management/src/monitor"
+	. "gopkg.in/check.v1"
+)
+
+type setConfigSuite struct {
+}
+
+var _ = Suite(&setConfigSuite{})
+
+func (s *setConfigSuite) TestSetConfigValidate(c *C) {
+	mgr := &Manager{config: DefaultConfig()}
+
+	// the ansible configuration can be changed
+	config := DefaultConfig()
+	config.Ansible.User = "foo"
+	c.Assert(newSetConfigEvent(mgr, config).eventValidate(), IsNil)
+
+	// the monitoring subsystem can't be switched at runtime
+	config = DefaultConfig()
+	config.Monitor.Probe = &monitor.ProbeConfig{Hosts: []string{"10.0.0.1"}}
+	c.Assert(newSetConfigEvent(mgr, config).eventValidate(), ErrorMatches,
+		"\"monitor\" configuration can't be changed.*")
+	config = DefaultConfig()
+	config.Manager.Addr = "0.0.0.0:1234"
+	c.Assert(newSetConfigEvent(mgr, config).eventValidate(), ErrorMatches,
+		"\"manager\" configuration can't be changed.*")
+}
+
+func (s *setConfigSuite) TestSetConfigJobsPending(c *C) {
+	mgr := &Manager{config: DefaultConfig(), jobs: newJobQueue(5, 5)}
+	config := DefaultConfig()
+	config.Ansible.User = "foo"
+
+	// the config is not changed while a job is active
+	j := testJob(1, "foo")
+	c.Assert(mgr.jobs.tryActivate(j), Equals, true)
+	c.Assert(newSetConfigEvent(mgr, config).process(), ErrorMatches,
+		"configuration can't be changed while jobs are active or queued.*")
+	c.Assert(mgr.config.Ansible.User, Not(Equals), "foo")
+
+	// and is changed right away once the jobs are done
+	mgr.jobs.done(j)
+	c.Assert(newSetConfigEvent(mgr, config).process(), IsNil)
+	c.Assert(mgr.config.Ansible.User, Equals, "foo")
+}
@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// setLabelsEvent sets the user defined labels of one or more nodes. The
+// specified labels are merged with the existing ones and a label with an
+// empty value is removed.
+type setLabelsEvent struct {
+	mgr       *Manager
+	nodeNames []string
+	labels    map[string]string
+}
+
+// newSetLabelsEvent creates and returns setLabelsEvent
+func newSetLabelsEvent(mgr *Manager, nodeNames []string, labels map[string]string) *setLabelsEvent {
+	return &setLabelsEvent{
+		mgr:       mgr,
+		nodeNames: nodeNames,
+		labels:    labels,
+	}
+}
+
+func (e *setLabelsEvent) String() string {
+	return fmt.Sprintf("setLabelsEvent: nodes: %v labels: %v", e.nodeNames, e.labels)
+}
+
+func (e *setLabelsEvent) process() error {
+	if err := e.eventValidate(); err != nil {
+		return err
+	}
+
+	// the labels are swapped in only after they are saved for all the nodes,
+	// so that the nodes' labels don't go out of sync with the inventory
+	newLabels := map[string]map[string]string{}
+	for _, name := range e.nodeNames {
+		labels := map[string]string{}
+		for k, v := range e.mgr.nodes[name].Labels {
+			labels[k] = v
+		}
+		for k, v := range e.labels {
+			if v == "" {
+				delete(labels, k)
+				continue
+			}
+			labels[k] = v
+		}
+		newLabels[name] = labels
+	}
+
+	for i, name := range e.nodeNames {
+		if err := e.mgr.saveNodeLabels(name, newLabels[name]); err != nil {
+			// revert the labels of the nodes saved so far
+			for _, saved := range e.nodeNames[:i] {
+				if err := e.mgr.saveNodeLabels(saved, e.mgr.nodes[saved].Labels); err != nil {
+					logrus.Errorf("failed to revert labels of node %q. Error: %v", saved, err)
+				}
+			}
+			return errored.Errorf("failed to save labels of node %q. Error: %v", name, err)
+		}
+	}
+
+	for _, name := range e.nodeNames {
+		e.mgr.nodes[name].Labels = newLabels[name]
+		e.mgr.addAssetLogs([]string{name}, inventory.LogTypeInfo, "node labels set to: %s", formatLabels(newLabels[name]))
+	}
+	return nil
+}
+
+// eventValidate checks that the labels are valid and all the nodes exist,
+// so that either all or none of the nodes are labelled
+func (e *setLabelsEvent) eventValidate() error {
+	if len(e.nodeNames) == 0 {
+		return errored.Errorf("atleast one node should be specified")
+	}
+	if err := validateLabels(e.labels); err != nil {
+		return err
+	}
+	for _, name := range e.nodeNames {
+		if _, err := e.mgr.findNode(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// maxTimelineTransitions is the maximum number of transitions retained in a
+// node's monitoring timeline, the oldest transitions are dropped first
+const maxTimelineTransitions = 100
+
+// flapPolicy specifies the number of disappearances within a window, after
+// which a node is considered flapping. The flap detection is disabled when the
+// threshold is 0.
+type flapPolicy struct {
+	threshold int
+	window    time.Duration
+}
+
+// monitorTransition is a change in the monitoring state of a node
+type monitorTransition struct {
+	State string    `json:"state"`
+	Time  time.Time `json:"time"`
+}
+
+// nodeTimeline is the history of the monitoring state transitions of a node,
+// oldest first. It is only accessed in the event loop.
+type nodeTimeline struct {
+	transitions []monitorTransition
+}
+
+// monitorHistory summarizes the monitoring timeline of a node over the flap window
+type monitorHistory struct {
+	// Transitions are the transitions within the window
+	Transitions []monitorTransition `json:"transitions"`
+	// Flaps is the number of times the node disappeared within the window
+	Flaps int `json:"flaps"`
+	// UptimePercent is the percentage of time the node stayed discovered within
+	// the window. It is not set when no transition is known for the node.
+	UptimePercent *float64 `json:"uptime_percent,omitempty"`
+	Window        string   `json:"window"`
+	Flapping      bool     `json:"flapping"`
+}
+
+// record adds a transition to the timeline. It returns false if the node is
+// already in the state.
+func (t *nodeTimeline) record(state inventory.AssetState, at time.Time) bool {
+	if l := len(t.transitions); l > 0 && t.transitions[l-1].State == state.String() {
+		return false
+	}
+	t.transitions = append(t.transitions, monitorTransition{State: state.String(), Time: at})
+	if len(t.transitions) > maxTimelineTransitions {
+		t.transitions = t.transitions[len(t.transitions)-maxTimelineTransitions:]
+	}
+	return true
+}
+
+// history returns the summary of the timeline over the policy's window, ending now
+func (t *nodeTimeline) history(now time.Time, policy flapPolicy) monitorHistory {
+
+	h := monitorHistory{
+		Transitions: []monitorTransition{},
+		Window:      policy.window.String(),
+	}
+	start := now.Add(-policy.window)
+	var (
+		up, measured time.Duration
+		last         *monitorTransition
+	)
+	for i := range t.transitions {
+		tr := &t.transitions[i]
+		if tr.Time.After(start) {
+			h.Transitions = append(h.Transitions, *tr)
+			// the first transition of the timeline is the node's initial state, not a flap
+			if i > 0 && tr.State == inventory.Disappeared.String() {
+				h.Flaps++
+			}
+		}
+		if last != nil {
+			d := spanWithin(last.Time, tr.Time, start)
+			measured += d
+			if last.State == inventory.Discovered.String() {
+				up += d
+			}
+		}
+		last = tr
+	}
+	if last == nil {
+		return h
+	}
+	d := spanWithin(last.Time, now, start)
+	measured += d
+	if last.State == inventory.Discovered.String() {
+		up += d
+	}
+
+	uptime := 100.0
+	if measured > 0 {
+		uptime = 100 * float64(up) / float64(measured)
+	} else if last.State != inventory.Discovered.String() {
+		uptime = 0
+	}
+	h.UptimePercent = &uptime
+	h.Flapping = policy.threshold > 0 && h.Flaps >= policy.threshold
+	return h
+}
+
+// spanWithin returns the part of the span from 'from' to 'to' that falls after start
+func spanWithin(from, to, start time.Time) time.Duration {
+	if from.Before(start) {
+		from = start
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// recordTransition records a change in the monitoring state of a node and
+// logs when the node starts flapping
+func (m *Manager) recordTransition(name string, state inventory.AssetState) {
+	t, ok := m.timelines[name]
+	if !ok {
+		t = &nodeTimeline{}
+		m.timelines[name] = t
+	}
+	if !t.record(state, time.Now()) {
+		return
+	}
+
+	if h := t.history(time.Now(), m.flapPolicy); h.Flapping && h.Flaps == m.flapPolicy.threshold {
+		m.addAssetLogs([]string{name}, inventory.LogTypeNote,
+			"node is flapping, it disappeared %d times in last %s", h.Flaps, h.Window)
+	}
+}
+
+// nodeHistory returns the summary of the monitoring timeline of a node
+func (m *Manager) nodeHistory(name string) monitorHistory {
+	t, ok := m.timelines[name]
+	if !ok {
+		t = &nodeTimeline{}
+	}
+	return t.history(time.Now(), m.flapPolicy)
+}
+
+// areNotFlappingNodes returns an error if any of the nodes is flapping
+func (m *Manager) areNotFlappingNodes(names []string) error {
+	flappingNodes := []string{}
+	for _, name := range names {
+		if m.nodeHistory(name).Flapping {
+			flappingNodes = append(flappingNodes, name)
+		}
+	}
+	if len(flappingNodes) > 0 {
+		return errored.Errorf("one or more nodes are flapping, please check their network stability. Flapping nodes: %v", flappingNodes)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// +build unittest
+
+package manager
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/cluster/management/src/monitor"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type timelineSuite struct {
+}
+
+var _ = Suite(&timelineSuite{})
+
+func (s *timelineSuite) TestRecord(c *C) {
+	t := &nodeTimeline{}
+	now := time.Now()
+	c.Assert(t.record(inventory.Discovered, now), Equals, true)
+	// a repeated state is not a transition
+	c.Assert(t.record(inventory.Discovered, now), Equals, false)
+	c.Assert(t.record(inventory.Disappeared, now), Equals, true)
+	c.Assert(t.transitions, HasLen, 2)
+
+	// the oldest transitions are dropped
+	for i := 0; i < maxTimelineTransitions; i++ {
+		t.record(inventory.Discovered, now)
+		t.record(inventory.Disappeared, now)
+	}
+	c.Assert(t.transitions, HasLen, maxTimelineTransitions)
+}
+
+func (s *timelineSuite) TestHistory(c *C) {
+	now := time.Now()
+	policy := flapPolicy{threshold: 2, window: time.Hour}
+
+	// no transitions
+	h := (&nodeTimeline{}).history(now, policy)
+	c.Assert(h.UptimePercent, IsNil)
+	c.Assert(h.Flaps, Equals, 0)
+	c.Assert(h.Flapping, Equals, false)
+	c.Assert(h.Window, Equals, "1h0m0s")
+
+	// discovered before the window, down for 15 minutes within the window
+	t := &nodeTimeline{transitions: []monitorTransition{
+		{State: inventory.Discovered.String(), Time: now.Add(-2 * time.Hour)},
+		{State: inventory.Disappeared.String(), Time: now.Add(-30 * time.Minute)},
+		{State: inventory.Discovered.String(), Time: now.Add(-15 * time.Minute)},
+	}}
+	h = t.history(now, policy)
+	c.Assert(h.Transitions, HasLen, 2)
+	c.Assert(h.Flaps, Equals, 1)
+	c.Assert(h.Flapping, Equals, false)
+	c.Assert(*h.UptimePercent, Equals, 75.0)
+
+	// one more flap makes the node flapping
+	t.record(inventory.Disappeared, now.Add(-10*time.Minute))
+	t.record(inventory.Discovered, now.Add(-5*time.Minute))
+	h = t.history(now, policy)
+	c.Assert(h.Flaps, Equals, 2)
+	c.Assert(h.Flapping, Equals, true)
+	c.Assert(*h.UptimePercent, Equals, 100*float64(40)/60)
+
+	// the flap detection is disabled with no threshold
+	policy.threshold = 0
+	c.Assert(t.history(now, policy).Flapping, Equals, false)
+
+	// the first transition of a node is not a flap
+	t = &nodeTimeline{transitions: []monitorTransition{
+		{State: inventory.Disappeared.String(), Time: now.Add(-time.Minute)},
+	}}
+	h = t.history(now, policy)
+	c.Assert(h.Flaps, Equals, 0)
+	c.Assert(*h.UptimePercent, Equals, 0.0)
+}
+
+func (s *timelineSuite) TestFlappingNode(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mgr := testManager(c, mClient, map[string]assetStatus{
+		"foo-1": {inventory.Unallocated, inventory.Discovered},
+		"bar-1": {inventory.Unallocated, inventory.Discovered},
+	})
+	mgr.quarantined = map[string]*quarantinedNode{}
+	mgr.flapPolicy = flapPolicy{threshold: 2, window: time.Hour}
+	mgr.recordTransition("foo-1", inventory.Discovered)
+
+	// the node is noted as flapping when it disappears the second time
+	mClient.EXPECT().AddAssetLog("foo-1", inventory.LogTypeNote,
+		"node is flapping, it disappeared 2 times in last 1h0m0s")
+	mClient.EXPECT().AddAssetLog("foo-1", gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().SetAssetStatus("foo-1", inventory.Unallocated.String(), gomock.Any(), gomock.Any()).AnyTimes()
+	// the configuration state is persisted on first discovery
+	mClient.EXPECT().SetAssetAttribute("foo-1", nodeConfigAttr, gomock.Any())
+	node := monitor.NewNode("foo", "1", "addr1")
+	for i := 0; i < 2; i++ {
+		c.Assert(newDisappearedEvent(mgr, []monitor.SubsysNode{node}).process(), IsNil)
+		c.Assert(newDiscoveredEvent(mgr, []monitor.SubsysNode{node}).process(), IsNil)
+	}
+
+	h := mgr.nodeHistory("foo-1")
+	c.Assert(h.Transitions, HasLen, 5)
+	c.Assert(h.Flaps, Equals, 2)
+	c.Assert(h.Flapping, Equals, true)
+	c.Assert(mgr.nodeHistory("bar-1").Flapping, Equals, false)
+
+	// the history is reported along with the node
+	ge := newGetNodeEvent(mgr, "foo-1")
+	c.Assert(ge.process(), IsNil)
+	out := struct {
+		History monitorHistory `json:"monitoring_history"`
+	}{}
+	c.Assert(json.Unmarshal(ge._out, &out), IsNil)
+	c.Assert(out.History.Flapping, Equals, true)
+	c.Assert(newGetNodeEvent(mgr, "baz-1").process(), ErrorMatches, ".*node with name or address \"baz-1\" doesn't exists.*")
+
+	c.Assert(mgr.areNotFlappingNodes([]string{"bar-1"}), IsNil)
+	c.Assert(mgr.areNotFlappingNodes([]string{"foo-1", "bar-1"}), ErrorMatches,
+		"one or more nodes are flapping.*Flapping nodes: \\[foo-1\\]")
+	e := newCommissionEvent(mgr, []string{"foo-1"}, "", ansibleMasterGroupName)
+	c.Assert(e.eventValidate(), ErrorMatches, "one or more nodes are flapping.*")
+}
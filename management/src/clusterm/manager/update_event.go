@@ -18,6 +18,7 @@ type updateEvent struct {
 
 	_hosts  configuration.SubsysHosts
 	_enodes map[string]*node
+	_job    *Job
 }
 
 // newUpdateEvent creates and returns updateEvent
@@ -34,52 +35,52 @@ func (e *updateEvent) String() string {
 	return fmt.Sprintf("updateEvent: nodes: %v extra-vars: %v host-group: %q", e.nodeNames, e.extraVars, e.hostGroup)
 }
 
+func (e *updateEvent) job() *Job {
+	return e._job
+}
+
 func (e *updateEvent) process() error {
-	// err shouldn't be redefined below
 	var err error
-
-	err = e.mgr.checkAndSetActiveJob(
+	e._job, err = e.mgr.submitJob(
 		e.String(),
+		e.nodeNames,
+		e.prepareJob,
 		e.updateRunner,
 		func(status JobStatus, errRet error) {
+			// persist the host-group and vars of the nodes in either case, as the
+			// configuration state has been updated as part of the event
+			defer e.mgr.saveNodesConfigBestEffort(e.nodeNames)
 			if status == Errored {
 				logrus.Errorf("configuration job failed. Error: %v", errRet)
 				// set assets as unallocated
 				e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetUnallocated)
 				return
 			}
+			// record the extra vars that were applied
+			for _, host := range e._hosts.([]*configuration.AnsibleHost) {
+				host.SetExtraVars(e.extraVars)
+			}
 			// set assets as commissioned
 			e.mgr.setAssetsStatusBestEffort(e.nodeNames, e.mgr.inventory.SetAssetCommissioned)
 		})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			e.mgr.resetActiveJob()
-		}
-	}()
+	return err
+}
 
+// prepareJob is run before the node upgrade is triggered
+func (e *updateEvent) prepareJob() error {
 	// validate event data
-	if err = e.eventValidate(); err != nil {
+	if err := e.eventValidate(); err != nil {
 		return err
 	}
 
 	// prepare inventory
-	if err = e.pepareInventory(); err != nil {
+	if err := e.pepareInventory(); err != nil {
 		return err
 	}
 
 	//set assets as in-maintenance
-	if err = e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetInMaintenance,
-		e.mgr.inventory.SetAssetCommissioned); err != nil {
-		return err
-	}
-
-	// trigger node upgrade event
-	go e.mgr.runActiveJob()
-
-	return nil
+	return e.mgr.setAssetsStatusAtomic(e.nodeNames, e.mgr.inventory.SetAssetInMaintenance,
+		e.mgr.inventory.SetAssetCommissioned)
 }
 
 // eventValidate perfoms the validations
@@ -90,46 +91,22 @@ func (e *updateEvent) eventValidate() error {
 		return err
 	}
 
-	if e.hostGroup != "" && !IsValidHostGroup(e.hostGroup) {
-		return errored.Errorf("invalid host-group specified: %q", e.hostGroup)
+	// a flapping node is likely to disappear while it's being configured
+	if err := e.mgr.areNotFlappingNodes(e.nodeNames); err != nil {
+		return err
 	}
 
-	// when workers are being configured, make sure that there is atleast one service-master
-	if e.hostGroup == ansibleWorkerGroupName {
-		masterCommissioned := false
-		for name := range e.mgr.nodes {
-			if _, ok := e._enodes[name]; ok {
-				// skip nodes in the event
-				continue
-			}
-
-			isDiscoveredAndAllocated, err := e.mgr.isDiscoveredAndAllocatedNode(name)
-			if err != nil || !isDiscoveredAndAllocated {
-				if err != nil {
-					logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-				}
-				// skip hosts that are not yet provisioned or not in discovered state
-				continue
-			}
-
-			isMasterNode, err := e.mgr.isMasterNode(name)
-			if err != nil || !isMasterNode {
-				if err != nil {
-					logrus.Debugf("a node check failed for %q. Error: %s", name, err)
-				}
-				//skip the hosts that are not in master group
-				continue
-			}
+	if e.hostGroup == "" {
+		return nil
+	}
 
-			// found a master node
-			masterCommissioned = true
-			break
-		}
-		if !masterCommissioned {
-			return errored.Errorf("Updating these nodes as worker will result in no master node in the cluster, make sure atleast one node is commissioned as master.")
-		}
+	if !e.mgr.isValidHostGroup(e.hostGroup) {
+		return errored.Errorf("invalid host-group specified: %q", e.hostGroup)
 	}
-	return nil
+
+	// make sure that the roles are left with the required nodes and the nodes
+	// they depend on
+	return e.mgr.validateRoleChanges("updating", nodeGroupChanges(e.nodeNames, e.hostGroup))
 }
 
 // pepareInventory prepares the inventory for update event.
@@ -148,7 +125,8 @@ func (e *updateEvent) pepareInventory() error {
 }
 
 // updateRunner is the job runner that runs a cleanup playbook followed by provision playbook
-// on one or more nodes. In case of provision failure the cleanup playbook it run again.
+// on one or more nodes and verifies the configuration. In case of provision or verification
+// failure the cleanup playbook it run again.
 func (e *updateEvent) updateRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
 	outReader, cancelFunc, errCh := e.mgr.configuration.Cleanup(e._hosts, e.extraVars)
 	if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
@@ -156,8 +134,7 @@ func (e *updateEvent) updateRunner(cancelCh CancelChannel, jobLogs io.Writer) er
 		// XXX: is there a case where we should continue on error here?
 		return err
 	}
-	outReader, cancelFunc, errCh = e.mgr.configuration.Configure(e._hosts, e.extraVars)
-	cfgErr := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs)
+	cfgErr := e.mgr.configureAndVerify(e.nodeNames, e._hosts, e.extraVars, cancelCh, jobLogs)
 	if cfgErr == nil {
 		return nil
 	}
@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/monitor"
+)
+
+// updatedEvent processes the event of a change in a node's info in the
+// monitoring subsystem. The node's management address is refreshed in it's
+// monitoring info and configuration state, so that the configuration is
+// pushed to the new address. The configuration state of a node held by an
+// active job is updated after the job finishes, as the job is configuring the
+// node at it's old address.
+type updatedEvent struct {
+	mgr   *Manager
+	nodes []monitor.SubsysNode
+}
+
+// newUpdatedEvent creates and returns updatedEvent event
+func newUpdatedEvent(mgr *Manager, nodes []monitor.SubsysNode) *updatedEvent {
+	return &updatedEvent{
+		mgr:   mgr,
+		nodes: nodes,
+	}
+}
+
+func (e *updatedEvent) String() string {
+	return fmt.Sprintf("updatedEvent: nodes: %s", monitorNodesString(e.nodes))
+}
+
+func (e *updatedEvent) process() error {
+	return processMonitorNodes(e.nodes, e.nodeUpdated)
+}
+
+// nodeUpdated refreshes the management address of a node
+func (e *updatedEvent) nodeUpdated(mon monitor.SubsysNode) error {
+	name, err := e.mgr.nodeName(mon)
+	if err != nil {
+		return err
+	}
+
+	node, err := e.mgr.findNode(name)
+	if err != nil {
+		return err
+	}
+
+	// update node's monitoring info to the one received in the event.
+	node.Mon = mon
+
+	host, ok := node.Cfg.(*configuration.AnsibleHost)
+	if !ok {
+		return nodeConfigNotExistsError(name)
+	}
+	addr := mon.GetMgmtAddress()
+	if host.GetAddr() == addr {
+		delete(e.mgr.deferredUpdates, name)
+		return nil
+	}
+
+	if j := e.mgr.jobs.findActiveNodeJob(name); j != nil {
+		logrus.Infof("deferring the management address change of %q to %s till job %d finishes", name, addr, j.ID())
+		e.mgr.deferredUpdates[name] = mon
+		return nil
+	}
+	delete(e.mgr.deferredUpdates, name)
+
+	e.mgr.addAssetLogs([]string{name}, inventory.LogTypeInfo,
+		"node's management address changed from %s to %s", host.GetAddr(), addr)
+	host.SetAddr(addr)
+	host.SetVar(ansibleNodeAddrHostVar, addr)
+	if err := e.mgr.saveNodeConfig(name); err != nil {
+		logrus.Errorf("saving configuration state of %q in inventory failed. Error: %s", name, err)
+		return err
+	}
+	return nil
+}
+
+// applyDeferredUpdates applies the management address changes of the nodes that
+// were deferred while the nodes were held by an active job
+func (m *Manager) applyDeferredUpdates(names []string) {
+	nodes := []monitor.SubsysNode{}
+	for _, name := range names {
+		if mon, ok := m.deferredUpdates[name]; ok {
+			nodes = append(nodes, mon)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	if err := newUpdatedEvent(m, nodes).process(); err != nil {
+		logrus.Errorf("failed to apply the deferred management address changes. Error: %s", err)
+	}
+}
@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/errored"
+)
+
+// upgradeBatchEvent picks the next batch of nodes to upgrade from the nodes
+// pending upgrade and moves them to maintenance. It is posted by the upgrade
+// job's runner, so that the unavailable nodes are counted and the batch is
+// moved to maintenance without any other node changing state in between.
+type upgradeBatchEvent struct {
+	mgr            *Manager
+	pending        []string
+	batchSize      int
+	maxUnavailable int
+
+	_batch []string
+}
+
+// newUpgradeBatchEvent creates and returns upgradeBatchEvent
+func newUpgradeBatchEvent(mgr *Manager, pending []string, batchSize, maxUnavailable int) *upgradeBatchEvent {
+	return &upgradeBatchEvent{
+		mgr:            mgr,
+		pending:        pending,
+		batchSize:      batchSize,
+		maxUnavailable: maxUnavailable,
+	}
+}
+
+func (e *upgradeBatchEvent) String() string {
+	return fmt.Sprintf("upgradeBatchEvent: pending nodes: %v batch-size: %d max-unavailable: %d",
+		e.pending, e.batchSize, e.maxUnavailable)
+}
+
+func (e *upgradeBatchEvent) process() error {
+	// the batch is shrunk so that the unavailable nodes of a host-group, along
+	// with the nodes of the batch, don't exceed the max unavailable nodes
+	batch := []string{}
+	unavailable := map[string]int{}
+	for _, name := range e.pending {
+		if len(batch) == e.batchSize {
+			break
+		}
+		n, err := e.mgr.findNode(name)
+		if err != nil {
+			return err
+		}
+		if e.maxUnavailable > 0 {
+			group := n.Cfg.GetGroup()
+			if _, ok := unavailable[group]; !ok {
+				unavailable[group] = e.mgr.unavailableGroupNodes(group)
+			}
+			if unavailable[group] >= e.maxUnavailable {
+				if len(batch) > 0 {
+					break
+				}
+				return errored.Errorf("host-group %q has %d unavailable node(s), upgrading node %q would exceed the max unavailable nodes: %d",
+					group, unavailable[group], name, e.maxUnavailable)
+			}
+			unavailable[group]++
+		}
+		batch = append(batch, name)
+	}
+
+	if err := e.mgr.setAssetsStatusAtomic(batch, e.mgr.inventory.SetAssetInMaintenance,
+		e.mgr.inventory.SetAssetCommissioned); err != nil {
+		return err
+	}
+	e._batch = batch
+	return nil
+}
+
+// unavailableGroupNodes returns the number of nodes in the host-group that are
+// either in maintenance or are commissioned but have disappeared
+func (m *Manager) unavailableGroupNodes(group string) int {
+	count := 0
+	for _, n := range m.nodes {
+		if n.Cfg == nil || n.Inv == nil || n.Cfg.GetGroup() != group {
+			continue
+		}
+		status, state := n.Inv.GetStatus()
+		if status == inventory.Maintenance ||
+			(status == inventory.Allocated && state == inventory.Disappeared) {
+			count++
+		}
+	}
+	return count
+}
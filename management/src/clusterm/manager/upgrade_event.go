@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/errored"
+)
+
+// UpgradeOptions specifies how an upgrade is rolled out across the nodes
+type UpgradeOptions struct {
+	// BatchSize is the number of nodes upgraded at a time. Defaults to 1
+	BatchSize int `json:"batch_size,omitempty"`
+	// MaxUnavailable is the maximum number of nodes of a host-group that can be
+	// unavailable at a time, counting the nodes that are already disappeared or
+	// in maintenance. The batches are shrunk to stay within it, when it is set.
+	MaxUnavailable int `json:"max_unavailable,omitempty"`
+	// BatchPause is the time to wait between the batches, like "30s" or "2m"
+	BatchPause string `json:"batch_pause,omitempty"`
+}
+
+// upgradeEvent triggers the rolling upgrade workflow
+type upgradeEvent struct {
+	mgr       *Manager
+	nodeNames []string
+	extraVars string
+	opts      UpgradeOptions
+
+	_hosts     map[string]*configuration.AnsibleHost
+	_pause     time.Duration
+	_failed    []string
+	_job       *Job
+	_completed int
+}
+
+// newUpgradeEvent creates and returns upgradeEvent
+func newUpgradeEvent(mgr *Manager, nodeNames []string, extraVars string, opts UpgradeOptions) *upgradeEvent {
+	return &upgradeEvent{
+		mgr:       mgr,
+		nodeNames: nodeNames,
+		extraVars: extraVars,
+		opts:      opts,
+	}
+}
+
+func (e *upgradeEvent) String() string {
+	return fmt.Sprintf("upgradeEvent: nodes: %v extra-vars: %v batch-size: %d max-unavailable: %d batch-pause: %q",
+		e.nodeNames, e.extraVars, e.opts.BatchSize, e.opts.MaxUnavailable, e.opts.BatchPause)
+}
+
+func (e *upgradeEvent) job() *Job {
+	return e._job
+}
+
+func (e *upgradeEvent) process() error {
+	var err error
+	e._job, err = e.mgr.submitJob(
+		e.String(),
+		e.nodeNames,
+		e.prepareJob,
+		e.upgradeRunner,
+		func(status JobStatus, errRet error) {
+			// the nodes in the failed batch are left in maintenance, as their state
+			// is not known. The rest of the nodes are still commissioned.
+			if status == Errored {
+				logrus.Errorf("upgrade job failed after %d batch(es), nodes left in maintenance: %v. Error: %v",
+					e._completed, e._failed, errRet)
+			}
+		})
+	return err
+}
+
+// prepareJob is run before the node upgrade is triggered
+func (e *upgradeEvent) prepareJob() error {
+	// validate event data
+	enodes, err := e.eventValidate()
+	if err != nil {
+		return err
+	}
+
+	// prepare inventory. The nodes are set in-maintenance one batch at a time,
+	// by the job runner.
+	e.prepareInventory(enodes)
+	return nil
+}
+
+// eventValidate perfoms the validations
+func (e *upgradeEvent) eventValidate() (map[string]*node, error) {
+	enodes, err := e.mgr.commonEventValidate(e.nodeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the commissioned nodes can be upgraded
+	for _, name := range e.nodeNames {
+		isDiscoveredAndAllocated, err := e.mgr.isDiscoveredAndAllocatedNode(name)
+		if err != nil {
+			return nil, err
+		}
+		if !isDiscoveredAndAllocated {
+			return nil, errored.Errorf("node %q is not commissioned, only the commissioned nodes can be upgraded", name)
+		}
+	}
+
+	if e.opts.BatchSize < 0 {
+		return nil, errored.Errorf("invalid batch size specified: %d", e.opts.BatchSize)
+	}
+	if e.opts.MaxUnavailable < 0 {
+		return nil, errored.Errorf("invalid max unavailable nodes specified: %d", e.opts.MaxUnavailable)
+	}
+	if e.opts.BatchPause != "" {
+		if e._pause, err = time.ParseDuration(e.opts.BatchPause); err != nil || e._pause < 0 {
+			return nil, errored.Errorf("invalid batch pause specified: %q", e.opts.BatchPause)
+		}
+	}
+	return enodes, nil
+}
+
+// batchSize returns the number of nodes to be upgraded at a time
+func (e *upgradeEvent) batchSize() int {
+	size := e.opts.BatchSize
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+// prepareInventory records the configuration of the nodes to be upgraded
+func (e *upgradeEvent) prepareInventory(enodes map[string]*node) {
+	e._hosts = map[string]*configuration.AnsibleHost{}
+	for _, name := range e.nodeNames {
+		e._hosts[name] = enodes[name].Cfg.(*configuration.AnsibleHost)
+	}
+}
+
+// upgradeRunner is the job runner that runs the upgrade playbook on the nodes, one
+// batch at a time and in the order they were specified. The nodes of a batch are
+// in maintenance while the batch is upgraded. It stops at the first batch that fails.
+func (e *upgradeEvent) upgradeRunner(cancelCh CancelChannel, jobLogs io.Writer) error {
+	pending := e.nodeNames
+	for i := 0; len(pending) > 0; i++ {
+		if i > 0 && e._pause > 0 {
+			fmt.Fprintf(jobLogs, "pausing for %s before the next batch\n", e._pause)
+			select {
+			case <-cancelCh:
+				return errJobCancelled
+			case <-time.After(e._pause):
+			}
+		}
+
+		// pick the next batch and set it's assets as in-maintenance
+		be := newUpgradeBatchEvent(e.mgr, pending, e.batchSize(), e.opts.MaxUnavailable)
+		me := newWaitableEvent(be)
+		e.mgr.reqQ <- me
+		if err := me.waitForCompletion(); err != nil {
+			return err
+		}
+		names := be._batch
+		pending = pending[len(names):]
+		batch := []*configuration.AnsibleHost{}
+		for _, name := range names {
+			batch = append(batch, e._hosts[name])
+		}
+
+		fmt.Fprintf(jobLogs, "upgrading batch %d, nodes: %v, nodes left to upgrade: %d\n", i+1, names, len(pending))
+		outReader, cancelFunc, errCh := e.mgr.configuration.Upgrade(batch, e.extraVars)
+		if err := logOutputAndReturnStatus(outReader, errCh, cancelCh, cancelFunc, jobLogs); err != nil {
+			logrus.Errorf("upgrade failed for nodes: %v. Error: %s", names, err)
+			e._failed = names
+			return err
+		}
+		e.mgr.setJobAssetsStatus(names, e.mgr.inventory.SetAssetCommissioned, nil)
+		e._completed++
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// +build unittest
+
+package manager
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/errored"
+	"github.com/golang/mock/gomock"
+	. "gopkg.in/check.v1"
+)
+
+type upgradeEventSuite struct {
+}
+
+var _ = Suite(&upgradeEventSuite{})
+
+// upgradeRecorder is a configuration subsystem that records the hosts passed to
+// the upgrade calls, along with the nodes that are in maintenance at the time of
+// the call. It fails the upgrade call specified by failOn
+type upgradeRecorder struct {
+	configuration.Subsys
+	mgr         *Manager
+	batches     [][]string
+	maintenance [][]string
+	failOn      int
+}
+
+func (r *upgradeRecorder) Upgrade(nodes configuration.SubsysHosts, extraVars string) (io.Reader, context.CancelFunc, chan error) {
+	names := []string{}
+	for _, host := range nodes.([]*configuration.AnsibleHost) {
+		names = append(names, host.GetTag())
+	}
+	r.batches = append(r.batches, names)
+	r.maintenance = append(r.maintenance, testNodesInStatus(r.mgr, inventory.Maintenance))
+	errCh := make(chan error, 1)
+	if len(r.batches) == r.failOn {
+		errCh <- errored.Errorf("test failure")
+	} else {
+		errCh <- nil
+	}
+	return strings.NewReader(""), func() {}, errCh
+}
+
+// testUpgradeEvent returns an upgrade event for the commissioned nodes, along
+// with a running event loop to process the status changes of the nodes. The
+// down nodes are commissioned nodes that have disappeared and are not upgraded
+func testUpgradeEvent(c *C, client inventory.SubsysClient, names, down []string,
+	opts UpgradeOptions, rec *upgradeRecorder) *upgradeEvent {
+	nodes := map[string]assetStatus{}
+	for _, name := range names {
+		nodes[name] = assetStatus{inventory.Allocated, inventory.Discovered}
+	}
+	for _, name := range down {
+		nodes[name] = assetStatus{inventory.Allocated, inventory.Disappeared}
+	}
+	mgr := testManager(c, client, nodes)
+	mgr.reqQ = make(chan event, 100)
+	mgr.configuration = rec
+	rec.mgr = mgr
+	go mgr.eventLoop()
+
+	e := newUpgradeEvent(mgr, names, "", opts)
+	e.prepareInventory(mgr.nodes)
+	return e
+}
+
+// testNodesInStatus returns the sorted names of the nodes in the specified status
+func testNodesInStatus(mgr *Manager, status inventory.AssetStatus) []string {
+	names := []string{}
+	for name, n := range mgr.nodes {
+		if s, _ := n.Inv.GetStatus(); s == status {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *upgradeEventSuite) TestBatchSize(c *C) {
+	tests := map[string]struct {
+		opts      UpgradeOptions
+		exptdSize int
+	}{
+		"default": {
+			opts:      UpgradeOptions{},
+			exptdSize: 1,
+		},
+		"batch-size": {
+			opts:      UpgradeOptions{BatchSize: 3},
+			exptdSize: 3,
+		},
+	}
+
+	for key, test := range tests {
+		e := newUpgradeEvent(&Manager{}, nil, "", test.opts)
+		c.Assert(e.batchSize(), Equals, test.exptdSize, Commentf("test: %s", key))
+	}
+}
+
+func (s *upgradeEventSuite) TestUpgradeRunnerBatches(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mClient.EXPECT().SetAssetStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	names := []string{"n1", "n2", "n3", "n4", "n5"}
+	rec := &upgradeRecorder{}
+	e := testUpgradeEvent(c, mClient, names, nil, UpgradeOptions{BatchSize: 2}, rec)
+
+	var logs bytes.Buffer
+	c.Assert(e.upgradeRunner(make(CancelChannel), &logs), IsNil)
+	c.Assert(rec.batches, DeepEquals, [][]string{{"n1", "n2"}, {"n3", "n4"}, {"n5"}})
+	// only the nodes of the batch being upgraded are in maintenance
+	c.Assert(rec.maintenance, DeepEquals, rec.batches)
+	c.Assert(testNodesInStatus(e.mgr, inventory.Allocated), DeepEquals, names)
+	c.Assert(e._completed, Equals, 3)
+	c.Assert(e._failed, IsNil)
+	c.Assert(logs.String(), Matches, "(?s).*upgrading batch 3, nodes: \\[n5\\], nodes left to upgrade: 0.*")
+}
+
+func (s *upgradeEventSuite) TestUpgradeRunnerMaxUnavailable(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mClient.EXPECT().SetAssetStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// a node of the host-group is already down, so only one more node can be
+	// unavailable at a time
+	names := []string{"n1", "n2", "n3"}
+	rec := &upgradeRecorder{}
+	e := testUpgradeEvent(c, mClient, names, []string{"d1"},
+		UpgradeOptions{BatchSize: 2, MaxUnavailable: 2}, rec)
+	c.Assert(e.upgradeRunner(make(CancelChannel), &bytes.Buffer{}), IsNil)
+	c.Assert(rec.batches, DeepEquals, [][]string{{"n1"}, {"n2"}, {"n3"}})
+	c.Assert(testNodesInStatus(e.mgr, inventory.Allocated), DeepEquals, []string{"d1", "n1", "n2", "n3"})
+
+	// no node can be upgraded once the down nodes reach the max unavailable nodes
+	rec = &upgradeRecorder{}
+	e = testUpgradeEvent(c, mClient, names, []string{"d1"},
+		UpgradeOptions{BatchSize: 2, MaxUnavailable: 1}, rec)
+	err := e.upgradeRunner(make(CancelChannel), &bytes.Buffer{})
+	c.Assert(err, ErrorMatches, "host-group \"service-master\" has 1 unavailable node\\(s\\), upgrading node \"n1\" would exceed.*")
+	c.Assert(rec.batches, HasLen, 0)
+	c.Assert(testNodesInStatus(e.mgr, inventory.Maintenance), HasLen, 0)
+}
+
+func (s *upgradeEventSuite) TestUpgradeRunnerStopOnFailure(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mClient.EXPECT().SetAssetStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	names := []string{"n1", "n2", "n3", "n4", "n5"}
+	rec := &upgradeRecorder{failOn: 2}
+	e := testUpgradeEvent(c, mClient, names, nil, UpgradeOptions{BatchSize: 2}, rec)
+
+	err := e.upgradeRunner(make(CancelChannel), &bytes.Buffer{})
+	c.Assert(err, ErrorMatches, "test failure")
+	c.Assert(rec.batches, DeepEquals, [][]string{{"n1", "n2"}, {"n3", "n4"}})
+	c.Assert(e._completed, Equals, 1)
+	c.Assert(e._failed, DeepEquals, []string{"n3", "n4"})
+	// the failed batch is left in maintenance, the batches that never ran stay commissioned
+	c.Assert(testNodesInStatus(e.mgr, inventory.Maintenance), DeepEquals, []string{"n3", "n4"})
+	c.Assert(testNodesInStatus(e.mgr, inventory.Allocated), DeepEquals, []string{"n1", "n2", "n5"})
+}
+
+func (s *upgradeEventSuite) TestUpgradeRunnerCancelInPause(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	mClient.EXPECT().SetAssetStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mClient.EXPECT().AddAssetLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	names := []string{"n1", "n2"}
+	rec := &upgradeRecorder{}
+	e := testUpgradeEvent(c, mClient, names, nil, UpgradeOptions{BatchPause: "1h"}, rec)
+	e._pause = time.Hour
+
+	// cancel the job while it pauses after the first batch
+	cancelCh := make(CancelChannel, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancelCh <- struct{}{}
+	}()
+	err := e.upgradeRunner(cancelCh, &bytes.Buffer{})
+	c.Assert(err, Equals, errJobCancelled)
+	c.Assert(rec.batches, DeepEquals, [][]string{{"n1"}})
+	c.Assert(e._failed, IsNil)
+	c.Assert(testNodesInStatus(e.mgr, inventory.Allocated), DeepEquals, names)
+}
@@ -1,7 +1,11 @@
 package manager
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/Sirupsen/logrus"
+	"github.com/contiv/cluster/management/src/configuration"
 	"github.com/contiv/cluster/management/src/inventory"
 	"github.com/contiv/errored"
 )
@@ -28,6 +32,10 @@ func (m *Manager) findNode(name string) (*node, error) {
 
 func (m *Manager) findNodeByMgmtAddr(addr string) (*node, error) {
 	for _, node := range m.nodes {
+		// skip the nodes that were restored from inventory but are not discovered yet
+		if node.Mon == nil {
+			continue
+		}
 		if node.Mon.GetMgmtAddress() == addr {
 			return node, nil
 		}
@@ -35,28 +43,6 @@ func (m *Manager) findNodeByMgmtAddr(addr string) (*node, error) {
 	return nil, nodeNotExistsError(addr)
 }
 
-func (m *Manager) isMasterNode(name string) (bool, error) {
-	n, err := m.findNode(name)
-	if err != nil {
-		return false, err
-	}
-	if n.Cfg == nil {
-		return false, nodeConfigNotExistsError(name)
-	}
-	return n.Cfg.GetGroup() == ansibleMasterGroupName, nil
-}
-
-func (m *Manager) isWorkerNode(name string) (bool, error) {
-	n, err := m.findNode(name)
-	if err != nil {
-		return false, err
-	}
-	if n.Cfg == nil {
-		return false, nodeConfigNotExistsError(name)
-	}
-	return n.Cfg.GetGroup() == ansibleWorkerGroupName, nil
-}
-
 func (m *Manager) isDiscoveredNode(name string) (bool, error) {
 	n, err := m.findNode(name)
 	if err != nil {
@@ -124,41 +110,184 @@ func (m *Manager) setAssetsStatusAtomic(names []string, newStatusCb setInvStateC
 	return nil
 }
 
-// checkAndGetNewJob() is a wrapper to check that there are no active jobs before a job is run
-func (m *Manager) checkAndSetActiveJob(jobDesc string, runner JobRunner, doneCb DoneCallback) error {
-	if m.activeJob != nil {
-		return errActiveJob(m.activeJob.String())
+// saveNodeConfig persists the configuration state of a node in the inventory,
+// so that it can be restored across clusterm restarts
+func (m *Manager) saveNodeConfig(name string) error {
+	n, err := m.findNode(name)
+	if err != nil {
+		return err
+	}
+	if n.Cfg == nil {
+		return nodeConfigNotExistsError(name)
+	}
+	cfg, err := json.Marshal(n.Cfg)
+	if err != nil {
+		return errored.Errorf("failed to marshal configuration state of node %q. Error: %v", name, err)
+	}
+	return m.inventory.SetAssetAttribute(name, nodeConfigAttr, string(cfg))
+}
+
+// saves the configuration state of all nodes, it continues on failures. It is
+// called from the jobs' done callbacks, which are run by the event loop.
+func (m *Manager) saveNodesConfigBestEffort(names []string) {
+	for _, name := range names {
+		if err := m.saveNodeConfig(name); err != nil {
+			logrus.Errorf("failed to save %s's configuration state in inventory, Error: %v", name, err)
+			continue
+		}
+	}
+}
+
+// restoreNodes restores the nodes using the configuration state persisted in the inventory.
+// The monitoring state of these nodes is updated once they are discovered.
+func (m *Manager) restoreNodes() error {
+	assets, ok := m.inventory.GetAllAssets().(map[string]*inventory.Asset)
+	if !ok {
+		return errored.Errorf("unexpected type of inventory assets: %T", m.inventory.GetAllAssets())
+	}
+	for name, asset := range assets {
+		cfg := asset.GetAttribute(nodeConfigAttr)
+		if cfg == "" {
+			// the configuration state of such nodes is initialized on discovery
+			logrus.Infof("no configuration state found for node %q, skipping restore", name)
+			continue
+		}
+		host := &configuration.AnsibleHost{}
+		if err := json.Unmarshal([]byte(cfg), host); err != nil {
+			return errored.Errorf("failed to restore configuration state of node %q. Error: %v", name, err)
+		}
+		n := &node{
+			Inv: asset,
+			Cfg: host,
+		}
+		if labels := asset.GetAttribute(nodeLabelsAttr); labels != "" {
+			if err := json.Unmarshal([]byte(labels), &n.Labels); err != nil {
+				return errored.Errorf("failed to restore labels of node %q. Error: %v", name, err)
+			}
+		}
+		m.nodes[name] = n
+	}
+	return nil
+}
+
+// submitJob creates a job and adds it to the job queue. The job is run right away
+// if it doesn't conflict with the active and queued jobs, in which case the
+// errors in preparing the job are returned as is. Otherwise the job is queued
+// and it is prepared once it is dispatched. The job's id is allocated only
+// once the job is either prepared or queued, so that the rejected requests
+// don't leave gaps in the job history.
+func (m *Manager) submitJob(jobDesc string, nodes []string, prepare JobPrepare,
+	runner JobRunner, doneCb DoneCallback) (*Job, error) {
+	j := NewJob(jobDesc, runner, doneCb)
+	j.nodes = nodes
+
+	if !m.jobs.tryActivate(j) {
+		if err := m.jobs.checkRoom(); err != nil {
+			return nil, err
+		}
+		if err := m.allocateJobID(j); err != nil {
+			return nil, err
+		}
+		if err := m.jobs.push(j, prepare); err != nil {
+			return nil, err
+		}
+		logrus.Infof("job %d is queued. Job: %s", j.ID(), j)
+		m.saveJob(j)
+		return j, nil
+	}
+
+	if err := prepare(); err != nil {
+		m.jobs.discard(j)
+		return nil, err
+	}
+	if err := m.allocateJobID(j); err != nil {
+		// the job's done callback reverts the changes made in preparing the job
+		j.abort(err)
+		j.finish()
+		m.jobs.discard(j)
+		return nil, err
+	}
+	m.startJob(j)
+	return j, nil
+}
+
+// allocateJobID assigns a unique id to the job
+func (m *Manager) allocateJobID(j *Job) error {
+	id, err := m.jobStore.NextJobID()
+	if err != nil {
+		return errored.Errorf("failed to allocate a job id. Error: %v", err)
 	}
-	m.activeJob = NewJob(jobDesc, runner, doneCb)
+	j.id = id
 	return nil
 }
 
-// resetActiveJob() is a helper to reset active jobs if any
-func (m *Manager) resetActiveJob() {
-	if m.activeJob != nil {
-		m.lastJob = m.activeJob
+// dispatchJobs prepares and runs the queued jobs that can be run
+func (m *Manager) dispatchJobs() {
+	for qj := m.jobs.popRunnable(); qj != nil; qj = m.jobs.popRunnable() {
+		if err := qj.prepare(); err != nil {
+			logrus.Errorf("failed to prepare job %d. Error: %v", qj.job.ID(), err)
+			qj.job.abort(err)
+			m.jobs.done(qj.job)
+			m.saveJob(qj.job)
+			m.addJobDoneLogs(qj.job)
+			continue
+		}
+		m.startJob(qj.job)
+	}
+}
+
+// startJob records the start of a prepared job and runs it in the background
+func (m *Manager) startJob(j *Job) {
+	m.saveJob(j)
+	m.addAssetLogs(j.nodes, inventory.LogTypeInfo, "job %d started. Job: %s", j.ID(), j)
+	go m.runJob(j)
+}
+
+// runJob is a wrapper to run the job and notify the manager once the actual job
+// is done. The job's done callback is run by the event loop, as it touches the
+// manager's state, same as the rest of the events.
+func (m *Manager) runJob(j *Job) {
+	j.run()
+	m.reqQ <- newJobDoneEvent(m, j)
+}
+
+// addAssetLogs adds a log entry for each of the specified nodes in the inventory.
+// The nodes that are not in the inventory are skipped. The failures are just logged.
+func (m *Manager) addAssetLogs(names []string, mtype, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, name := range names {
+		if m.inventory.GetAsset(name) == nil {
+			continue
+		}
+		if err := m.inventory.AddAssetLog(name, mtype, msg); err != nil {
+			logrus.Warnf("failed to add log for asset %q. Error: %v", name, err)
+		}
 	}
-	m.activeJob = nil
 }
 
-// runActiveJob() is a wrapper to run the job and reset the active job once the actual job is done
-func (m *Manager) runActiveJob() {
-	if m.activeJob == nil {
-		logrus.Errorf("run called without an active job")
+// addJobDoneLogs adds the log entries for the outcome of a job for the nodes touched by it
+func (m *Manager) addJobDoneLogs(j *Job) {
+	status, err := j.Status()
+	if status == Errored {
+		m.addAssetLogs(j.nodes, inventory.LogTypeError, "job %d failed. Error: %v", j.ID(), err)
 		return
 	}
-	m.activeJob.Run()
-	// reset the active job once done
-	m.resetActiveJob()
+	m.addAssetLogs(j.nodes, inventory.LogTypeInfo, "job %d finished with status: %s", j.ID(), status)
 }
 
-// IsValidHostGroup checks if the passed hostGroup is valid
-func IsValidHostGroup(hostGroup string) bool {
-	switch hostGroup {
-	case
-		ansibleMasterGroupName,
-		ansibleWorkerGroupName:
-		return true
+// saveJob persists the job's info in the job history and prunes the oldest
+// jobs as per the configured history size. It just logs the failures, if any.
+func (m *Manager) saveJob(j *Job) {
+	info, err := json.Marshal(j)
+	if err != nil {
+		logrus.Errorf("failed to marshal info of job %d. Error: %v", j.ID(), err)
+		return
+	}
+	if err := m.jobStore.PutJob(j.ID(), info); err != nil {
+		logrus.Errorf("failed to save info of job %d. Error: %v", j.ID(), err)
+		return
+	}
+	if err := m.jobStore.PruneJobs(m.config.Manager.JobHistorySize); err != nil {
+		logrus.Errorf("failed to prune job history. Error: %v", err)
 	}
-	return false
 }
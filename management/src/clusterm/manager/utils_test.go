@@ -3,7 +3,15 @@
 package manager
 
 import (
+	"encoding/json"
+	"io"
+
+	"github.com/contiv/cluster/management/src/configuration"
+	"github.com/contiv/cluster/management/src/inventory"
+	"github.com/contiv/cluster/management/src/mock"
+	"github.com/contiv/cluster/management/src/monitor"
 	"github.com/contiv/errored"
+	"github.com/golang/mock/gomock"
 	. "gopkg.in/check.v1"
 )
 
@@ -12,6 +20,35 @@ type eventUtilsSuite struct {
 
 var _ = Suite(&eventUtilsSuite{})
 
+// assetStatus is the status and state of an asset
+type assetStatus struct {
+	status inventory.AssetStatus
+	state  inventory.AssetState
+}
+
+// testManager returns a manager with the specified nodes in the specified
+// status and state. All nodes are in master host-group.
+func testManager(c *C, client inventory.SubsysClient, nodes map[string]assetStatus) *Manager {
+	invSubsys := inventory.NewGeneralSubsys(client)
+	mgr := &Manager{
+		inventory:       invSubsys,
+		nodes:           map[string]*node{},
+		jobs:            newJobQueue(5, 5),
+		disappeared:     map[string]*disappearance{},
+		timelines:       map[string]*nodeTimeline{},
+		deferredUpdates: map[string]monitor.SubsysNode{},
+	}
+	for name, s := range nodes {
+		c.Assert(invSubsys.RestoreAsset(name, inventory.NewAssetWithState(client, name,
+			s.status, s.state, nil)), IsNil)
+		mgr.nodes[name] = &node{
+			Inv: invSubsys.GetAsset(name),
+			Cfg: configuration.NewAnsibleHost(name, "", ansibleMasterGroupName, nil),
+		}
+	}
+	return mgr
+}
+
 func recordCb(strs *[]string) setInvStateCallback {
 	return func(name string) error {
 		*strs = append(*strs, name)
@@ -64,3 +101,86 @@ func (s *eventUtilsSuite) TestSetStatusBestEffortFailure(c *C) {
 	mgr.setAssetsStatusBestEffort(strs, failureCb(&setStrs, 2))
 	c.Assert(strs, DeepEquals, setStrs)
 }
+
+func (s *eventUtilsSuite) TestSaveAndRestoreNodeConfig(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	invSubsys := inventory.NewGeneralSubsys(mClient)
+	invSubsys.RestoreAsset("foo", inventory.NewAssetWithState(mClient, "foo",
+		inventory.Allocated, inventory.Discovered, nil))
+	host := configuration.NewAnsibleHost("foo", "1.2.3.4", ansibleWorkerGroupName,
+		map[string]string{ansibleNodeNameHostVar: "foo"})
+	host.SetExtraVars(`{"foo": "bar"}`)
+	mgr := &Manager{
+		inventory: invSubsys,
+		nodes: map[string]*node{
+			"foo": {
+				Inv: invSubsys.GetAsset("foo"),
+				Cfg: host,
+			},
+		},
+	}
+	cfg, err := json.Marshal(host)
+	c.Assert(err, IsNil)
+	mClient.EXPECT().SetAssetAttribute("foo", nodeConfigAttr, string(cfg))
+	c.Assert(mgr.saveNodeConfig("foo"), IsNil)
+
+	// restore the node and make sure the configuration state is retained
+	mgr.nodes = make(map[string]*node)
+	c.Assert(mgr.restoreNodes(), IsNil)
+	c.Assert(len(mgr.nodes), Equals, 1)
+	c.Assert(mgr.nodes["foo"].Cfg, DeepEquals, host)
+	c.Assert(mgr.nodes["foo"].Mon, IsNil)
+}
+
+func (s *eventUtilsSuite) TestRestoreNodeConfigNotExist(c *C) {
+	invSubsys := inventory.NewGeneralSubsys(nil)
+	invSubsys.RestoreAsset("foo", inventory.NewAssetWithState(nil, "foo",
+		inventory.Unallocated, inventory.Discovered, nil))
+	mgr := &Manager{
+		inventory: invSubsys,
+		nodes:     make(map[string]*node),
+	}
+	c.Assert(mgr.restoreNodes(), IsNil)
+	c.Assert(len(mgr.nodes), Equals, 0)
+}
+
+func (s *eventUtilsSuite) TestRestoreNodeConfigInvalid(c *C) {
+	invSubsys := inventory.NewGeneralSubsys(nil)
+	invSubsys.RestoreAsset("foo", inventory.NewAssetWithState(nil, "foo",
+		inventory.Unallocated, inventory.Discovered, map[string]string{nodeConfigAttr: "{"}))
+	mgr := &Manager{
+		inventory: invSubsys,
+		nodes:     make(map[string]*node),
+	}
+	c.Assert(mgr.restoreNodes(), ErrorMatches, "failed to restore configuration state.*")
+}
+
+func (s *eventUtilsSuite) TestSubmitJobIDAllocation(c *C) {
+	mgr := testManager(c, nil, nil)
+	mgr.config = DefaultConfig()
+	mgr.reqQ = make(chan event, 10)
+	mgr.jobStore = newMemJobStore()
+	runner := func(cancelCh CancelChannel, jobLogs io.Writer) error { return nil }
+	doneCb := func(status JobStatus, errRet error) {}
+
+	// a job that fails to prepare doesn't use up a job id
+	_, err := mgr.submitJob("test", []string{"foo"},
+		func() error { return errored.Errorf("test failure") }, runner, doneCb)
+	c.Assert(err, ErrorMatches, "test failure")
+	j, err := mgr.submitJob("test", []string{"foo"}, noopPrepare, runner, doneCb)
+	c.Assert(err, IsNil)
+	c.Assert(j.ID(), Equals, uint64(1))
+
+	// a queued job is assigned an id, unless the queue is full
+	mgr.jobs = newJobQueue(1, 1)
+	c.Assert(mgr.jobs.tryActivate(j), Equals, true)
+	j, err = mgr.submitJob("test", []string{"foo"}, noopPrepare, runner, doneCb)
+	c.Assert(err, IsNil)
+	c.Assert(j.ID(), Equals, uint64(2))
+	_, err = mgr.submitJob("test", []string{"foo"}, noopPrepare, runner, doneCb)
+	c.Assert(err, ErrorMatches, "the job queue is full.*")
+	c.Assert(mgr.jobStore.(*memJobStore).jobs, HasLen, 2)
+}
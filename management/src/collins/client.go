@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
@@ -35,6 +36,38 @@ type Asset struct {
 	State  struct {
 		Name string `json:"NAME"`
 	}
+	// Attributes stores the key/value attributes associated with the asset.
+	// Collins returns these separate from rest of asset's info.
+	Attributes map[string]string `json:"-"`
+}
+
+// maxAssetLogs is the maximum number of log entries fetched for an asset
+const maxAssetLogs = 1000
+
+// AssetLog denotes a log entry of an asset as read from collins
+type AssetLog struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// attributes denotes the asset attributes as returned by collins. The attributes
+// are keyed by their dimension, which is always "0" for the attributes set by this client.
+type attributes map[string]map[string]string
+
+// flatten returns the attributes as a key/value map. The keys are lower-cased
+// as collins always returns them in upper-case.
+func (attrs attributes) flatten() map[string]string {
+	var flatAttrs map[string]string
+	for _, dimAttrs := range attrs {
+		for k, v := range dimAttrs {
+			if flatAttrs == nil {
+				flatAttrs = make(map[string]string)
+			}
+			flatAttrs[strings.ToLower(k)] = v
+		}
+	}
+	return flatAttrs
 }
 
 // Client denotes state for a collins client
@@ -116,20 +149,24 @@ func (c *Client) GetAsset(tag string) (Asset, error) {
 	logrus.Debugf("response: %s", body)
 	collinsResp := &struct {
 		Data struct {
-			Asset Asset `json:"ASSET"`
+			Asset      Asset      `json:"ASSET"`
+			Attributes attributes `json:"ATTRIBS"`
 		} `json:"data"`
 	}{}
 	if err := json.Unmarshal(body, collinsResp); err != nil {
 		return Asset{}, errored.Errorf("failed to unmarshal response. Error: %s", err)
 	}
 
-	logrus.Debugf("collins asset: %+v", collinsResp.Data.Asset)
-	return collinsResp.Data.Asset, nil
+	asset := collinsResp.Data.Asset
+	asset.Attributes = collinsResp.Data.Attributes.flatten()
+	logrus.Debugf("collins asset: %+v", asset)
+	return asset, nil
 }
 
 // GetAllAssets queries and returns a all the assets
 func (c *Client) GetAllAssets() (interface{}, error) {
-	reqURL := c.config.URL + "/api/assets"
+	// fetch the details to get the asset attributes as well
+	reqURL := c.config.URL + "/api/assets?details=true"
 	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
 		return nil, err
@@ -155,7 +192,8 @@ func (c *Client) GetAllAssets() (interface{}, error) {
 	collinsResp := &struct {
 		Data struct {
 			Assets []struct {
-				Asset Asset `json:"ASSET"`
+				Asset      Asset      `json:"ASSET"`
+				Attributes attributes `json:"ATTRIBS"`
 			} `json:"Data"`
 		} `json:"data"`
 	}{}
@@ -165,8 +203,10 @@ func (c *Client) GetAllAssets() (interface{}, error) {
 
 	assets := []Asset{}
 	for _, d := range collinsResp.Data.Assets {
-		logrus.Debugf("collins asset: %+v", d.Asset)
-		assets = append(assets, d.Asset)
+		asset := d.Asset
+		asset.Attributes = d.Attributes.flatten()
+		logrus.Debugf("collins asset: %+v", asset)
+		assets = append(assets, asset)
 	}
 	return assets, nil
 }
@@ -210,7 +250,117 @@ func (c *Client) CreateState(name, description, status string) error {
 
 // AddAssetLog creates a log entry for an asset
 func (c *Client) AddAssetLog(tag, mtype, message string) error {
-	return errored.Errorf("not implemented")
+	params := &url.Values{}
+	params.Set("message", message)
+	params.Set("type", mtype)
+
+	reqURL := c.config.URL + "/api/asset/" + tag + "/log?" + params.Encode()
+	req, err := http.NewRequest("PUT", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.config.User, c.config.Password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			body = []byte{}
+		}
+		return errored.Errorf("status code %d unexpected. Response body: %q",
+			resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// DeleteAsset deletes an asset with specified tag from the collins database.
+// Collins only deletes the assets that are already decommissioned.
+func (c *Client) DeleteAsset(tag string) error {
+	params := &url.Values{}
+	params.Set("reason", "purged by cluster manager")
+	params.Set("nuke", "true")
+
+	reqURL := c.config.URL + "/api/asset/" + tag + "?" + params.Encode()
+	req, err := http.NewRequest("DELETE", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.config.User, c.config.Password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			body = []byte{}
+		}
+		return errored.Errorf("status code %d unexpected. Response body: %q",
+			resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// GetAssetLogs queries and returns the log entries of an asset, oldest first.
+// Atmost maxAssetLogs latest entries are returned.
+func (c *Client) GetAssetLogs(tag string) (interface{}, error) {
+	params := &url.Values{}
+	params.Set("size", strconv.Itoa(maxAssetLogs))
+	params.Set("sort", "DESC")
+
+	reqURL := c.config.URL + "/api/asset/" + tag + "/logs?" + params.Encode()
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.config.User, c.config.Password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errored.Errorf("failed to read response body. Error: %s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errored.Errorf("status code %d unexpected. Response body: %q",
+			resp.StatusCode, body)
+	}
+
+	logrus.Debugf("response: %s", body)
+	collinsResp := &struct {
+		Data struct {
+			Logs []struct {
+				Created string `json:"CREATED"`
+				Type    string `json:"TYPE"`
+				Message string `json:"MESSAGE"`
+			} `json:"Data"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, collinsResp); err != nil {
+		return nil, errored.Errorf("failed to unmarshal response. Error: %s", err)
+	}
+
+	// the latest entries were fetched first, return them oldest first
+	logs := []AssetLog{}
+	for i := len(collinsResp.Data.Logs) - 1; i >= 0; i-- {
+		l := collinsResp.Data.Logs[i]
+		logs = append(logs, AssetLog{Time: l.Created, Type: l.Type, Message: l.Message})
+	}
+	return logs, nil
 }
 
 // SetAssetStatus sets the status of an asset
@@ -243,3 +393,33 @@ func (c *Client) SetAssetStatus(tag, status, state, reason string) error {
 
 	return nil
 }
+
+// SetAssetAttribute sets the value of a key/value attribute associated with an asset
+func (c *Client) SetAssetAttribute(tag, key, value string) error {
+	params := &url.Values{}
+	params.Set("attribute", key+";"+value)
+
+	reqURL := c.config.URL + "/api/asset/" + tag + "?" + params.Encode()
+	req, err := http.NewRequest("POST", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.config.User, c.config.Password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			body = []byte{}
+		}
+		return errored.Errorf("status code %d unexpected. Response body: %q",
+			resp.StatusCode, body)
+	}
+
+	return nil
+}
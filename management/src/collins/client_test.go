@@ -350,3 +350,157 @@ func (s *collinsSuite) TestSetAssetStatusStatusFailure(c *C) {
 	err := client.SetAssetStatus("test", "status", "state", "reason")
 	c.Assert(err, ErrorMatches, errStr)
 }
+
+func (s *collinsSuite) TestGetAssetWithAttributes(c *C) {
+	tag := "test"
+	srvr, httpC := getHTTPTestClientAndServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":{"ASSET":{"TAG":"test","STATUS":"status","STATE":{"NAME":"state"}},` +
+				`"ATTRIBS":{"0":{"FOO":"bar","BAZ":"qux"}}}}`))
+		}))
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	rcvdAsset, err := client.GetAsset(tag)
+	c.Assert(err, IsNil)
+	c.Assert(rcvdAsset.Tag, Equals, tag)
+	c.Assert(rcvdAsset.Attributes, DeepEquals, map[string]string{"foo": "bar", "baz": "qux"})
+}
+
+func (s *collinsSuite) TestSetAssetAttribute(c *C) {
+	tag := "test"
+	key := "key"
+	value := "value"
+	srvr, httpC := getHTTPTestClientAndServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reqStr := "/api/asset/" + tag
+			if !strings.Contains(r.RequestURI, reqStr) ||
+				r.URL.Query().Get("attribute") != key+";"+value {
+				http.Error(w, "unexpected request", http.StatusInternalServerError)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	err := client.SetAssetAttribute(tag, key, value)
+	c.Assert(err, IsNil)
+}
+
+func (s *collinsSuite) TestSetAssetAttributeStatusFailure(c *C) {
+	srvr, httpC := getHTTPTestClientAndServer(failureReturner)
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	errStr := ".*unexpected. Response body.*test failure.*"
+	err := client.SetAssetAttribute("test", "key", "value")
+	c.Assert(err, ErrorMatches, errStr)
+}
+
+func (s *collinsSuite) TestAddAssetLog(c *C) {
+	tag := "test"
+	srvr, httpC := getHTTPTestClientAndServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reqStr := "/api/asset/" + tag + "/log"
+			if r.Method != "PUT" || !strings.Contains(r.RequestURI, reqStr) ||
+				r.URL.Query().Get("type") != "NOTE" || r.URL.Query().Get("message") != "a message" {
+				http.Error(w, "unexpected request", http.StatusInternalServerError)
+			} else {
+				w.WriteHeader(http.StatusCreated)
+			}
+		}))
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	err := client.AddAssetLog(tag, "NOTE", "a message")
+	c.Assert(err, IsNil)
+}
+
+func (s *collinsSuite) TestDeleteAsset(c *C) {
+	tag := "test"
+	srvr, httpC := getHTTPTestClientAndServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reqStr := "/api/asset/" + tag
+			if r.Method != "DELETE" || !strings.Contains(r.RequestURI, reqStr) ||
+				r.URL.Query().Get("nuke") != "true" || r.URL.Query().Get("reason") == "" {
+				http.Error(w, "unexpected request", http.StatusInternalServerError)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	err := client.DeleteAsset(tag)
+	c.Assert(err, IsNil)
+}
+
+func (s *collinsSuite) TestDeleteAssetStatusFailure(c *C) {
+	srvr, httpC := getHTTPTestClientAndServer(failureReturner)
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	errStr := ".*unexpected. Response body.*test failure.*"
+	err := client.DeleteAsset("test")
+	c.Assert(err, ErrorMatches, errStr)
+}
+
+func (s *collinsSuite) TestAddAssetLogStatusFailure(c *C) {
+	srvr, httpC := getHTTPTestClientAndServer(failureReturner)
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	errStr := ".*unexpected. Response body.*test failure.*"
+	err := client.AddAssetLog("test", "NOTE", "a message")
+	c.Assert(err, ErrorMatches, errStr)
+}
+
+func (s *collinsSuite) TestGetAssetLogs(c *C) {
+	tag := "test"
+	srvr, httpC := getHTTPTestClientAndServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reqStr := "/api/asset/" + tag + "/logs"
+			if !strings.Contains(r.RequestURI, reqStr) {
+				http.Error(w, "unexpected request", http.StatusInternalServerError)
+				return
+			}
+			// collins returns the latest entries first
+			w.Write([]byte(`{"data":{"Data":[` +
+				`{"CREATED":"t2","TYPE":"ERROR","MESSAGE":"msg2"},` +
+				`{"CREATED":"t1","TYPE":"INFORMATIONAL","MESSAGE":"msg1"}]}}`))
+		}))
+	defer srvr.Close()
+	client := &Client{
+		config: DefaultConfig(),
+		client: httpC,
+	}
+
+	logs, err := client.GetAssetLogs(tag)
+	c.Assert(err, IsNil)
+	c.Assert(logs, DeepEquals, []AssetLog{
+		{Time: "t1", Type: "INFORMATIONAL", Message: "msg1"},
+		{Time: "t2", Type: "ERROR", Message: "msg2"},
+	})
+}
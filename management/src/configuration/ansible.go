@@ -17,6 +17,7 @@ type AnsibleSubsysConfig struct {
 	ConfigurePlaybook string `json:"configure_playbook"`
 	CleanupPlaybook   string `json:"cleanup_playbook"`
 	UpgradePlaybook   string `json:"upgrade_playbook"`
+	VerifyPlaybook    string `json:"verify_playbook"`
 	PlaybookLocation  string `json:"playbook_location"`
 	ExtraVariables    string `json:"extra_variables"`
 	// XXX: revisit the user credential configuration. We may need to allow other provisions.
@@ -32,10 +33,11 @@ type AnsibleSubsys struct {
 
 // AnsibleHost describes host related info relevant for ansible inventory
 type AnsibleHost struct {
-	addr  string
-	group string
-	tag   string
-	vars  map[string]string
+	addr      string
+	group     string
+	tag       string
+	vars      map[string]string
+	extraVars string
 }
 
 // NewAnsibleHost instantiates and returns AnsibleHost
@@ -63,26 +65,71 @@ func (h *AnsibleHost) SetVar(key, val string) {
 	h.vars[key] = val
 }
 
+// SetAddr sets the address used to reach the host
+func (h *AnsibleHost) SetAddr(addr string) {
+	h.addr = addr
+}
+
+// GetAddr returns the address used to reach the host
+func (h *AnsibleHost) GetAddr() string {
+	return h.addr
+}
+
 // SetGroup sets the host's group
 func (h *AnsibleHost) SetGroup(group string) {
 	h.group = group
 }
 
+// SetExtraVars records the extra vars that were last applied to the host
+func (h *AnsibleHost) SetExtraVars(extraVars string) {
+	h.extraVars = extraVars
+}
+
+// GetExtraVars returns the extra vars that were last applied to the host
+func (h *AnsibleHost) GetExtraVars() string {
+	return h.extraVars
+}
+
+// ansibleHostJSON is the json representation of AnsibleHost
+type ansibleHostJSON struct {
+	Tag       string            `json:"inventory_name"`
+	HostGroup string            `json:"host_group"`
+	Addr      string            `json:"ssh_address"`
+	Vars      map[string]string `json:"inventory_vars"`
+	ExtraVars string            `json:"last_extra_vars,omitempty"`
+}
+
 // MarshalJSON satisfies the json marshaller interface and shall encode asset info in json
 func (h *AnsibleHost) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Tag       string            `json:"inventory_name"`
-		HostGroup string            `json:"host_group"`
-		Addr      string            `json:"ssh_address"`
-		Vars      map[string]string `json:"inventory_vars"`
-	}{
+	return json.Marshal(ansibleHostJSON{
 		Tag:       h.tag,
 		HostGroup: h.group,
 		Addr:      h.addr,
 		Vars:      h.vars,
+		ExtraVars: h.extraVars,
 	})
 }
 
+// UnmarshalJSON satisfies the json unmarshaller interface and shall decode the
+// host info encoded by MarshalJSON. This allows restoring a host's info from it's
+// persisted state.
+func (h *AnsibleHost) UnmarshalJSON(data []byte) error {
+	hj := ansibleHostJSON{}
+	if err := json.Unmarshal(data, &hj); err != nil {
+		return err
+	}
+
+	h.tag = hj.Tag
+	h.group = hj.HostGroup
+	h.addr = hj.Addr
+	h.vars = hj.Vars
+	if h.vars == nil {
+		h.vars = make(map[string]string)
+	}
+	h.extraVars = hj.ExtraVars
+	return nil
+}
+
 // NewAnsibleSubsys instantiates and returns AnsibleSubsys
 func NewAnsibleSubsys(config *AnsibleSubsysConfig) *AnsibleSubsys {
 	return &AnsibleSubsys{
@@ -179,6 +226,19 @@ func (a *AnsibleSubsys) Upgrade(nodes SubsysHosts, extraVars string) (io.Reader,
 		a.config.UpgradePlaybook}, "/"), extraVars)
 }
 
+// Verify triggers the ansible playbook for verification on specified nodes. If no
+// verification playbook is configured, it returns a nil reader and a nil error
+// on the error channel.
+func (a *AnsibleSubsys) Verify(nodes SubsysHosts, extraVars string) (io.Reader, context.CancelFunc, chan error) {
+	if a.config.VerifyPlaybook == "" {
+		errCh := make(chan error, 1)
+		errCh <- nil
+		return nil, func() {}, errCh
+	}
+	return a.ansibleRunner(nodes.([]*AnsibleHost), strings.Join([]string{a.config.PlaybookLocation,
+		a.config.VerifyPlaybook}, "/"), extraVars)
+}
+
 // SetGlobals sets the extra vars at a ansible subsys level
 func (a *AnsibleSubsys) SetGlobals(extraVars string) error {
 	a.globalExtraVars = extraVars
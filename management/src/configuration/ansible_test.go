@@ -69,3 +69,22 @@ func (s *ansibleSuite) TestMergeExtraVarsInvalidJSON(c *C) {
 	c.Assert(err, ErrorMatches, "failed to unmarshal src extra vars.*",
 		Commentf("output string: %s", out))
 }
+
+func (s *ansibleSuite) TestAnsibleHostJSONRoundTrip(c *C) {
+	host := NewAnsibleHost("foo", "1.2.3.4", "bar", map[string]string{"key": "val"})
+	host.SetExtraVars(`{"var": "val"}`)
+	out, err := json.Marshal(host)
+	c.Assert(err, IsNil)
+
+	rHost := &AnsibleHost{}
+	c.Assert(json.Unmarshal(out, rHost), IsNil)
+	c.Assert(rHost, DeepEquals, host)
+}
+
+func (s *ansibleSuite) TestVerifyWithoutPlaybook(c *C) {
+	a := NewAnsibleSubsys(&AnsibleSubsysConfig{})
+	r, cancelFunc, errCh := a.Verify([]*AnsibleHost{NewAnsibleHost("foo", "1.2.3.4", "bar", nil)}, "")
+	c.Assert(r, IsNil)
+	c.Assert(cancelFunc, NotNil)
+	c.Assert(<-errCh, IsNil)
+}
@@ -20,6 +20,10 @@ type Subsys interface {
 	// Cleanup triggers the configuration upgrade on specified set of nodes.
 	// It return a error channel that the caller can wait on to get completion status.
 	Upgrade(nodes SubsysHosts, extraVars string) (io.Reader, context.CancelFunc, chan error)
+	// Verify triggers the configuration verification on specified set of nodes.
+	// It return a error channel that the caller can wait on to get completion status.
+	// The verification is skipped, if the subsystem is not configured for it.
+	Verify(nodes SubsysHosts, extraVars string) (io.Reader, context.CancelFunc, chan error)
 	// SetGlobals sets the extra vars at a configuration subsys level
 	SetGlobals(extraVars string) error
 	// GetGlobals return the value of extra vars at a configuration subsys level
@@ -2,6 +2,7 @@ package inventory
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
@@ -47,17 +48,23 @@ var lifecycleStatus = map[AssetStatus]map[AssetStatus]bool{
 	New: {},
 	Unallocated: {
 		Provisioning: true,
+		Provisioned:  true,
 	},
 	Provisioning: {
+		Unallocated: true,
+		Provisioned: true,
+		Allocated:   true,
+	},
+	Provisioned: {
 		Unallocated: true,
 		Allocated:   true,
 	},
-	Provisioned: {},
 	Allocated: {
 		Cancelled:   true,
 		Maintenance: true,
 	},
 	Cancelled: {
+		Allocated:      true,
 		Decommissioned: true,
 	},
 	Decommissioned: {
@@ -65,7 +72,9 @@ var lifecycleStatus = map[AssetStatus]map[AssetStatus]bool{
 	},
 	Maintenance: {
 		Unallocated: true,
+		Provisioned: true,
 		Allocated:   true,
+		Cancelled:   true,
 	},
 }
 
@@ -80,7 +89,10 @@ var lifecycleStates = map[AssetStatus]map[AssetState]bool{
 		Discovered:  true,
 		Disappeared: true,
 	},
-	Provisioned: {},
+	Provisioned: {
+		Discovered:  true,
+		Disappeared: true,
+	},
 	Allocated: {
 		Discovered:  true,
 		Disappeared: true,
@@ -107,11 +119,14 @@ type Asset struct {
 	prevStatus AssetStatus
 	state      AssetState
 	prevState  AssetState
+	attrs      map[string]string
 }
 
 // NewAssetWithState creates a new asset in the inventory in a discovered state and returns it.
-func NewAssetWithState(client SubsysClient, name string, status AssetStatus, state AssetState) *Asset {
-	return &Asset{
+// The attrs are the key/value attributes, if any, that were previously stored with the asset.
+func NewAssetWithState(client SubsysClient, name string, status AssetStatus, state AssetState,
+	attrs map[string]string) *Asset {
+	a := &Asset{
 		client:     client,
 		name:       name,
 		status:     status,
@@ -119,6 +134,13 @@ func NewAssetWithState(client SubsysClient, name string, status AssetStatus, sta
 		state:      state,
 		prevState:  Unknown,
 	}
+	for k, v := range attrs {
+		if a.attrs == nil {
+			a.attrs = make(map[string]string)
+		}
+		a.attrs[k] = v
+	}
+	return a
 }
 
 // NewAsset creates a new asset in the inventory in a discovered state and returns it.
@@ -141,6 +163,7 @@ func NewAsset(client SubsysClient, name string) (*Asset, error) {
 		return nil, err
 	}
 
+	a.addLog(LogTypeInfo, "asset created in status: %q and state: %q", a.status, a.state)
 	logrus.Debugf("created asset: %+v", a)
 	return a, nil
 }
@@ -170,9 +193,44 @@ func (a *Asset) SetStatus(status AssetStatus, state AssetState) error {
 	a.status = status
 	a.state = state
 
+	a.addLog(LogTypeInfo, "asset moved from status: %q and state: %q to status: %q and state: %q",
+		a.prevStatus, a.prevState, a.status, a.state)
 	return nil
 }
 
+// ForceStatus updates the status and state of an asset in the inventory without
+// performing the lifecycle related validations. It is meant to fix an asset whose
+// status doesn't reflect the reality, so a reason for the override is required and
+// it is recorded in the asset's logs.
+func (a *Asset) ForceStatus(status AssetStatus, state AssetState, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errored.Errorf("a reason is required to force the status of asset %q", a.name)
+	}
+
+	if err := a.client.SetAssetStatus(a.name, status.String(), state.String(), reason); err != nil {
+		return err
+	}
+
+	a.prevStatus = a.status
+	a.prevState = a.state
+	a.status = status
+	a.state = state
+
+	logrus.Warnf("status of asset %q forced from status: %q and state: %q to status: %q and state: %q. Reason: %s",
+		a.name, a.prevStatus, a.prevState, a.status, a.state, reason)
+	a.addLog(LogTypeNote, "asset status forced from status: %q and state: %q to status: %q and state: %q. Reason: %s",
+		a.prevStatus, a.prevState, a.status, a.state, reason)
+	return nil
+}
+
+// addLog adds a log entry for the asset in the inventory. The failure to add
+// the log is not fatal, so it is just logged.
+func (a *Asset) addLog(mtype, format string, args ...interface{}) {
+	if err := a.client.AddAssetLog(a.name, mtype, fmt.Sprintf(format, args...)); err != nil {
+		logrus.Warnf("failed to add log for asset %q. Error: %v", a.name, err)
+	}
+}
+
 // GetStatus returns the current status and state of an asset.
 func (a *Asset) GetStatus() (AssetStatus, AssetState) {
 	return a.status, a.state
@@ -183,6 +241,29 @@ func (a *Asset) GetTag() string {
 	return a.name
 }
 
+// SetAttribute updates the value of a key/value attribute associated with the asset.
+func (a *Asset) SetAttribute(key, value string) error {
+	if a.attrs[key] == value {
+		return nil
+	}
+
+	if err := a.client.SetAssetAttribute(a.name, key, value); err != nil {
+		return err
+	}
+
+	if a.attrs == nil {
+		a.attrs = make(map[string]string)
+	}
+	a.attrs[key] = value
+	return nil
+}
+
+// GetAttribute returns the value of an attribute associated with the asset.
+// It returns an empty string if the attribute is not set.
+func (a *Asset) GetAttribute(key string) string {
+	return a.attrs[key]
+}
+
 // MarshalJSON implements the json marshaller for asset. It is done this way
 // than making the fields public inorder to safeguard against direct state interpolation.
 func (a *Asset) MarshalJSON() ([]byte, error) {
@@ -35,6 +35,7 @@ func (s *inventorySuite) TestNewAsset(c *C) {
 	mClient.EXPECT().CreateAsset(eAsset.name, eAsset.status.String())
 	mClient.EXPECT().SetAssetStatus(eAsset.name, eAsset.status.String(),
 		eAsset.state.String(), StateDescription[eAsset.state])
+	mClient.EXPECT().AddAssetLog(eAsset.name, LogTypeInfo, gomock.Any())
 	rAsset, err := NewAsset(mClient, eAsset.name)
 	c.Assert(err, IsNil)
 	c.Assert(rAsset, DeepEquals, eAsset)
@@ -102,11 +103,83 @@ func (s *inventorySuite) TestSetStatus(c *C) {
 	}
 	mClient.EXPECT().SetAssetStatus(asset.name, eAsset.status.String(),
 		eAsset.state.String(), StateDescription[eAsset.state])
+	mClient.EXPECT().AddAssetLog(asset.name, LogTypeInfo, gomock.Any())
 	err := asset.SetStatus(eAsset.status, eAsset.state)
 	c.Assert(err, IsNil)
 	c.Assert(asset, DeepEquals, eAsset)
 }
 
+func (s *inventorySuite) TestSetStatusProvisioned(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	asset := &Asset{
+		client:     mClient,
+		name:       "foo",
+		status:     Provisioning,
+		prevStatus: Unallocated,
+		state:      Discovered,
+		prevState:  Unknown,
+	}
+	eAsset := &Asset{
+		client:     mClient,
+		name:       "foo",
+		status:     Allocated,
+		prevStatus: Provisioned,
+		state:      Discovered,
+		prevState:  Discovered,
+	}
+	// a provisioned asset is allocated once it's configuration is verified
+	mClient.EXPECT().SetAssetStatus(asset.name, Provisioned.String(),
+		eAsset.state.String(), StateDescription[eAsset.state])
+	mClient.EXPECT().SetAssetStatus(asset.name, eAsset.status.String(),
+		eAsset.state.String(), StateDescription[eAsset.state])
+	mClient.EXPECT().AddAssetLog(asset.name, LogTypeInfo, gomock.Any()).Times(2)
+	c.Assert(asset.SetStatus(Provisioned, Discovered), IsNil)
+	c.Assert(asset.SetStatus(eAsset.status, eAsset.state), IsNil)
+	c.Assert(asset, DeepEquals, eAsset)
+}
+
+func (s *inventorySuite) TestSetStatusAddLogFailure(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	asset := NewAssetWithState(mClient, "foo", Unallocated, Discovered, nil)
+	mClient.EXPECT().SetAssetStatus(asset.name, Provisioning.String(),
+		Discovered.String(), StateDescription[Discovered])
+	mClient.EXPECT().AddAssetLog(asset.name, LogTypeInfo, gomock.Any()).Return(errored.Errorf("test error"))
+	// failure to add the log doesn't fail the transition
+	c.Assert(asset.SetStatus(Provisioning, Discovered), IsNil)
+	status, _ := asset.GetStatus()
+	c.Assert(status, Equals, Provisioning)
+}
+
+func (s *inventorySuite) TestForceStatus(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	// the transition from cancelled to unallocated is not allowed by the lifecycle
+	asset := NewAssetWithState(mClient, "foo", Cancelled, Disappeared, nil)
+	c.Assert(asset.SetStatus(Unallocated, Discovered), ErrorMatches, "transition from .* is not allowed")
+
+	mClient.EXPECT().SetAssetStatus(asset.name, Unallocated.String(), Discovered.String(), "node was reinstalled")
+	mClient.EXPECT().AddAssetLog(asset.name, LogTypeNote, gomock.Any())
+	c.Assert(asset.ForceStatus(Unallocated, Discovered, "node was reinstalled"), IsNil)
+	status, state := asset.GetStatus()
+	c.Assert(status, Equals, Unallocated)
+	c.Assert(state, Equals, Discovered)
+}
+
+func (s *inventorySuite) TestForceStatusNoReason(c *C) {
+	asset := NewAssetWithState(nil, "foo", Cancelled, Disappeared, nil)
+	c.Assert(asset.ForceStatus(Unallocated, Discovered, " "), ErrorMatches, "a reason is required.*")
+	status, _ := asset.GetStatus()
+	c.Assert(status, Equals, Cancelled)
+}
+
 func (s *inventorySuite) TestSetStatusNoTransition(c *C) {
 	asset := &Asset{
 		client:     nil,
@@ -200,3 +273,33 @@ func (s *inventorySuite) TestSetStatusSetFailure(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(asset, DeepEquals, eAsset)
 }
+
+func (s *inventorySuite) TestSetAttribute(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	asset := NewAssetWithState(mClient, "foo", Allocated, Discovered, nil)
+	mClient.EXPECT().SetAssetAttribute(asset.name, "key", "value")
+	err := asset.SetAttribute("key", "value")
+	c.Assert(err, IsNil)
+	c.Assert(asset.GetAttribute("key"), Equals, "value")
+
+	// setting same value again shall not result in a client call
+	err = asset.SetAttribute("key", "value")
+	c.Assert(err, IsNil)
+}
+
+func (s *inventorySuite) TestSetAttributeFailure(c *C) {
+	ctrl := gomock.NewController(c)
+	defer ctrl.Finish()
+
+	mClient := mock.NewMockSubsysClient(ctrl)
+	asset := NewAssetWithState(mClient, "foo", Allocated, Discovered,
+		map[string]string{"key": "value"})
+	mClient.EXPECT().SetAssetAttribute(asset.name, "key",
+		"newValue").Return(errored.Errorf("test failure"))
+	err := asset.SetAttribute("key", "newValue")
+	c.Assert(err, NotNil)
+	c.Assert(asset.GetAttribute("key"), Equals, "value")
+}
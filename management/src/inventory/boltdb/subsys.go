@@ -11,6 +11,12 @@ func NewBoltdbSubsys(config boltdb.Config) (*inventory.GeneralSubsys, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewBoltdbSubsysFromClient(client)
+}
+
+// NewBoltdbSubsysFromClient initializes and return an instance of boltdb based
+// inventory subsystem using an existing boltdb client
+func NewBoltdbSubsysFromClient(client *boltdb.Client) (*inventory.GeneralSubsys, error) {
 	subsys := inventory.NewGeneralSubsys(client)
 
 	// restore any previously added hosts
@@ -21,7 +27,7 @@ func NewBoltdbSubsys(config boltdb.Config) (*inventory.GeneralSubsys, error) {
 	assets1 := assets.([]boltdb.Asset)
 	for _, asset := range assets1 {
 		a := inventory.NewAssetWithState(client, asset.Name, inventory.AssetStatusVals[asset.Status],
-			inventory.AssetStateVals[asset.State])
+			inventory.AssetStateVals[asset.State], asset.Attributes)
 		subsys.RestoreAsset(asset.Name, a)
 	}
 
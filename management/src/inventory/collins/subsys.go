@@ -26,7 +26,7 @@ func NewCollinsSubsys(config collins.Config) (*inventory.GeneralSubsys, error) {
 	assets1 := assets.([]collins.Asset)
 	for _, asset := range assets1 {
 		a := inventory.NewAssetWithState(client, asset.Tag, inventory.AssetStatusVals[asset.Status],
-			inventory.AssetStateVals[asset.State.Name])
+			inventory.AssetStateVals[asset.State.Name], asset.Attributes)
 		subsys.RestoreAsset(asset.Tag, a)
 	}
 
@@ -20,7 +20,9 @@ const (
 	// admin or automatically. The configuration for infrastructure is pushed at this status.
 	Provisioning
 	// Provisioned status in collins implies that Host has finished provisioning and is awaiting final
-	// automated verification. This is not yet used in contiv cluster.
+	// automated verification. In contiv cluster this status is set when the host configuration was
+	// pushed successfully or when an already configured host is adopted. The configuration is verified
+	// at this status.
 	Provisioned
 	// Allocated status in collins implies that this asset is in what should likely be considered a production
 	// state. In contiv cluster this status is set when the host configuration was successful.
@@ -51,3 +53,13 @@ const (
 	// Disappeared state denotes that host has disappeared from monitoring subsystem.
 	Disappeared
 )
+
+// the types of asset logs. These are a subset of the log types supported by collins.
+const (
+	// LogTypeInfo denotes an informational log, like a status transition
+	LogTypeInfo = "INFORMATIONAL"
+	// LogTypeNote denotes a log that needs the user's attention, like a node's disappearance
+	LogTypeNote = "NOTE"
+	// LogTypeError denotes a log for a failure, like a failed job
+	LogTypeError = "ERROR"
+)
@@ -15,6 +15,8 @@ type Subsys interface {
 	SetAssetDisappeared(name string) error
 	//SetAssetProvisioning sets an asset state to provisioning
 	SetAssetProvisioning(name string) error
+	//SetAssetProvisioned sets an asset state to provisioned
+	SetAssetProvisioned(name string) error
 	//SetAssetCommissioned sets an asset state to commissioned (aka allocated)
 	SetAssetCommissioned(name string) error
 	//SetAssetCancelled sets an asset state to cancelled
@@ -25,6 +27,17 @@ type Subsys interface {
 	SetAssetInMaintenance(name string) error
 	//SetAssetUnallocated sets an asset status to unallocated
 	SetAssetUnallocated(name string) error
+	//ForceAssetStatus sets an asset's status and state bypassing the lifecycle validations.
+	//The reason for the override is recorded in the asset's logs
+	ForceAssetStatus(name string, status AssetStatus, state AssetState, reason string) error
+	//SetAssetAttribute sets the value of a key/value attribute associated with an asset
+	SetAssetAttribute(name, key, value string) error
+	//AddAssetLog adds a log entry of specified type for an asset
+	AddAssetLog(name, mtype, message string) error
+	//DeleteAsset removes an asset from the inventory
+	DeleteAsset(name string) error
+	//GetAssetLogs returns the log entries of an asset, oldest first
+	GetAssetLogs(name string) (SubsysAssetLogs, error)
 	//GetAsset finds and returns the asset in inventory
 	GetAsset(name string) SubsysAsset
 	//GetAllAssets returns all the assets in inventory
@@ -35,9 +48,12 @@ type Subsys interface {
 type SubsysClient interface {
 	GetAllAssets() (interface{}, error)
 	CreateAsset(tag, status string) error
+	DeleteAsset(tag string) error
 	CreateState(name, description, status string) error
 	AddAssetLog(tag, mtype, message string) error
+	GetAssetLogs(tag string) (interface{}, error)
 	SetAssetStatus(tag, status, state, reason string) error
+	SetAssetAttribute(tag, key, value string) error
 }
 
 // SubsysAsset denotes a single asset in inventory subsystem
@@ -46,9 +62,16 @@ type SubsysAsset interface {
 	GetStatus() (AssetStatus, AssetState)
 	//GetTag returns the inventory tag of the asset
 	GetTag() string
+	//GetAttribute returns the value of an attribute associated with the asset.
+	//It returns an empty string if the attribute is not set.
+	GetAttribute(key string) string
 	//SubsysAsset shall satisfy the json marshaller interface to encode asset's info in json
 	json.Marshaler
 }
 
 // SubsysAssets denotes a collection of assets in the inventory subsystem
 type SubsysAssets interface{}
+
+// SubsysAssetLogs denotes the log entries of an asset in the inventory subsystem.
+// They shall be encodable in json
+type SubsysAssetLogs interface{}
@@ -71,6 +71,16 @@ func (ci *GeneralSubsys) SetAssetProvisioning(name string) error {
 	return ci.assets[name].SetStatus(Provisioning, state)
 }
 
+//SetAssetProvisioned sets an asset state to provisioned
+func (ci *GeneralSubsys) SetAssetProvisioned(name string) error {
+	if _, ok := ci.assets[name]; !ok {
+		return errAssetNotExists(name)
+	}
+
+	_, state := ci.assets[name].GetStatus()
+	return ci.assets[name].SetStatus(Provisioned, state)
+}
+
 //SetAssetCommissioned sets an asset status to unallocated
 func (ci *GeneralSubsys) SetAssetCommissioned(name string) error {
 	if _, ok := ci.assets[name]; !ok {
@@ -122,6 +132,55 @@ func (ci *GeneralSubsys) SetAssetUnallocated(name string) error {
 	return ci.assets[name].SetStatus(Unallocated, state)
 }
 
+//ForceAssetStatus sets an asset's status and state bypassing the lifecycle validations
+func (ci *GeneralSubsys) ForceAssetStatus(name string, status AssetStatus, state AssetState, reason string) error {
+	if _, ok := ci.assets[name]; !ok {
+		return errAssetNotExists(name)
+	}
+
+	return ci.assets[name].ForceStatus(status, state, reason)
+}
+
+//SetAssetAttribute sets the value of a key/value attribute associated with an asset
+func (ci *GeneralSubsys) SetAssetAttribute(name, key, value string) error {
+	if _, ok := ci.assets[name]; !ok {
+		return errAssetNotExists(name)
+	}
+
+	return ci.assets[name].SetAttribute(key, value)
+}
+
+//AddAssetLog adds a log entry of specified type for an asset
+func (ci *GeneralSubsys) AddAssetLog(name, mtype, message string) error {
+	if _, ok := ci.assets[name]; !ok {
+		return errAssetNotExists(name)
+	}
+
+	return ci.client.AddAssetLog(name, mtype, message)
+}
+
+//GetAssetLogs returns the log entries of an asset, oldest first
+func (ci *GeneralSubsys) GetAssetLogs(name string) (SubsysAssetLogs, error) {
+	if _, ok := ci.assets[name]; !ok {
+		return nil, errAssetNotExists(name)
+	}
+
+	return ci.client.GetAssetLogs(name)
+}
+
+//DeleteAsset removes an asset from the inventory
+func (ci *GeneralSubsys) DeleteAsset(name string) error {
+	if _, ok := ci.assets[name]; !ok {
+		return errAssetNotExists(name)
+	}
+
+	if err := ci.client.DeleteAsset(name); err != nil {
+		return err
+	}
+	delete(ci.assets, name)
+	return nil
+}
+
 //GetAsset finds and returns the asset in inventory
 func (ci *GeneralSubsys) GetAsset(name string) SubsysAsset {
 	if a, ok := ci.assets[name]; ok {
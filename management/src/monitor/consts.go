@@ -3,7 +3,7 @@
 package monitor
 
 // EventType denotes the possible events associated with node monitoring
-// viz. discovery and disappearance
+// viz. discovery, disappearance, leave, update and reap
 type EventType int
 
 const (
@@ -12,4 +12,14 @@ const (
 
 	// Disappeared is constant for the node disappearance event
 	Disappeared
+
+	// Left is constant for the event of a node leaving the cluster gracefully
+	Left
+
+	// Updated is constant for the event of a change in node's info, like it's address
+	Updated
+
+	// Reaped is constant for the event of a failed or left node being removed
+	// from the monitoring system, after it stays away for long
+	Reaped
 )
@@ -0,0 +1,220 @@
+package monitor
+
+import (
+	"encoding/base64"
+	"os"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/errored"
+	"github.com/hashicorp/serf/serf"
+	"github.com/mapuri/serf/client"
+)
+
+// the defaults for the gossip settings that are not specified in the config
+const (
+	// defaultGossipBindPort is different from serf's default port, so that the
+	// embedded member can run alongside a serf agent on the same host
+	defaultGossipBindPort = 7947
+	defaultGossipBindAddr = "0.0.0.0"
+	// defaultGossipNodeNameSuffix is appended to the hostname to form the
+	// member name, so that it doesn't conflict with the serf agent on the same host
+	defaultGossipNodeNameSuffix = "-clusterm"
+	gossipJoinRetryInterval     = 1 * time.Minute
+)
+
+// GossipConfig is the configuration of the monitoring sub-system based on the
+// gossip membership embedded in the cluster manager
+type GossipConfig struct {
+	// NodeName is the member name the cluster manager joins the gossip pool
+	// with. It defaults to the hostname suffixed with '-clusterm'
+	NodeName string `json:"node_name"`
+	// BindAddr and BindPort are the address and port used for gossip
+	BindAddr string `json:"bind_addr"`
+	BindPort int    `json:"bind_port"`
+	// AdvertiseAddr is the address advertised to the other members, when it
+	// differs from the bind address
+	AdvertiseAddr string `json:"advertise_addr,omitempty"`
+	// Join lists the addresses of the members to join the gossip pool through
+	Join []string `json:"join"`
+	// EncryptKey is the base64 encoded key used to encrypt the gossip traffic
+	EncryptKey string `json:"encrypt_key,omitempty"`
+}
+
+// GossipSubsys implements monitoring sub-system by joining the gossip pool of
+// the nodes directly, without depending on a separately run serf agent
+type GossipSubsys struct {
+	config    *serf.Config
+	join      []string
+	eventCh   chan serf.Event
+	callbacks map[EventType]EventCb
+}
+
+// NewGossipSubsys validates the config and returns a GossipSubsys instance.
+// The settings that are not specified are set to their defaults.
+func NewGossipSubsys(config *GossipConfig) (*GossipSubsys, error) {
+	gm := &GossipSubsys{
+		config:    serf.DefaultConfig(),
+		join:      config.Join,
+		eventCh:   make(chan serf.Event, 256),
+		callbacks: map[EventType]EventCb{},
+	}
+
+	gm.config.NodeName = config.NodeName
+	if gm.config.NodeName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, errored.Errorf("failed to read hostname. Error: %v", err)
+		}
+		gm.config.NodeName = hostname + defaultGossipNodeNameSuffix
+	}
+	gm.config.EventCh = gm.eventCh
+	gm.config.MemberlistConfig.BindAddr = config.BindAddr
+	if config.BindAddr == "" {
+		gm.config.MemberlistConfig.BindAddr = defaultGossipBindAddr
+	}
+	if config.BindPort < 0 || config.BindPort > 65535 {
+		return nil, errored.Errorf("invalid gossip bind port %d", config.BindPort)
+	}
+	gm.config.MemberlistConfig.BindPort = config.BindPort
+	if config.BindPort == 0 {
+		gm.config.MemberlistConfig.BindPort = defaultGossipBindPort
+	}
+	gm.config.MemberlistConfig.AdvertiseAddr = config.AdvertiseAddr
+	gm.config.MemberlistConfig.AdvertisePort = gm.config.MemberlistConfig.BindPort
+	if config.EncryptKey != "" {
+		key, err := base64.StdEncoding.DecodeString(config.EncryptKey)
+		if err != nil {
+			return nil, errored.Errorf("invalid gossip encrypt key. Error: %v", err)
+		}
+		gm.config.MemberlistConfig.SecretKey = key
+	}
+	return gm, nil
+}
+
+// RegisterCb implements the callback registration interface of monitoring sub-system
+func (gm *GossipSubsys) RegisterCb(e EventType, cb EventCb) error {
+	switch e {
+	case Discovered, Disappeared, Left, Updated, Reaped:
+		gm.callbacks[e] = cb
+		return nil
+	}
+	return errored.Errorf("Unsupported event type: %d", e)
+}
+
+// gossipEventTypes maps the serf member events to the monitor events
+var gossipEventTypes = map[serf.EventType]EventType{
+	serf.EventMemberJoin:   Discovered,
+	serf.EventMemberFailed: Disappeared,
+	serf.EventMemberLeave:  Left,
+	serf.EventMemberUpdate: Updated,
+	serf.EventMemberReap:   Reaped,
+}
+
+// memberNode returns the monitor node for a gossip member, from it's tags
+func memberNode(mbr serf.Member) *Node {
+	return &Node{
+		label:  mbr.Tags[nodeLabel],
+		serial: mbr.Tags[nodeSerial],
+		addr:   mbr.Tags[nodeAddr],
+	}
+}
+
+// handleEvent translates a serf member event to monitor events and delivers
+// them to the registered callback. The events about the cluster manager's own
+// member are skipped.
+func (gm *GossipSubsys) handleEvent(se serf.Event) {
+	me, ok := se.(serf.MemberEvent)
+	if !ok {
+		logrus.Debugf("skipping gossip event: %q", se)
+		return
+	}
+	t, ok := gossipEventTypes[me.Type]
+	if !ok {
+		logrus.Infof("Unexpected gossip event type: %d", me.Type)
+		return
+	}
+	cb, ok := gm.callbacks[t]
+	if !ok {
+		return
+	}
+
+	events := []Event{}
+	for _, mbr := range me.Members {
+		if mbr.Name == gm.config.NodeName {
+			continue
+		}
+		e := Event{Type: t, Node: memberNode(mbr)}
+		logrus.Debugf("monitor event: %+v", e)
+		events = append(events, e)
+	}
+	if len(events) > 0 {
+		cb(events)
+	}
+}
+
+// reconcile reports the members that failed the gossip pool as disappeared and
+// the members that left it gracefully as left. The alive members are reported
+// by the join events.
+func (gm *GossipSubsys) reconcile(members []serf.Member) {
+	mbrs := []client.Member{}
+	for _, mbr := range members {
+		if mbr.Name == gm.config.NodeName {
+			continue
+		}
+		mbrs = append(mbrs, client.Member{Name: mbr.Name, Tags: mbr.Tags, Status: mbr.Status.String()})
+	}
+	_, disappeared, left := membersToEvents(mbrs)
+	if cb, ok := gm.callbacks[Disappeared]; ok && len(disappeared) > 0 {
+		cb(disappeared)
+	}
+	if cb, ok := gm.callbacks[Left]; ok && len(left) > 0 {
+		cb(left)
+	}
+}
+
+// joinPool joins the gossip pool through the configured members, it retries
+// till at least one of them is reachable. It signals on the joined channel
+// once it succeeds.
+func (gm *GossipSubsys) joinPool(s *serf.Serf, joined chan struct{}) {
+	for {
+		n, err := s.Join(gm.join, true)
+		if err == nil || n > 0 {
+			logrus.Infof("joined gossip pool through %d members", n)
+			joined <- struct{}{}
+			return
+		}
+		logrus.Errorf("failed to join gossip pool through %v. Error: %v", gm.join, err)
+
+		select {
+		case <-s.ShutdownCh():
+			return
+		case <-time.After(gossipJoinRetryInterval):
+		}
+	}
+}
+
+// Start implements the start interface of monitoring sub-system
+func (gm *GossipSubsys) Start() error {
+	s, err := serf.Create(gm.config)
+	if err != nil {
+		return errored.Errorf("failed to start gossip membership. Error: %v", err)
+	}
+	defer s.Shutdown()
+
+	joined := make(chan struct{}, 1)
+	if len(gm.join) > 0 {
+		go gm.joinPool(s, joined)
+	}
+
+	for {
+		select {
+		case se := <-gm.eventCh:
+			gm.handleEvent(se)
+		case <-joined:
+			gm.reconcile(s.Members())
+		case <-s.ShutdownCh():
+			return errored.Errorf("gossip membership shutdown unexpectedly")
+		}
+	}
+}
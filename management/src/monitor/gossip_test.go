@@ -0,0 +1,104 @@
+// +build unittest
+
+package monitor
+
+import (
+	"github.com/hashicorp/serf/serf"
+	. "gopkg.in/check.v1"
+)
+
+type gossipSuite struct {
+}
+
+var _ = Suite(&gossipSuite{})
+
+func testGossipMember(label string, status serf.MemberStatus) serf.Member {
+	return serf.Member{
+		Name:   label,
+		Status: status,
+		Tags: map[string]string{
+			nodeLabel:  label,
+			nodeSerial: "serial-" + label,
+			nodeAddr:   "addr-" + label,
+		},
+	}
+}
+
+func testGossipNode(label string) *Node {
+	return &Node{label: label, serial: "serial-" + label, addr: "addr-" + label}
+}
+
+func (s *gossipSuite) TestNewGossipSubsys(c *C) {
+	gm, err := NewGossipSubsys(&GossipConfig{NodeName: "clusterm", Join: []string{"10.0.0.1"}})
+	c.Assert(err, IsNil)
+	c.Assert(gm.config.NodeName, Equals, "clusterm")
+	c.Assert(gm.config.MemberlistConfig.BindAddr, Equals, defaultGossipBindAddr)
+	c.Assert(gm.config.MemberlistConfig.BindPort, Equals, defaultGossipBindPort)
+	c.Assert(gm.join, DeepEquals, []string{"10.0.0.1"})
+
+	// the hostname based name is used by default
+	gm, err = NewGossipSubsys(&GossipConfig{})
+	c.Assert(err, IsNil)
+	c.Assert(gm.config.NodeName, Matches, ".+"+defaultGossipNodeNameSuffix)
+
+	_, err = NewGossipSubsys(&GossipConfig{BindPort: -1})
+	c.Assert(err, ErrorMatches, "invalid gossip bind port -1")
+	_, err = NewGossipSubsys(&GossipConfig{EncryptKey: "not-base64!"})
+	c.Assert(err, ErrorMatches, "invalid gossip encrypt key.*")
+}
+
+func (s *gossipSuite) TestHandleEvent(c *C) {
+	gm, err := NewGossipSubsys(&GossipConfig{NodeName: "clusterm"})
+	c.Assert(err, IsNil)
+	recvd := []Event{}
+	cb := func(events []Event) { recvd = append(recvd, events...) }
+	for _, t := range []EventType{Discovered, Disappeared, Left, Updated} {
+		c.Assert(gm.RegisterCb(t, cb), IsNil)
+	}
+
+	tests := map[serf.EventType]EventType{
+		serf.EventMemberJoin:   Discovered,
+		serf.EventMemberFailed: Disappeared,
+		serf.EventMemberLeave:  Left,
+		serf.EventMemberUpdate: Updated,
+	}
+	for st, exptd := range tests {
+		recvd = []Event{}
+		// the cluster manager's own member is skipped
+		gm.handleEvent(serf.MemberEvent{Type: st, Members: []serf.Member{
+			testGossipMember("n1", serf.StatusAlive),
+			testGossipMember("clusterm", serf.StatusAlive),
+		}})
+		c.Assert(recvd, DeepEquals, []Event{{Type: exptd, Node: testGossipNode("n1")}}, Commentf("event: %s", st))
+	}
+
+	// no callback is registered for reap
+	recvd = []Event{}
+	gm.handleEvent(serf.MemberEvent{Type: serf.EventMemberReap, Members: []serf.Member{
+		testGossipMember("n1", serf.StatusLeft),
+	}})
+	c.Assert(recvd, HasLen, 0)
+
+	// user events are skipped
+	gm.handleEvent(serf.UserEvent{Name: "foo"})
+	c.Assert(recvd, HasLen, 0)
+}
+
+func (s *gossipSuite) TestReconcile(c *C) {
+	gm, err := NewGossipSubsys(&GossipConfig{NodeName: "clusterm"})
+	c.Assert(err, IsNil)
+	recvd := []Event{}
+	c.Assert(gm.RegisterCb(Disappeared, func(events []Event) { recvd = append(recvd, events...) }), IsNil)
+	c.Assert(gm.RegisterCb(Left, func(events []Event) { recvd = append(recvd, events...) }), IsNil)
+
+	gm.reconcile([]serf.Member{
+		testGossipMember("clusterm", serf.StatusAlive),
+		testGossipMember("n1", serf.StatusAlive),
+		testGossipMember("n2", serf.StatusFailed),
+		testGossipMember("n3", serf.StatusLeft),
+	})
+	c.Assert(recvd, DeepEquals, []Event{
+		{Type: Disappeared, Node: testGossipNode("n2")},
+		{Type: Left, Node: testGossipNode("n3")},
+	})
+}
@@ -0,0 +1,329 @@
+package monitor
+
+import (
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/contiv/errored"
+)
+
+// the defaults for the probe settings that are not specified in the config
+const (
+	defaultProbePort             = 22
+	defaultProbeInterval         = "15s"
+	defaultProbeTimeout          = "5s"
+	defaultProbeFailureThreshold = 3
+	defaultProbeSuccessThreshold = 1
+
+	// maxProbeHosts is the maximum number of hosts that can be probed, it keeps
+	// a large CIDR from being probed by mistake
+	maxProbeHosts = 4096
+)
+
+// ProbeConfig is the configuration of the probe based monitoring sub-system
+type ProbeConfig struct {
+	// Hosts lists the addresses or CIDRs of the hosts to probe, like
+	// '192.168.2.10' or '192.168.2.0/24'
+	Hosts []string `json:"hosts"`
+	// Port is the TCP port that is probed on the hosts, like the ssh port
+	Port int `json:"port"`
+	// Interval is the time between the probes of a host, like "15s"
+	Interval string `json:"interval"`
+	// Timeout is the time a probe waits for the connection to be accepted, like "5s"
+	Timeout string `json:"timeout"`
+	// FailureThreshold is the number of consecutive failed probes after which
+	// a host is reported as disappeared
+	FailureThreshold int `json:"failure_threshold"`
+	// SuccessThreshold is the number of consecutive successful probes after which
+	// a host is reported as discovered
+	SuccessThreshold int `json:"success_threshold"`
+	// Identities maps the address of a host to it's label and serial
+	Identities map[string]ProbeIdentity `json:"identities,omitempty"`
+	// IdentityScript is run with the address of a host, whose identity is not
+	// in Identities, as argument. It shall print the host's label and serial
+	// separated by a space.
+	IdentityScript string `json:"identity_script,omitempty"`
+}
+
+// ProbeIdentity is the label and serial of a probed host
+type ProbeIdentity struct {
+	Label  string `json:"label"`
+	Serial string `json:"serial"`
+}
+
+// the status of a probed host
+const (
+	probeUnknown = iota
+	probeUp
+	probeDown
+)
+
+// probeState is the state of a probed host
+type probeState struct {
+	node      *Node
+	status    int
+	successes int
+	failures  int
+}
+
+// ProbeSubsys implements monitoring sub-system that probes a list of hosts over TCP
+type ProbeSubsys struct {
+	config        ProbeConfig
+	hosts         []string
+	interval      time.Duration
+	timeout       time.Duration
+	states        map[string]*probeState
+	probe         func(addr string) error
+	identify      func(addr string) (*Node, error)
+	discoveredCb  EventCb
+	disappearedCb EventCb
+}
+
+// NewProbeSubsys validates the config and returns a ProbeSubsys instance.
+// The settings that are not specified are set to their defaults.
+func NewProbeSubsys(config *ProbeConfig) (*ProbeSubsys, error) {
+	c := *config
+	if c.Port == 0 {
+		c.Port = defaultProbePort
+	}
+	if c.Interval == "" {
+		c.Interval = defaultProbeInterval
+	}
+	if c.Timeout == "" {
+		c.Timeout = defaultProbeTimeout
+	}
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = defaultProbeFailureThreshold
+	}
+	if c.SuccessThreshold == 0 {
+		c.SuccessThreshold = defaultProbeSuccessThreshold
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		return nil, errored.Errorf("invalid probe port %d", c.Port)
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil || interval <= 0 {
+		return nil, errored.Errorf("invalid probe interval %q", c.Interval)
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil || timeout <= 0 {
+		return nil, errored.Errorf("invalid probe timeout %q", c.Timeout)
+	}
+	if c.FailureThreshold < 0 || c.SuccessThreshold < 0 {
+		return nil, errored.Errorf("invalid probe thresholds, failure: %d success: %d",
+			c.FailureThreshold, c.SuccessThreshold)
+	}
+	hosts, err := expandHosts(c.Hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	pm := &ProbeSubsys{
+		config:   c,
+		hosts:    hosts,
+		interval: interval,
+		timeout:  timeout,
+		states:   map[string]*probeState{},
+	}
+	for _, host := range hosts {
+		pm.states[host] = &probeState{}
+	}
+	pm.probe = pm.dial
+	pm.identify = pm.lookupIdentity
+	return pm, nil
+}
+
+// expandHosts returns the addresses of the hosts specified as addresses or CIDRs.
+// The network and broadcast addresses of the IPv4 CIDRs are skipped.
+func expandHosts(specs []string) ([]string, error) {
+	hosts := []string{}
+	seen := map[string]bool{}
+	add := func(host string) error {
+		if seen[host] {
+			return nil
+		}
+		if len(hosts) >= maxProbeHosts {
+			return errored.Errorf("more than %d hosts specified for probing", maxProbeHosts)
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+		return nil
+	}
+
+	for _, spec := range specs {
+		if !strings.Contains(spec, "/") {
+			if err := add(spec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		ip, ipnet, err := net.ParseCIDR(spec)
+		if err != nil {
+			return nil, errored.Errorf("invalid CIDR %q specified for probing. Error: %v", spec, err)
+		}
+		ones, bits := ipnet.Mask.Size()
+		ip = ip.Mask(ipnet.Mask)
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4
+		}
+		for cur := ip; ipnet.Contains(cur); cur = nextIP(cur) {
+			if bits == 32 && bits-ones > 1 && (cur.Equal(ip) || !ipnet.Contains(nextIP(cur))) {
+				// skip the network and broadcast addresses
+				continue
+			}
+			if err := add(cur.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// nextIP returns the address that follows the specified one
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// dial probes a host by opening a TCP connection to the configured port
+func (pm *ProbeSubsys) dial(addr string) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(addr, strconv.Itoa(pm.config.Port)), pm.timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// lookupIdentity returns the node for a host, with it's label and serial looked
+// up in the configured identities or read from the identity script
+func (pm *ProbeSubsys) lookupIdentity(addr string) (*Node, error) {
+	if id, ok := pm.config.Identities[addr]; ok {
+		return NewNode(id.Label, id.Serial, addr), nil
+	}
+	if pm.config.IdentityScript == "" {
+		return nil, errored.Errorf("no identity is configured for host %q", addr)
+	}
+
+	out, err := exec.Command(pm.config.IdentityScript, addr).Output()
+	if err != nil {
+		return nil, errored.Errorf("identity script failed for host %q. Error: %v", addr, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return nil, errored.Errorf("identity script returned invalid output for host %q: %q", addr, string(out))
+	}
+	return NewNode(fields[0], fields[1], addr), nil
+}
+
+// RegisterCb implements the callback registration interface of monitoring sub-system
+func (pm *ProbeSubsys) RegisterCb(e EventType, cb EventCb) error {
+	switch e {
+	case Discovered:
+		pm.discoveredCb = cb
+		return nil
+	case Disappeared:
+		pm.disappearedCb = cb
+		return nil
+	case Left, Updated, Reaped:
+		// probing can't tell these apart from a disappearance, so they are never reported
+		return nil
+	}
+	return errored.Errorf("Unsupported event type: %d", e)
+}
+
+// probeAll probes all hosts concurrently and returns the hosts that failed the probe
+func (pm *ProbeSubsys) probeAll() map[string]bool {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = map[string]bool{}
+	)
+	for _, host := range pm.hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			if err := pm.probe(host); err != nil {
+				logrus.Debugf("probe of host %q failed. Error: %v", host, err)
+				mu.Lock()
+				failed[host] = true
+				mu.Unlock()
+			}
+		}(host)
+	}
+	wg.Wait()
+	return failed
+}
+
+// update updates the state of the hosts as per the result of a probe round and
+// returns the events for the hosts that crossed the success or failure threshold.
+// A host is identified when it first comes up and is reported up only once it is
+// identified, so it is retried in the next round if the identification fails. A
+// host that was never reported up is not reported when it goes down.
+func (pm *ProbeSubsys) update(failed map[string]bool) (discovered []Event, disappeared []Event) {
+	for _, host := range pm.hosts {
+		s := pm.states[host]
+		if failed[host] {
+			s.successes = 0
+			s.failures++
+			if s.status == probeDown || s.failures < pm.config.FailureThreshold {
+				continue
+			}
+			s.status = probeDown
+			if s.node == nil {
+				continue
+			}
+			e := Event{Type: Disappeared, Node: s.node}
+			disappeared = append(disappeared, e)
+			logrus.Debugf("monitor event: %+v", e)
+			continue
+		}
+
+		s.failures = 0
+		s.successes++
+		if s.status == probeUp || s.successes < pm.config.SuccessThreshold {
+			continue
+		}
+		if s.node == nil {
+			n, err := pm.identify(host)
+			if err != nil {
+				logrus.Errorf("failed to identify host %q. Error: %v", host, err)
+				continue
+			}
+			s.node = n
+		}
+		s.status = probeUp
+		e := Event{Type: Discovered, Node: s.node}
+		discovered = append(discovered, e)
+		logrus.Debugf("monitor event: %+v", e)
+	}
+	return discovered, disappeared
+}
+
+// Start implements the start interface of monitoring sub-system
+func (pm *ProbeSubsys) Start() error {
+	for {
+		discovered, disappeared := pm.update(pm.probeAll())
+		if len(discovered) > 0 && pm.discoveredCb != nil {
+			pm.discoveredCb(discovered)
+		}
+		if len(disappeared) > 0 && pm.disappearedCb != nil {
+			pm.disappearedCb(disappeared)
+		}
+
+		<-time.After(pm.interval)
+	}
+}
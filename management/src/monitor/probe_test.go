@@ -0,0 +1,159 @@
+// +build unittest
+
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/contiv/errored"
+	. "gopkg.in/check.v1"
+)
+
+type probeSuite struct {
+}
+
+var _ = Suite(&probeSuite{})
+
+func (s *probeSuite) TestNewProbeSubsysDefaults(c *C) {
+	pm, err := NewProbeSubsys(&ProbeConfig{Hosts: []string{"10.0.0.1"}})
+	c.Assert(err, IsNil)
+	c.Assert(pm.config.Port, Equals, defaultProbePort)
+	c.Assert(pm.config.FailureThreshold, Equals, defaultProbeFailureThreshold)
+	c.Assert(pm.config.SuccessThreshold, Equals, defaultProbeSuccessThreshold)
+	c.Assert(pm.interval.String(), Equals, defaultProbeInterval)
+	c.Assert(pm.timeout.String(), Equals, defaultProbeTimeout)
+}
+
+func (s *probeSuite) TestNewProbeSubsysInvalidConfig(c *C) {
+	tests := map[string]struct {
+		config   ProbeConfig
+		exptdErr string
+	}{
+		"port": {
+			config:   ProbeConfig{Port: 70000},
+			exptdErr: "invalid probe port 70000",
+		},
+		"interval": {
+			config:   ProbeConfig{Interval: "foo"},
+			exptdErr: "invalid probe interval \"foo\"",
+		},
+		"timeout": {
+			config:   ProbeConfig{Timeout: "-1s"},
+			exptdErr: "invalid probe timeout \"-1s\"",
+		},
+		"threshold": {
+			config:   ProbeConfig{FailureThreshold: -1},
+			exptdErr: "invalid probe thresholds.*",
+		},
+		"cidr": {
+			config:   ProbeConfig{Hosts: []string{"10.0.0.0/33"}},
+			exptdErr: "invalid CIDR \"10.0.0.0/33\".*",
+		},
+		"too-many-hosts": {
+			config:   ProbeConfig{Hosts: []string{"10.0.0.0/16"}},
+			exptdErr: "more than 4096 hosts specified for probing",
+		},
+	}
+	for key, test := range tests {
+		_, err := NewProbeSubsys(&test.config)
+		c.Assert(err, ErrorMatches, test.exptdErr, Commentf("test: %s", key))
+	}
+}
+
+func (s *probeSuite) TestExpandHosts(c *C) {
+	hosts, err := expandHosts([]string{"10.0.0.0/30", "10.0.0.1", "10.0.1.5/32", "host1"})
+	c.Assert(err, IsNil)
+	c.Assert(hosts, DeepEquals, []string{"10.0.0.1", "10.0.0.2", "10.0.1.5", "host1"})
+}
+
+func (s *probeSuite) TestProbeThresholds(c *C) {
+	pm, err := NewProbeSubsys(&ProbeConfig{
+		Hosts:            []string{"10.0.0.1", "10.0.0.2"},
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		Identities: map[string]ProbeIdentity{
+			"10.0.0.1": {Label: "n1", Serial: "s1"},
+		},
+	})
+	c.Assert(err, IsNil)
+	n1 := NewNode("n1", "s1", "10.0.0.1")
+
+	// the hosts are discovered after two successful probes, the host without
+	// an identity is not reported
+	discovered, disappeared := pm.update(map[string]bool{})
+	c.Assert(discovered, HasLen, 0)
+	c.Assert(disappeared, HasLen, 0)
+	discovered, disappeared = pm.update(map[string]bool{})
+	c.Assert(discovered, DeepEquals, []Event{{Type: Discovered, Node: n1}})
+	c.Assert(disappeared, HasLen, 0)
+
+	// a failure followed by a success doesn't disappear the host
+	discovered, disappeared = pm.update(map[string]bool{"10.0.0.1": true})
+	c.Assert(discovered, HasLen, 0)
+	c.Assert(disappeared, HasLen, 0)
+	discovered, disappeared = pm.update(map[string]bool{})
+	c.Assert(discovered, HasLen, 0)
+	c.Assert(disappeared, HasLen, 0)
+
+	// the host disappears after two failed probes, once. The host that was never
+	// reported up is not reported down
+	for _, exptd := range [][]Event{nil, {{Type: Disappeared, Node: n1}}, nil} {
+		discovered, disappeared = pm.update(map[string]bool{"10.0.0.1": true, "10.0.0.2": true})
+		c.Assert(discovered, HasLen, 0)
+		c.Assert(disappeared, DeepEquals, exptd)
+	}
+
+	// the host without an identity is reported up once it's identified
+	for i := 0; i < 2; i++ {
+		discovered, disappeared = pm.update(map[string]bool{"10.0.0.1": true})
+		c.Assert(discovered, HasLen, 0)
+		c.Assert(disappeared, HasLen, 0)
+	}
+	pm.config.Identities["10.0.0.2"] = ProbeIdentity{Label: "n2", Serial: "s2"}
+	discovered, disappeared = pm.update(map[string]bool{"10.0.0.1": true})
+	c.Assert(discovered, DeepEquals, []Event{{Type: Discovered, Node: NewNode("n2", "s2", "10.0.0.2")}})
+	c.Assert(disappeared, HasLen, 0)
+}
+
+func (s *probeSuite) TestProbeIdentityScript(c *C) {
+	f, err := ioutil.TempFile("", "identity")
+	c.Assert(err, IsNil)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("#!/bin/sh\nif [ \"$1\" = \"10.0.0.1\" ]; then echo n1 s1; else echo bad; fi\n")
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(os.Chmod(f.Name(), 0700), IsNil)
+
+	pm, err := NewProbeSubsys(&ProbeConfig{
+		IdentityScript: f.Name(),
+		Identities: map[string]ProbeIdentity{
+			"10.0.0.3": {Label: "n3", Serial: "s3"},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	n, err := pm.identify("10.0.0.1")
+	c.Assert(err, IsNil)
+	c.Assert(n, DeepEquals, NewNode("n1", "s1", "10.0.0.1"))
+
+	_, err = pm.identify("10.0.0.2")
+	c.Assert(err, ErrorMatches, "identity script returned invalid output for host \"10.0.0.2\".*")
+
+	// the static identities take precedence
+	n, err = pm.identify("10.0.0.3")
+	c.Assert(err, IsNil)
+	c.Assert(n, DeepEquals, NewNode("n3", "s3", "10.0.0.3"))
+}
+
+func (s *probeSuite) TestProbeAll(c *C) {
+	pm, err := NewProbeSubsys(&ProbeConfig{Hosts: []string{"10.0.0.1", "10.0.0.2"}})
+	c.Assert(err, IsNil)
+	pm.probe = func(addr string) error {
+		if addr == "10.0.0.2" {
+			return errored.Errorf("connection refused")
+		}
+		return nil
+	}
+	c.Assert(pm.probeAll(), DeepEquals, map[string]bool{"10.0.0.2": true})
+}
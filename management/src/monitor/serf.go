@@ -1,8 +1,6 @@
 package monitor
 
 import (
-	"encoding/json"
-	"os/exec"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -17,12 +15,20 @@ const (
 	nodeAddr   = "NodeAddr"
 )
 
+// the status of the serf members
+const (
+	memberStatusAlive  = "alive"
+	memberStatusFailed = "failed"
+	memberStatusLeft   = "left"
+)
+
 // SerfSubsys implements monitoring sub-system for a serf based cluster
 type SerfSubsys struct {
 	config        *client.Config
 	router        *serfer.Router
 	discoveredCb  EventCb
 	disappearedCb EventCb
+	leftCb        EventCb
 }
 
 // NewSerfSubsys initializes and return a SerfSubsys instance
@@ -52,6 +58,12 @@ func serferCb(cb EventCb) serfer.HandlerFunc {
 				e.Type = Discovered
 			case "member-failed":
 				e.Type = Disappeared
+			case "member-leave":
+				e.Type = Left
+			case "member-update":
+				e.Type = Updated
+			case "member-reap":
+				e.Type = Reaped
 			default:
 				logrus.Infof("Unexpected serf event: %q", name)
 				break for_label
@@ -76,47 +88,79 @@ func (sm *SerfSubsys) RegisterCb(e EventType, cb EventCb) error {
 		sm.disappearedCb = cb
 		return nil
 	}
-	return errored.Errorf("Unsupported event type: %d", e)
-}
-
-func (sm *SerfSubsys) restore() error {
-	// read any members and call the Discovered callback.
-	type serfMemberInfo struct {
-		Members []struct {
-			Name   string            `json:"name"`
-			Status string            `json:"status"`
-			Tags   map[string]string `json:"tags"`
-		} `json:"members"`
+	if e == Left {
+		sm.router.AddMemberLeaveHandler(serferCb(cb))
+		sm.leftCb = cb
+		return nil
 	}
-	output, err := exec.Command("serf", "members", "-format", "json").CombinedOutput()
-	if err != nil {
-		logrus.Errorf("serf members failed. Output: %s, Error: %s", output, err)
-		return err
+	if e == Updated {
+		sm.router.AddHandler("member-update", serferCb(cb))
+		return nil
 	}
-	info := &serfMemberInfo{}
-	if err := json.Unmarshal(output, info); err != nil {
-		logrus.Errorf("failed to parse serf members. Output: %s, Error: %s", output, err)
-		return err
+	if e == Reaped {
+		sm.router.AddHandler("member-reap", serferCb(cb))
+		return nil
 	}
-	events := []Event{}
-	for _, mbr := range info.Members {
-		logrus.Debugf("considering member: %+v", mbr)
-		if mbr.Status != "alive" {
-			continue
-		}
+	return errored.Errorf("Unsupported event type: %d", e)
+}
 
+// membersToEvents returns the monitor events for the serf members as per their
+// status. The alive members are discovered, the failed members are disappeared
+// and the members that left the cluster gracefully are left.
+func membersToEvents(members []client.Member) (discovered, disappeared, left []Event) {
+	for _, mbr := range members {
+		logrus.Debugf("considering member: %+v", mbr)
 		e := Event{
-			Type: Discovered,
 			Node: &Node{
 				label:  mbr.Tags[nodeLabel],
 				serial: mbr.Tags[nodeSerial],
 				addr:   mbr.Tags[nodeAddr],
 			},
 		}
+		switch mbr.Status {
+		case memberStatusAlive:
+			e.Type = Discovered
+			discovered = append(discovered, e)
+		case memberStatusFailed:
+			e.Type = Disappeared
+			disappeared = append(disappeared, e)
+		case memberStatusLeft:
+			e.Type = Left
+			left = append(left, e)
+		default:
+			// the status of a leaving member is reconciled by the event that follows
+			logrus.Infof("skipping member %q in status %q", mbr.Name, mbr.Status)
+			continue
+		}
 		logrus.Debugf("monitor event: %+v", e)
-		events = append(events, e)
 	}
-	sm.discoveredCb(events)
+	return discovered, disappeared, left
+}
+
+// restore reads the members from the serf agent and calls the Discovered,
+// Disappeared and Left callbacks as per their status
+func (sm *SerfSubsys) restore() error {
+	//XXX: make a copy of the config as the serf client changes the config
+	c := *sm.config
+	rpcC, err := client.ClientFromConfig(&c)
+	if err != nil {
+		return errored.Errorf("failed to connect to serf agent at %q. Error: %v", c.Addr, err)
+	}
+	defer rpcC.Close()
+
+	members, err := rpcC.Members()
+	if err != nil {
+		return errored.Errorf("failed to read serf members. Error: %v", err)
+	}
+
+	discovered, disappeared, left := membersToEvents(members)
+	sm.discoveredCb(discovered)
+	if len(disappeared) > 0 {
+		sm.disappearedCb(disappeared)
+	}
+	if len(left) > 0 && sm.leftCb != nil {
+		sm.leftCb(left)
+	}
 	return nil
 }
 
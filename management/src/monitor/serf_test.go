@@ -0,0 +1,49 @@
+// +build unittest
+
+package monitor
+
+import (
+	"testing"
+
+	"github.com/mapuri/serf/client"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type serfSuite struct {
+}
+
+var _ = Suite(&serfSuite{})
+
+func testMember(label, status string) client.Member {
+	return client.Member{
+		Name:   label,
+		Status: status,
+		Tags: map[string]string{
+			nodeLabel:  label,
+			nodeSerial: "serial-" + label,
+			nodeAddr:   "addr-" + label,
+		},
+	}
+}
+
+func (s *serfSuite) TestMembersToEvents(c *C) {
+	discovered, disappeared, left := membersToEvents([]client.Member{
+		testMember("n1", memberStatusAlive),
+		testMember("n2", memberStatusFailed),
+		testMember("n3", "leaving"),
+		testMember("n4", memberStatusLeft),
+	})
+
+	c.Assert(discovered, DeepEquals, []Event{
+		{Type: Discovered, Node: &Node{label: "n1", serial: "serial-n1", addr: "addr-n1"}},
+	})
+	c.Assert(disappeared, DeepEquals, []Event{
+		{Type: Disappeared, Node: &Node{label: "n2", serial: "serial-n2", addr: "addr-n2"}},
+	})
+	c.Assert(left, DeepEquals, []Event{
+		{Type: Left, Node: &Node{label: "n4", serial: "serial-n4", addr: "addr-n4"}},
+	})
+}
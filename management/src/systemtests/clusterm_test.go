@@ -33,9 +33,8 @@ func (s *SystemTestSuite) TestClustermRestart(c *C) {
 	s.checkProvisionStatus(c, s.tbn1, nodeName2, "Decommissioned")
 }
 
-func (s *SystemTestSuite) TestClustermFailureActiveJob(c *C) {
+func (s *SystemTestSuite) TestClustermQueuedJob(c *C) {
 	nodeName1 := validNodeNames[0]
-	nodeName2 := validNodeNames[1]
 
 	// launch commission on a node
 	done := make(chan struct{})
@@ -44,23 +43,23 @@ func (s *SystemTestSuite) TestClustermFailureActiveJob(c *C) {
 		done <- struct{}{}
 	}()
 
-	// try to start another commission job
+	// start an update job on the same node, it gets queued behind the active
+	// job as both touch the same node. The command waits for the update job
+	// to finish, which can only succeed once the node is commissioned
 	time.Sleep(time.Second)
-	cmdStr := fmt.Sprintf("clusterctl node commission %s --host-group %s", nodeName2, ansibleWorkerGroupName)
+	cmdStr := fmt.Sprintf("clusterctl node update %s --host-group %s --wait", nodeName1, ansibleMasterGroupName)
 	out, err := s.tbn1.RunCommandWithOutput(cmdStr)
-	s.Assert(c, err, NotNil, Commentf("output: %s", out))
-	exptStr := ".*there is already an active job.*"
-	s.assertMatch(c, exptStr, out)
+	s.Assert(c, err, IsNil, Commentf("output: %s", out))
 
-	// wait for job to finish
+	// the first job finished before the queued job was run
 	select {
 	case <-done:
 	case <-time.After(30 * time.Second):
 		s.Assert(c, false, Equals, true, Commentf("timeout waiting for job to finish"))
 	}
+	s.checkProvisionStatus(c, s.tbn1, nodeName1, "Allocated")
 
-	// try a decommission job once previous job is done
-	s.decommissionNode(c, nodeName1, s.tbn2)
+	s.decommissionNode(c, nodeName1, s.tbn1)
 }
 
 func (s *SystemTestSuite) TestSerfFailureOnClustermHost(c *C) {
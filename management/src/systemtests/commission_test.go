@@ -139,7 +139,7 @@ func (s *SystemTestSuite) TestCommissionWorkerNodesWithoutMasterFailure(c *C) {
 	cmdStr := fmt.Sprintf("clusterctl node commission %s --host-group %s", nodeName, ansibleWorkerGroupName)
 	out, err := s.tbn1.RunCommandWithOutput(cmdStr)
 	s.Assert(c, err, NotNil, Commentf("output: %s", out))
-	exptdOut := ".*Cannot commission a worker node without existence of a master node in the cluster, make sure atleast one master node is commissioned.*"
+	exptdOut := ".*commissioning the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*"
 	s.assertMatch(c, exptdOut, out)
 }
 
@@ -160,7 +160,7 @@ func (s *SystemTestSuite) TestCommissionWorkerNodesWithMasterDisappearedFailure(
 	cmdStr := fmt.Sprintf("clusterctl node commission %s --host-group %s", nodeName, ansibleWorkerGroupName)
 	out, err := s.tbn1.RunCommandWithOutput(cmdStr)
 	s.Assert(c, err, NotNil, Commentf("output: %s", out))
-	exptStr := ".*Cannot commission a worker node without existence of a master node in the cluster, make sure atleast one master node is commissioned.*"
+	exptStr := ".*commissioning the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*"
 	s.assertMatch(c, exptStr, out)
 }
 
@@ -27,7 +27,7 @@ func (s *SystemTestSuite) TestUpdateNodeFailureNoMasterLeft(c *C) {
 	cmdStr := fmt.Sprintf("clusterctl node update %s --host-group %s", nodeName, ansibleWorkerGroupName)
 	out, err := s.tbn1.RunCommandWithOutput(cmdStr)
 	s.Assert(c, err, NotNil, Commentf("output: %s", out))
-	exptStr := ".*Updating these nodes as worker will result in no master node in the cluster, make sure atleast one node is commissioned as master.*"
+	exptStr := ".*updating the specified node\\(s\\) will leave node\\(s\\) of role \"service-worker\" without a node of role \"service-master\".*"
 	s.assertMatch(c, exptStr, out)
 }
 